@@ -0,0 +1,131 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+	"github.com/sedurm85/calcut/pkg/calcut"
+)
+
+var weekdayNames = [...]string{"일", "월", "화", "수", "목", "금", "토"}
+
+// showToday implements "today", which prints the day's agenda.
+func showToday(args []string) error {
+	return showAgenda("today", "today", args)
+}
+
+// showWeek implements "week", which prints the agenda of the week
+// (Monday to Sunday).
+func showWeek(args []string) error {
+	return showAgenda("week", "this week", args)
+}
+
+// showAgenda prints the occurrences of the calendar's events during the
+// period, recurring events expanded, day by day in the local timezone.
+func showAgenda(name, period string, args []string) error {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	tz := fs.String("tz", "", i18n.T("일정을 보여 줄 시간대 (기본: 시스템 시간대)"))
+	date := fs.String("date", "", i18n.T("이 날짜가 든 기간을 보여 줌 (예: 2024-03-05, tomorrow, next week; 기본: 오늘)"))
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, i18n.T("사용법: split-ical %s [옵션] <입력.ics 또는 URL>\n\n옵션:\n"), name)
+		fs.PrintDefaults()
+	}
+	inputs := parseInterspersed(fs, args)
+	if len(inputs) != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	loc, err := loadLocation(*tz)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if *date != "" {
+		if now, _, err = calcut.ParseDatePeriod(*date, now, loc); err != nil {
+			return err
+		}
+	}
+	from, to, err := calcut.ParseDatePeriod(period, now, loc)
+	if err != nil {
+		return err
+	}
+
+	limits := calcut.DefaultParseLimits()
+	data, err := fetchCalendar(inputs[0], limits.MaxBytes)
+	if err != nil {
+		return err
+	}
+	parsed, err := calcut.ParseBytes(data, limits)
+	if err != nil {
+		return fmt.Errorf("%s: %w", inputs[0], err)
+	}
+	items, err := calcut.Agenda(parsed.Events, from, to, loc)
+	if err != nil {
+		return err
+	}
+
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		next := day.AddDate(0, 0, 1)
+		var lines []string
+		for _, item := range items {
+			if item.Start.Before(next) && (item.End.After(day) || !item.Start.Before(day)) {
+				lines = append(lines, agendaLine(item, day, next))
+			}
+		}
+		if len(lines) == 0 && name == "week" {
+			continue
+		}
+		fmt.Printf("%s (%s)\n", day.Format("2006-01-02"), i18n.T(weekdayNames[day.Weekday()]))
+		if len(lines) == 0 {
+			fmt.Println(i18n.T("  일정 없음"))
+		}
+		for _, line := range lines {
+			fmt.Println("  " + line)
+		}
+	}
+	if len(items) == 0 && name == "week" {
+		fmt.Printf(i18n.T("%s ~ %s: 일정 없음\n"), from.Format("2006-01-02"), to.AddDate(0, 0, -1).Format("2006-01-02"))
+	}
+	return nil
+}
+
+// agendaLine shows item on the day from day to next: its time there, or
+// "종일" when it covers the whole day, followed by summary and location.
+func agendaLine(item calcut.AgendaItem, day, next time.Time) string {
+	when := i18n.T("종일       ")
+	if !item.AllDay && (item.Start.After(day) || item.End.Before(next)) {
+		start, end := "", ""
+		if !item.Start.Before(day) {
+			start = item.Start.Format("15:04")
+		}
+		if item.End.Before(next) {
+			end = item.End.Format("15:04")
+		}
+		when = fmt.Sprintf("%5s-%-5s", start, end)
+	}
+	summary := calcut.UnescapeText(item.Event.Summary)
+	if summary == "" {
+		summary = i18n.T("(제목 없음)")
+	}
+	if loc := calcut.UnescapeText(item.Event.Details().Location.Value); loc != "" {
+		summary += " @ " + strings.ReplaceAll(loc, "\n", ", ")
+	}
+	return when + "  " + summary
+}
+
+// fetchCalendar reads a calendar from a file, standard input ("-") or an
+// http, https or webcal URL, refusing more than maxBytes.
+func fetchCalendar(src string, maxBytes int64) ([]byte, error) {
+	url := src
+	if rest, ok := strings.CutPrefix(src, "webcal://"); ok {
+		url = "https://" + rest
+	}
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return readInput(src, maxBytes)
+	}
+	return fetchURL(url, maxBytes)
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+	"github.com/sedurm85/calcut/pkg/calcut"
+)
+
+// archive collects the output files of a run as entries of a single
+// stream instead of loose files in the output directory: a -zip file, or
+// a tarball, MIME multipart message or bare calendar on standard output
+// (-stdout).
+type archive interface {
+	add(name, content string) error
+	// close finishes the archive. Entries added so far stay readable, so
+	// an interrupted run still leaves a valid archive behind.
+	close() error
+	// discard gives up on an archive that failed part way.
+	discard()
+}
+
+// stdoutFormats are the values -stdout accepts.
+var stdoutFormats = []string{"tar", "multipart", "ics"}
+
+// zipArchive is the archive of -zip.
+type zipArchive struct {
+	path     string
+	file     *os.File
+	zw       *zip.Writer
+	modified time.Time
+}
+
+func createZipArchive(path string, mode os.FileMode, modified time.Time) (*zipArchive, error) {
+	if err := validateOutputPath(path); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(longPath(path), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return nil, err
+	}
+	return &zipArchive{path: path, file: f, zw: zip.NewWriter(f), modified: modified}, nil
+}
+
+func (a *zipArchive) add(name, content string) error {
+	return calcut.AddZipEntry(a.zw, name, content, a.modified)
+}
+
+func (a *zipArchive) close() error {
+	err := a.zw.Close()
+	if cerr := a.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (a *zipArchive) discard() {
+	a.file.Close()
+	os.Remove(longPath(a.path))
+}
+
+// tarArchive writes a tarball to a stream, typically standard output.
+type tarArchive struct {
+	tw       *tar.Writer
+	mode     os.FileMode
+	modified time.Time
+}
+
+func (a *tarArchive) add(name, content string) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    int64(a.mode),
+		Size:    int64(len(content)),
+		ModTime: a.modified,
+		Format:  tar.FormatPAX,
+	}
+	if err := a.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := io.WriteString(a.tw, content)
+	return err
+}
+
+func (a *tarArchive) close() error { return a.tw.Close() }
+func (a *tarArchive) discard()     {}
+
+// multipartArchive writes a multipart/mixed MIME message with one
+// attachment per file.
+type multipartArchive struct {
+	mw *multipart.Writer
+}
+
+func newMultipartArchive(w io.Writer) (*multipartArchive, error) {
+	mw := multipart.NewWriter(w)
+	_, err := fmt.Fprintf(w, "MIME-Version: 1.0\r\nContent-Type: multipart/mixed; boundary=%s\r\n\r\n", mw.Boundary())
+	return &multipartArchive{mw: mw}, err
+}
+
+func (a *multipartArchive) add(name, content string) error {
+	contentType := "text/calendar; charset=utf-8"
+	if name == manifestName || name == auditLogName {
+		contentType = "application/json"
+	}
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Type", contentType)
+	h.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	part, err := a.mw.CreatePart(h)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(part, content)
+	return err
+}
+
+func (a *multipartArchive) close() error { return a.mw.Close() }
+func (a *multipartArchive) discard()     {}
+
+// icsArchive writes the only output file as is, for pipelines that want
+// the calendar itself. The manifest and audit log are left out.
+type icsArchive struct {
+	w       io.Writer
+	written bool
+}
+
+func (a *icsArchive) add(name, content string) error {
+	if name == manifestName || name == auditLogName {
+		return nil
+	}
+	if a.written {
+		return errors.New(i18n.T("-stdout ics는 출력 파일이 하나일 때만 쓸 수 있습니다 (tar, multipart를 쓰거나 -max-size를 늘리세요)"))
+	}
+	a.written = true
+	_, err := io.WriteString(a.w, content)
+	return err
+}
+
+func (a *icsArchive) close() error { return nil }
+func (a *icsArchive) discard()     {}
+
+// newStdoutArchive returns the -stdout archive of the given format.
+func newStdoutArchive(format string, w io.Writer, mode os.FileMode, modified time.Time) (archive, error) {
+	switch format {
+	case "tar":
+		return &tarArchive{tw: tar.NewWriter(w), mode: mode, modified: modified}, nil
+	case "multipart":
+		return newMultipartArchive(w)
+	case "ics":
+		return &icsArchive{w: w}, nil
+	}
+	return nil, fmt.Errorf(i18n.T("알 수 없는 -stdout 형식: %s (%s)"), format, strings.Join(stdoutFormats, ", "))
+}
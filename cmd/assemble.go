@@ -0,0 +1,79 @@
+package main
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+	"github.com/sedurm85/calcut/pkg/calcut"
+)
+
+// assembleCalendars implements "assemble", which turns the output of a
+// -shared-skeleton split back into complete .ics files, one per fragment.
+func assembleCalendars(args []string) error {
+	fs := flag.NewFlagSet("assemble", flag.ExitOnError)
+	output := fs.String("o", "", i18n.T("복원한 .ics 파일을 쓸 디렉토리 (기본: 입력 디렉토리)"))
+	force := fs.Bool("force", false, i18n.T("이미 있는 파일을 덮어씀"))
+	fileMode := fs.String("file-mode", "0644", i18n.T("생성 파일 권한 (8진수, umask 적용)"))
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, i18n.T("사용법: split-ical assemble [옵션] <분할 출력 디렉토리> [-o 출력 디렉토리]\n\n옵션:\n"))
+		fs.PrintDefaults()
+	}
+	inputs := parseInterspersed(fs, args)
+	if len(inputs) != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	mode, err := parseFileMode(*fileMode)
+	if err != nil {
+		return err
+	}
+	dir := inputs[0]
+	outDir := cmp.Or(*output, globals.outputDir, dir)
+
+	m, err := readManifest(dir)
+	if err != nil {
+		return err
+	}
+	if m.Skeleton == "" || !filepath.IsLocal(m.Skeleton) {
+		return fmt.Errorf(i18n.T("%s: -shared-skeleton으로 나눈 결과가 아닙니다"), dir)
+	}
+	skeleton, err := os.ReadFile(filepath.Join(dir, m.Skeleton))
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(longPath(outDir), 0o755); err != nil {
+		return err
+	}
+
+	var size int64
+	for _, f := range m.Files {
+		if !filepath.IsLocal(f.Name) {
+			return fmt.Errorf(i18n.T("%s: 잘못된 파일 이름: %s"), dir, f.Name)
+		}
+		fragment, err := os.ReadFile(filepath.Join(dir, f.Name))
+		if err != nil {
+			return err
+		}
+		content := calcut.Assemble(string(skeleton), string(fragment))
+		path := filepath.Join(outDir, strings.TrimSuffix(f.Name, fragmentExt)+".ics")
+		if err := validateOutputPath(path); err != nil {
+			return err
+		}
+		if *force {
+			err = writeFile(path, content, mode)
+		} else {
+			err = createFile(path, content, mode)
+		}
+		if err != nil {
+			return err
+		}
+		size += int64(len(content))
+	}
+	report(i18n.T("복원 완료: 파일 %d개 (%s) -> %s\n"), len(m.Files), calcut.FormatBytes(size), outDir)
+	return nil
+}
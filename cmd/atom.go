@@ -0,0 +1,103 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+	"github.com/sedurm85/calcut/pkg/calcut"
+)
+
+// writeAtomFeed implements "atom", which writes an Atom feed of the next
+// upcoming events for intranet pages that can embed a feed but not a
+// calendar.
+func writeAtomFeed(args []string) error {
+	fs := flag.NewFlagSet("atom", flag.ExitOnError)
+	output := fs.String("o", "", i18n.T("피드를 쓸 파일 (기본: 표준 출력)"))
+	entries := fs.Int("n", calcut.DefaultAtomEntries, i18n.T("피드에 넣을 다가오는 일정 수"))
+	days := fs.Int("days", 365, i18n.T("이 날수 안에 시작하는 일정만 넣음"))
+	title := fs.String("title", "", i18n.T("피드 제목 (기본: 캘린더의 X-WR-CALNAME, 없으면 파일 이름)"))
+	id := fs.String("id", "", i18n.T("피드 ID (기본: urn:calcut:feed:<파일 이름>, 실행마다 같아야 함)"))
+	author := fs.String("author", "calcut", i18n.T("피드 작성자 이름"))
+	link := fs.String("link", "", i18n.T("피드가 속한 페이지 주소"))
+	tz := fs.String("tz", "", i18n.T("날짜와 시간대 없는 시각을 해석하고 표시할 시간대 (기본: 시스템 시간대)"))
+	fileMode := fs.String("file-mode", "0644", i18n.T("생성 파일 권한 (8진수, umask 적용)"))
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, i18n.T("사용법: split-ical atom [옵션] <입력.ics> [-o feed.xml]\n\n옵션:\n"))
+		fs.PrintDefaults()
+	}
+	inputs := parseInterspersed(fs, args)
+	if len(inputs) != 1 || *entries < 1 || *days < 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	mode, err := parseFileMode(*fileMode)
+	if err != nil {
+		return err
+	}
+	loc, err := loadLocation(*tz)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(inputs[0])
+	if err != nil {
+		return err
+	}
+	parsed, err := calcut.ParseBytes(data, calcut.DefaultParseLimits())
+	if err != nil {
+		return fmt.Errorf("%s: %w", inputs[0], err)
+	}
+	name := filepath.Base(inputs[0])
+	opts := calcut.AtomOptions{
+		Title:   *title,
+		ID:      *id,
+		Author:  *author,
+		Link:    *link,
+		Entries: *entries,
+		Horizon: time.Duration(*days) * 24 * time.Hour,
+		Loc:     loc,
+	}
+	if opts.Title == "" {
+		opts.Title = calendarName(parsed)
+	}
+	if opts.Title == "" {
+		opts.Title = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	if opts.ID == "" {
+		opts.ID = "urn:calcut:feed:" + name
+	}
+	feed, err := calcut.Atom(parsed.Events, opts)
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		_, err = os.Stdout.Write(feed)
+		return err
+	}
+	if err := validateOutputPath(*output); err != nil {
+		return err
+	}
+	if err := writeFile(*output, string(feed), mode); err != nil {
+		return err
+	}
+	report(i18n.T("피드 완료: %s -> %s (%s)\n"), inputs[0], *output, calcut.FormatBytes(int64(len(feed))))
+	return nil
+}
+
+// calendarName returns the X-WR-CALNAME of a calendar, unescaped.
+func calendarName(parsed calcut.ParsedCalendar) string {
+	for _, line := range parsed.HeaderLines {
+		if calcut.PropertyName(line) == "X-WR-CALNAME" {
+			if _, value, ok := strings.Cut(line, ":"); ok {
+				return calcut.UnescapeText(strings.TrimSpace(value))
+			}
+		}
+	}
+	return ""
+}
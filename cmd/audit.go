@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+	"github.com/sedurm85/calcut/pkg/calcut"
+)
+
+// auditLogName is where -redact-profile writes its audit log when
+// -audit-log is not given.
+const auditLogName = "audit.json"
+
+// auditLog collects, per UID, which properties the rewrites changed, for
+// -audit-log. Only property names are recorded, never values, so the log
+// cannot bring back data a transform removed on purpose. A nil *auditLog
+// records nothing.
+type auditLog struct {
+	Source    string        `json:"source"`
+	Generated time.Time     `json:"generated"`
+	Events    []*auditEvent `json:"events"`
+
+	byUID map[string]*auditEvent
+}
+
+type auditEvent struct {
+	UID     string        `json:"uid"`
+	Changes []auditChange `json:"changes"`
+}
+
+type auditChange struct {
+	Transform string `json:"transform"`
+	Action    string `json:"action"` // added, changed, removed, or dropped
+	Property  string `json:"property,omitempty"`
+}
+
+func newAuditLog(source string, now time.Time) *auditLog {
+	return &auditLog{
+		Source:    source,
+		Generated: now,
+		Events:    []*auditEvent{},
+		byUID:     make(map[string]*auditEvent),
+	}
+}
+
+// record notes every top-level property value that differs between before
+// and after, or the event as a whole when the difference lies elsewhere.
+// Events are keyed by their UID before the change.
+func (a *auditLog) record(transform string, before, after calcut.Event) {
+	if a == nil || before.Text == after.Text {
+		return
+	}
+	old := propertyValues(before.Text)
+	cur := propertyValues(after.Text)
+	var changes []auditChange
+	for _, name := range propertyNames(before.Text, after.Text) {
+		action := "changed"
+		switch o, c := old[name], cur[name]; {
+		case slices.Equal(o, c):
+			continue
+		case len(o) == 0:
+			action = "added"
+		case len(c) == 0:
+			action = "removed"
+		}
+		changes = append(changes, auditChange{Transform: transform, Action: action, Property: name})
+	}
+	if len(changes) == 0 {
+		// Only parameters or nested components (e.g. VALARM) differ.
+		changes = append(changes, auditChange{Transform: transform, Action: "changed"})
+	}
+	a.add(before.UID, changes...)
+}
+
+// dropped notes that transform removed the whole event.
+func (a *auditLog) dropped(transform string, event calcut.Event) {
+	if a == nil {
+		return
+	}
+	a.add(event.UID, auditChange{Transform: transform, Action: "dropped"})
+}
+
+func (a *auditLog) add(uid string, changes ...auditChange) {
+	if len(changes) == 0 {
+		return
+	}
+	e, ok := a.byUID[uid]
+	if !ok {
+		e = &auditEvent{UID: uid}
+		a.byUID[uid] = e
+		a.Events = append(a.Events, e)
+	}
+	e.Changes = append(e.Changes, changes...)
+}
+
+// writeAuditLog writes a to path, or to auditLogName among the output
+// files when path is empty, warning instead of failing the run.
+func writeAuditLog(a *auditLog, path string, opts splitOptions) {
+	if a == nil {
+		return
+	}
+	data, err := json.MarshalIndent(a, "", "  ")
+	if err == nil && path == "" {
+		err = writeOutput(auditLogName, string(data)+"\n", opts)
+	} else if err == nil {
+		err = writeFile(path, string(data)+"\n", opts.fileMode)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, i18n.T("경고: 감사 로그 기록 실패 - %s\n"), err)
+	}
+}
+
+func propertyValues(text string) map[string][]string {
+	values := make(map[string][]string)
+	for _, p := range calcut.TopLevelProperties(text) {
+		values[p.Name] = append(values[p.Name], p.Value)
+	}
+	return values
+}
+
+// propertyNames lists the property names of both texts in order of first
+// appearance.
+func propertyNames(texts ...string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, text := range texts {
+		for _, p := range calcut.TopLevelProperties(text) {
+			if !seen[p.Name] {
+				seen[p.Name] = true
+				names = append(names, p.Name)
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+	"github.com/sedurm85/calcut/pkg/calcut"
+)
+
+// calendarModes are the values of -calendars, for inputs holding several
+// VCALENDAR objects one after another: merge them into one calendar with
+// their headers deduplicated, or keep each in files of its own.
+var calendarModes = []string{"merge", "split"}
+
+// calendarSplit keeps the VCALENDARs of the inputs apart for -calendars
+// split. Events are told apart by UID, since they are filtered and
+// rewritten after parsing.
+type calendarSplit struct {
+	// keys name the calendars, by their X-WR-CALNAME or their place.
+	keys []string
+	// headers are the header lines of each calendar, by key.
+	headers map[string][]string
+	// byUID lists the calendars an UID appears in, once per appearance.
+	byUID map[string][]int
+}
+
+// newCalendarSplit records the calendars of the inputs, in order.
+func newCalendarSplit(cals []calcut.ParsedCalendar) *calendarSplit {
+	s := &calendarSplit{headers: make(map[string][]string), byUID: make(map[string][]int)}
+	seen := make(map[string]int)
+	for i, cal := range cals {
+		key := calendarName(cal)
+		if key == "" {
+			key = fmt.Sprintf("calendar_%03d", i+1)
+		}
+		if seen[key]++; seen[key] > 1 {
+			key = fmt.Sprintf("%s_%d", key, seen[key])
+		}
+		s.keys = append(s.keys, key)
+		s.headers[key] = cal.HeaderLines
+		for _, event := range cal.Events {
+			s.byUID[event.UID] = append(s.byUID[event.UID], i)
+		}
+	}
+	return s
+}
+
+// plan puts each event in the files of the calendar it came from, and
+// groups related events within each calendar only. An UID in several
+// calendars goes to them in turn; the occurrences -expand adds stay with
+// the last.
+func (s *calendarSplit) plan(parsed calcut.ParsedCalendar, opts calcut.SplitOptions) []calcut.Chunk {
+	used := make(map[string]int)
+	byCal := make([][]calcut.Event, len(s.keys))
+	for _, event := range parsed.Events {
+		cal := 0
+		if found := s.byUID[event.UID]; len(found) > 0 {
+			cal = found[min(used[event.UID], len(found)-1)]
+		}
+		used[event.UID]++
+		byCal[cal] = append(byCal[cal], event)
+	}
+	var groups [][]calcut.Event
+	var keys []string
+	for cal, events := range byCal {
+		for _, group := range calcut.GroupEvents(events, opts.Related) {
+			groups = append(groups, group)
+			keys = append(keys, s.keys[cal])
+		}
+	}
+	return calcut.PlanByKey(parsed, groups, keys, opts)
+}
+
+// header returns the header lines of the calendar a chunk was planned for,
+// or nil for chunks planned otherwise.
+func (s *calendarSplit) header(chunk calcut.Chunk) []string {
+	if s == nil {
+		return nil
+	}
+	return s.headers[chunk.Key]
+}
+
+// calendarsNote describes the calendars found in the inputs, for the
+// header of the run.
+func calendarsNote(count int, mode string) string {
+	if mode == "split" {
+		return fmt.Sprintf(i18n.T("   캘린더: %d개, 각각 따로 분할\n"), count)
+	}
+	return fmt.Sprintf(i18n.T("   캘린더: %d개를 합쳐 분할\n"), count)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+	"github.com/sedurm85/calcut/pkg/calcut"
+)
+
+// chunkData is the template context for -calendar-prop values, evaluated
+// once per output file.
+type chunkData struct {
+	Version  string
+	Now      time.Time
+	Source   string
+	Index    int
+	Total    int
+	Filename string
+	Events   int
+	From     string
+	To       string
+}
+
+// calendarPropSlack is added per property to the size reserve so values
+// that render longer than the sample (e.g. long summaries) still fit.
+const calendarPropSlack = 16
+
+// buildChunk renders one output file: the calendar header with the
+// per-chunk color and properties applied, the chunk's timezones, and its
+// events.
+func buildChunk(parsed calcut.ParsedCalendar, chunk calcut.Chunk, data chunkData, opts splitOptions) (string, error) {
+	cal, err := chunkCalendar(parsed, chunk, data, opts)
+	if err != nil {
+		return "", err
+	}
+	return cal.BuildChunk(chunk), nil
+}
+
+// chunkCalendar returns parsed with the per-chunk color and properties of
+// one output file applied to its header.
+func chunkCalendar(parsed calcut.ParsedCalendar, chunk calcut.Chunk, data chunkData, opts splitOptions) (calcut.ParsedCalendar, error) {
+	if len(opts.colors) > 0 {
+		parsed = parsed.WithColor(opts.colors[(data.Index-1)%len(opts.colors)])
+	}
+	if len(opts.calendarProps) == 0 {
+		return parsed, nil
+	}
+	events := chunk.Events
+
+	data.Version = version
+	data.Now = opts.now
+	data.Source = opts.source
+	data.Events = len(events)
+	data.From, data.To = calcut.DateRange(events)
+
+	header, err := renderCalendarProps(parsed.HeaderLines, opts.calendarProps, data)
+	if err != nil {
+		return parsed, err
+	}
+	parsed.HeaderLines = header
+	return parsed, nil
+}
+
+func renderCalendarProps(headerLines []string, props []stampProp, data chunkData) ([]string, error) {
+	header := append([]string(nil), headerLines...)
+	var b strings.Builder
+	for _, p := range props {
+		b.Reset()
+		if err := p.tmpl.Execute(&b, data); err != nil {
+			return nil, fmt.Errorf(i18n.T("-calendar-prop %s 적용 실패: %w"), p.name, err)
+		}
+		line := p.name + ":" + strings.NewReplacer("\r", "", "\n", `\n`).Replace(b.String())
+
+		replaced := false
+		for i, h := range header {
+			if calcut.PropertyName(strings.TrimSpace(h)) == p.name {
+				header[i] = line
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			header = append(header, line)
+		}
+	}
+	return header, nil
+}
+
+// calendarPropsReserve estimates how many bytes the per-chunk color and
+// properties add to the skeleton, so size-based planning leaves room for
+// them. n bounds the numbers the templates can see.
+func calendarPropsReserve(n int, opts splitOptions) int64 {
+	var colors int64
+	for _, c := range opts.colors {
+		colors = max(colors, c.HeaderSize())
+	}
+	if len(opts.calendarProps) == 0 {
+		return colors
+	}
+	sample := chunkData{
+		Version:  version,
+		Now:      opts.now,
+		Source:   opts.source,
+		Index:    n,
+		Total:    n,
+		Filename: fmt.Sprintf("%s_%03d.ics", opts.prefix, n),
+		Events:   n,
+		From:     "0000-00-00",
+		To:       "0000-00-00",
+	}
+	header, err := renderCalendarProps(nil, opts.calendarProps, sample)
+	if err != nil {
+		return colors
+	}
+	reserve := colors
+	for _, line := range header {
+		reserve += int64(len(line)) + 1 + calendarPropSlack
+	}
+	return reserve
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// casStore keeps output contents by their SHA-256 under dir, so that
+// repeated runs over a mostly unchanged calendar store each distinct file
+// once (-cas-output). Output files are hard links to the stored objects,
+// or symbolic links where hard links are not possible.
+type casStore struct {
+	dir     string
+	mode    os.FileMode
+	dirMode os.FileMode
+
+	// added and reused count the objects stored by this run and those
+	// already there.
+	added, reused int
+}
+
+// objectPath returns where content is stored: a subdirectory per first
+// two hex digits keeps directories small.
+func (s *casStore) objectPath(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(s.dir, name[:2], name+".ics")
+}
+
+// store writes content to the store unless it is there already and
+// returns its path. The object is written to a temporary file and renamed
+// into place, so an interrupted run never leaves a truncated object that
+// later runs would link to. The temporary file is created with the
+// object's mode, so that the umask applies as it does to writeFile.
+func (s *casStore) store(content string) (string, error) {
+	obj := s.objectPath(content)
+	if _, err := os.Stat(longPath(obj)); err == nil {
+		s.reused++
+		return obj, nil
+	}
+	if err := os.MkdirAll(longPath(filepath.Dir(obj)), s.dirMode); err != nil {
+		return "", err
+	}
+	tmp := filepath.Join(filepath.Dir(obj), ".tmp-"+rand.Text())
+	err := createFile(tmp, content, s.mode)
+	if err == nil {
+		err = os.Rename(longPath(tmp), longPath(obj))
+	}
+	if err != nil {
+		os.Remove(longPath(tmp))
+		return "", err
+	}
+	s.added++
+	return obj, nil
+}
+
+// link stores content and makes path a link to it. An existing file at
+// path is replaced only with force.
+func (s *casStore) link(path, content string, force bool) error {
+	obj, err := s.store(content)
+	if err != nil {
+		return err
+	}
+	if force {
+		if err := os.Remove(longPath(path)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	err = os.Link(longPath(obj), longPath(path))
+	if err != nil && !errors.Is(err, fs.ErrExist) {
+		// Hard links cannot cross file systems and are not supported
+		// everywhere; a relative symbolic link still saves the space.
+		target, _ := filepath.Abs(obj)
+		if dir, derr := filepath.Abs(filepath.Dir(path)); derr == nil {
+			if rel, rerr := filepath.Rel(dir, target); rerr == nil {
+				target = rel
+			}
+		}
+		err = os.Symlink(target, longPath(path))
+	}
+	if errors.Is(err, fs.ErrExist) {
+		return overwriteError(path)
+	}
+	return err
+}
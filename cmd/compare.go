@@ -0,0 +1,123 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+)
+
+// compareListLimit caps how many names compare-runs lists per section.
+const compareListLimit = 10
+
+// compareRuns implements "compare-runs A B": it summarizes how the chunking
+// changed between two runs from their manifests (or output directories).
+func compareRuns(args []string) error {
+	fs := flag.NewFlagSet("compare-runs", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, i18n.T("사용법: split-ical compare-runs <A/index.json> <B/index.json>\n\n출력 디렉토리를 주면 그 안의 %s 파일을 읽습니다.\n"), manifestName)
+	}
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	a, err := readManifest(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	b, err := readManifest(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	filesA, filesB := manifestNames(a), manifestNames(b)
+	locA, locB := manifestLocations(a), manifestLocations(b)
+
+	fmt.Printf(i18n.T("A: %s (%d개 파일, %d개 UID)%s\n"), fs.Arg(0), len(a.Files), len(locA), partialNote(a))
+	fmt.Printf(i18n.T("B: %s (%d개 파일, %d개 UID)%s\n\n"), fs.Arg(1), len(b.Files), len(locB), partialNote(b))
+
+	printNames(i18n.T("추가된 파일"), "+", missingFrom(filesB, filesA))
+	printNames(i18n.T("삭제된 파일"), "-", missingFrom(filesA, filesB))
+
+	var moved, onlyA, onlyB []string
+	for uid, file := range locA {
+		switch other, ok := locB[uid]; {
+		case !ok:
+			onlyA = append(onlyA, uid)
+		case other != file:
+			moved = append(moved, fmt.Sprintf("%s: %s -> %s", uid, file, other))
+		}
+	}
+	for uid := range locB {
+		if _, ok := locA[uid]; !ok {
+			onlyB = append(onlyB, uid)
+		}
+	}
+	sort.Strings(moved)
+	sort.Strings(onlyA)
+	sort.Strings(onlyB)
+
+	printNames(i18n.T("다른 파일로 옮겨진 이벤트"), "~", moved)
+	printNames(i18n.T("A에만 있는 이벤트"), "-", onlyA)
+	printNames(i18n.T("B에만 있는 이벤트"), "+", onlyB)
+
+	kept := len(locA) - len(onlyA) - len(moved)
+	fmt.Printf(i18n.T("같은 파일에 남은 이벤트: %d개\n"), kept)
+	return nil
+}
+
+func partialNote(m manifest) string {
+	if m.Partial {
+		return i18n.T(" [중단된 실행]")
+	}
+	return ""
+}
+
+func manifestNames(m manifest) []string {
+	names := make([]string, len(m.Files))
+	for i, f := range m.Files {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// manifestLocations maps every UID to the file that holds it.
+func manifestLocations(m manifest) map[string]string {
+	loc := make(map[string]string)
+	for _, f := range m.Files {
+		for _, uid := range f.UIDs {
+			loc[uid] = f.Name
+		}
+	}
+	return loc
+}
+
+// missingFrom returns the names in list that are not in other, in order.
+func missingFrom(list, other []string) []string {
+	have := make(map[string]bool, len(other))
+	for _, name := range other {
+		have[name] = true
+	}
+	var missing []string
+	for _, name := range list {
+		if !have[name] {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+func printNames(title, mark string, names []string) {
+	fmt.Printf(i18n.T("%s: %d개\n"), title, len(names))
+	for i, name := range names {
+		if i == compareListLimit {
+			fmt.Printf(i18n.T("  ... 외 %d개\n"), len(names)-i)
+			break
+		}
+		fmt.Printf("  %s %s\n", mark, name)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+	"github.com/sedurm85/calcut/pkg/calcut"
+)
+
+// conferenceTargets are the values of -target: the calendar system the
+// output is meant for, and which vendor conference properties it keeps.
+var conferenceTargets = map[string]string{
+	"google":  "google",
+	"outlook": "microsoft",
+	"plain":   "",
+}
+
+// targetAlarms are how the -target systems want alarms: Google drops
+// EMAIL alarms on import and reads only reminders relative to the start,
+// and Outlook turns absolute triggers into reminders at the wrong time.
+var targetAlarms = map[string]calcut.AlarmPolicy{
+	"google":  {Relative: true, Drop: []string{"EMAIL"}},
+	"outlook": {Relative: true},
+}
+
+// conferenceInliner copies the meeting links that the -target system
+// would drop into DESCRIPTION, so they survive the migration. The
+// original properties are left in place for systems that do read them.
+type conferenceInliner struct {
+	props []string
+}
+
+func newConferenceInliner(target string) (*conferenceInliner, error) {
+	vendor, ok := conferenceTargets[target]
+	if !ok {
+		return nil, fmt.Errorf(i18n.T("알 수 없는 -target: %s (%s 중 하나)"), target, strings.Join(slices.Sorted(maps.Keys(conferenceTargets)), ", "))
+	}
+	// No mainstream importer reads the standard CONFERENCE property yet.
+	props := []string{"CONFERENCE"}
+	for v, names := range calcut.ConferenceProperties {
+		if v != vendor {
+			props = append(props, names...)
+		}
+	}
+	slices.Sort(props)
+	return &conferenceInliner{props: props}, nil
+}
+
+// inline returns a copy of event whose DESCRIPTION ends with every
+// dropped meeting link it does not mention yet, one per line.
+func (c *conferenceInliner) inline(event calcut.Event) calcut.Event {
+	var description string
+	var urls []string
+	for _, p := range calcut.TopLevelProperties(event.Text) {
+		switch {
+		case p.Name == "DESCRIPTION" && description == "":
+			description = calcut.UnescapeText(p.Value)
+		case slices.Contains(c.props, p.Name) && p.Value != "":
+			urls = append(urls, p.Value)
+		}
+	}
+	added := description
+	for _, u := range urls {
+		if !strings.Contains(added, u) {
+			if added != "" {
+				added += "\n\n"
+			}
+			added += u
+		}
+	}
+	if added == description {
+		return event
+	}
+	return calcut.NewEvent(calcut.SetProperty(event.Text, "DESCRIPTION", calcut.EscapeText(added)))
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+)
+
+// findConfigArg looks for -config/--config among the raw arguments so the
+// file can be applied before the command line, which then takes precedence.
+func findConfigArg(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != "config" {
+			continue
+		}
+		if hasValue {
+			return value
+		}
+		if i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+// loadConfig applies "name = value" lines from path to fs, using the same
+// names as the command-line flags. Blank lines and lines starting with #
+// are ignored; repeatable flags may appear several times. lang is refused:
+// messages are already in the chosen language when the file is read.
+func loadConfig(fs *flag.FlagSet, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf(i18n.T("설정 파일을 읽을 수 없습니다 - %w"), err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return fmt.Errorf(i18n.T("%s:%d: \"이름 = 값\" 형식이 아닙니다"), path, lineNo)
+		}
+		name = strings.TrimSpace(name)
+		if name == "lang" {
+			// The locale is fixed before the config file is read.
+			return fmt.Errorf(i18n.T("%s:%d: lang은 설정 파일에 쓸 수 없습니다 (-lang 옵션이나 LANG 환경 변수를 쓰세요)"), path, lineNo)
+		}
+		if name == "config" || fs.Lookup(name) == nil {
+			return fmt.Errorf(i18n.T("%s:%d: 알 수 없는 옵션 %q"), path, lineNo, name)
+		}
+		if err := fs.Set(name, strings.TrimSpace(value)); err != nil {
+			return fmt.Errorf("%s:%d: %w", path, lineNo, err)
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+)
+
+const (
+	colorRed    = "31"
+	colorGreen  = "32"
+	colorYellow = "33"
+)
+
+// console decides how decorated the CLI output is. Emoji and ANSI colors
+// are only used on interactive terminals that can render them; logs
+// captured in CI or legacy Windows consoles get plain text.
+type console struct {
+	emoji bool
+	color bool
+}
+
+var term = console{emoji: true, color: true}
+
+func detectConsole(noEmoji, noColor bool) console {
+	interactive := isTerminal(os.Stdout) && os.Getenv("TERM") != "dumb"
+	legacy := isLegacyConsole()
+
+	c := console{
+		emoji: interactive && !legacy && !noEmoji,
+		color: interactive && !legacy && !noColor,
+	}
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		c.color = false
+	}
+	return c
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// icon returns the emoji when the console can show it, otherwise the plain
+// fallback (which may be empty).
+func (c console) icon(emoji, plain string) string {
+	if c.emoji {
+		return emoji
+	}
+	return plain
+}
+
+func (c console) paint(code, s string) string {
+	if !c.color {
+		return s
+	}
+	return "\x1b[" + code + "m" + s + "\x1b[0m"
+}
+
+// verboseFileLimit is the number of output files above which the per-file
+// listing collapses into a progress counter unless -print-every says
+// otherwise.
+const verboseFileLimit = 1000
+
+// progress reports how far a split has come. With every == 1 each file is
+// listed individually; larger values print a counter every N files. A zero
+// total means the number of files is not known in advance (-stream).
+type progress struct {
+	total  int
+	every  int
+	auto   bool
+	inline bool
+	// note, when set, adds to each counter, such as the upload queue.
+	note func() string
+}
+
+func newProgress(total, every int) *progress {
+	auto := every <= 0
+	if auto {
+		every = 1
+		if total > verboseFileLimit {
+			every = total / 100
+		}
+	}
+	return &progress{
+		total:  total,
+		every:  every,
+		auto:   auto,
+		inline: term.color && isTerminal(os.Stdout),
+	}
+}
+
+// detailed reports whether every file should be listed.
+func (p *progress) detailed() bool {
+	return p.every == 1
+}
+
+func (p *progress) step(done int) {
+	// Without a total the listing collapses once it outgrows the limit.
+	if p.total == 0 && p.auto && done > verboseFileLimit {
+		p.every = verboseFileLimit / 10
+	}
+	if p.detailed() || (done%p.every != 0 && done != p.total) {
+		return
+	}
+	p.print(done)
+}
+
+// finish prints the final counter when the total was not known up front.
+func (p *progress) finish(done int) {
+	if p.total != 0 || p.detailed() {
+		return
+	}
+	if done%p.every != 0 {
+		p.print(done)
+	}
+	if p.inline {
+		fmt.Println()
+	}
+}
+
+func (p *progress) print(done int) {
+	counter := fmt.Sprintf("%d/%d", done, p.total)
+	if p.total == 0 {
+		counter = fmt.Sprint(done)
+	}
+	note := ""
+	if p.note != nil {
+		note = p.note()
+	}
+	if p.inline {
+		// The note changes length, so clear what is left of the line.
+		fmt.Printf(i18n.T("\r  진행: %s 파일")+"%s\x1b[K", counter, note)
+		if done == p.total {
+			fmt.Println()
+		}
+		return
+	}
+	fmt.Printf(i18n.T("  진행: %s 파일")+"%s\n", counter, note)
+}
@@ -0,0 +1,15 @@
+package main
+
+import "os"
+
+// isLegacyConsole reports whether we are attached to the classic Windows
+// console host, which renders neither emoji nor ANSI escapes. Modern hosts
+// announce themselves through environment variables.
+func isLegacyConsole() bool {
+	for _, key := range []string{"WT_SESSION", "TERM_PROGRAM", "ANSICON", "ConEmuANSI"} {
+		if os.Getenv(key) != "" {
+			return false
+		}
+	}
+	return os.Getenv("TERM") == ""
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+	"github.com/sedurm85/calcut/pkg/calcut"
+)
+
+// diffEntry names one event of the diff report.
+type diffEntry struct {
+	UID          string `json:"uid"`
+	RecurrenceID string `json:"recurrenceId,omitempty"`
+	Summary      string `json:"summary"`
+	// Older marks a change to an older revision (EventChange.Older).
+	Older bool `json:"older,omitempty"`
+}
+
+func newDiffEntry(e calcut.Event) diffEntry {
+	return diffEntry{UID: e.UID, RecurrenceID: calcut.ExtractProperty(e.Text, "RECURRENCE-ID"), Summary: calcut.UnescapeText(e.Summary)}
+}
+
+// label names e in the human report: UID, the occurrence for an
+// override, and the summary.
+func (e diffEntry) label() string {
+	s := e.UID
+	if e.RecurrenceID != "" {
+		s += " @" + e.RecurrenceID
+	}
+	s += "  " + e.Summary
+	if e.Older {
+		s += i18n.T(" (이전 판으로 바뀜)")
+	}
+	return s
+}
+
+// diffCalendars implements "diff old.ics new.ics", which reports the
+// events added, removed and changed between two versions of a calendar
+// and can write the changes as scheduling messages for the attendees'
+// calendars.
+func diffCalendars(args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, i18n.T("결과를 JSON으로 출력"))
+	requestPath := fs.String("request", "", i18n.T("추가·변경된 이벤트를 METHOD:REQUEST 캘린더로 쓸 파일"))
+	cancelPath := fs.String("cancel", "", i18n.T("삭제된 이벤트를 METHOD:CANCEL 캘린더로 쓸 파일"))
+	fileMode := fs.String("file-mode", "0644", i18n.T("생성 파일 권한 (8진수, umask 적용)"))
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, i18n.T("사용법: split-ical diff [옵션] <이전.ics> <이후.ics>\n\n옵션:\n"))
+		fs.PrintDefaults()
+	}
+	inputs := parseInterspersed(fs, args)
+	if len(inputs) != 2 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	mode, err := parseFileMode(*fileMode)
+	if err != nil {
+		return err
+	}
+
+	var cals [2]calcut.ParsedCalendar
+	for i, path := range inputs {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if cals[i], err = calcut.ParseBytes(data, calcut.DefaultParseLimits()); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	d := calcut.Diff(cals[0].Events, cals[1].Events)
+
+	if *requestPath != "" {
+		events := append([]calcut.Event(nil), d.Added...)
+		for _, c := range d.Changed {
+			events = append(events, c.Reissue())
+		}
+		if err := writeMessage(*requestPath, cals[1].WithMethod("REQUEST"), events, mode); err != nil {
+			return err
+		}
+	}
+	if *cancelPath != "" {
+		now := time.Now()
+		var events []calcut.Event
+		for _, e := range d.Removed {
+			events = append(events, e.Cancellation(now))
+		}
+		if err := writeMessage(*cancelPath, cals[0].WithMethod("CANCEL"), events, mode); err != nil {
+			return err
+		}
+	}
+
+	report := struct {
+		Added     []diffEntry `json:"added"`
+		Removed   []diffEntry `json:"removed"`
+		Changed   []diffEntry `json:"changed"`
+		Unchanged int         `json:"unchanged"`
+	}{[]diffEntry{}, []diffEntry{}, []diffEntry{}, d.Unchanged}
+	for _, e := range d.Added {
+		report.Added = append(report.Added, newDiffEntry(e))
+	}
+	for _, e := range d.Removed {
+		report.Removed = append(report.Removed, newDiffEntry(e))
+	}
+	for _, c := range d.Changed {
+		e := newDiffEntry(c.New)
+		e.Older = c.Older()
+		report.Changed = append(report.Changed, e)
+	}
+
+	if *asJSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	for _, section := range []struct {
+		title, mark string
+		entries     []diffEntry
+	}{
+		{i18n.T("추가된 이벤트"), "+", report.Added},
+		{i18n.T("삭제된 이벤트"), "-", report.Removed},
+		{i18n.T("바뀐 이벤트"), "~", report.Changed},
+	} {
+		labels := make([]string, len(section.entries))
+		for i, e := range section.entries {
+			labels[i] = e.label()
+		}
+		printNames(section.title, section.mark, labels)
+	}
+	fmt.Printf(i18n.T("그대로인 이벤트: %d개\n"), d.Unchanged)
+	return nil
+}
+
+// writeMessage writes events under the header and timezones of cal,
+// unless there are none.
+func writeMessage(path string, cal calcut.ParsedCalendar, events []calcut.Event, mode os.FileMode) error {
+	if len(events) == 0 {
+		fmt.Fprintf(os.Stderr, i18n.T("%s: 쓸 이벤트가 없어 건너뜁니다\n"), path)
+		return nil
+	}
+	if err := validateOutputPath(path); err != nil {
+		return err
+	}
+	content := cal.BuildChunk(calcut.Chunk{Events: events, Timezones: cal.TimezonesFor(events)})
+	if err := writeFile(path, content, mode); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, i18n.T("%s: 이벤트 %d개 (%s)\n"), path, len(events), calcut.FormatBytes(int64(len(content))))
+	return nil
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+	"github.com/sedurm85/calcut/pkg/calcut"
+)
+
+// encryptedPrefix marks a property value encrypted by -encrypt-fields. The
+// version leaves room for other schemes without guessing.
+const encryptedPrefix = "calcut-enc:v1:"
+
+// encryptKeyEnv holds the key when no key file is given.
+const encryptKeyEnv = "CALCUT_ENCRYPT_KEY"
+
+// unencryptable are the properties calendars need in the clear to place,
+// repeat and match events, so encrypting them would break the very
+// scheduling -encrypt-fields means to keep.
+var unencryptable = []string{
+	"UID", "DTSTART", "DTEND", "DUE", "DURATION", "RRULE", "RDATE", "EXDATE", "EXRULE",
+	"RECURRENCE-ID", "SEQUENCE", "DTSTAMP", "TZID", "TRIGGER", "ACTION", "REPEAT",
+}
+
+// fieldEncryptor encrypts the values of chosen properties with AES-256-GCM
+// (-encrypt-fields), leaving times and everything else readable: the
+// result still imports and shows in any calendar, as busy time with
+// unreadable text, and "split-ical decrypt" brings the text back with the
+// key. Each value gets a fresh nonce and is bound to its property name, so
+// values cannot be moved between properties unnoticed.
+type fieldEncryptor struct {
+	fields []string
+	aead   cipher.AEAD
+}
+
+// newFieldEncryptor encrypts the properties of a comma-separated list such
+// as "DESCRIPTION,LOCATION" with the key read by loadEncryptKey.
+func newFieldEncryptor(list, keyFile string) (*fieldEncryptor, error) {
+	e := &fieldEncryptor{}
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToUpper(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if slices.Contains(unencryptable, name) {
+			return nil, fmt.Errorf(i18n.T("-encrypt-fields: %s는 일정을 맞추는 데 필요해 암호화할 수 없습니다"), name)
+		}
+		e.fields = append(e.fields, name)
+	}
+	if len(e.fields) == 0 {
+		return nil, errors.New(i18n.T("-encrypt-fields에 속성 이름이 없습니다"))
+	}
+	aead, err := loadEncryptKey(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	e.aead = aead
+	return e, nil
+}
+
+// loadEncryptKey reads a 256-bit key from path, or from CALCUT_ENCRYPT_KEY
+// when path is empty, written as 64 hex digits (as "openssl rand -hex 32"
+// makes), in base64, or as 32 raw bytes.
+func loadEncryptKey(path string) (cipher.AEAD, error) {
+	var data []byte
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, err
+		}
+	} else if env := os.Getenv(encryptKeyEnv); env != "" {
+		data = []byte(env)
+	} else {
+		return nil, fmt.Errorf(i18n.T("암호화 키가 없습니다 (키 파일을 주거나 %s 환경 변수를 설정)"), encryptKeyEnv)
+	}
+	key := data
+	text := strings.TrimSpace(string(data))
+	if k, err := hex.DecodeString(text); err == nil && len(k) == 32 {
+		key = k
+	} else if k, err := base64.StdEncoding.DecodeString(text); err == nil && len(k) == 32 {
+		key = k
+	}
+	if len(key) != 32 {
+		return nil, errors.New(i18n.T("암호화 키는 32바이트여야 합니다 (16진수 64자, base64, 또는 32바이트 그대로)"))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encrypt returns a copy of event with the chosen properties encrypted,
+// in nested components such as VALARM too, which often repeat the
+// description. Values already encrypted are left alone.
+func (e *fieldEncryptor) encrypt(event calcut.Event) calcut.Event {
+	text := calcut.MapProperties(event.Text, true, func(name, value string) (string, bool) {
+		if !slices.Contains(e.fields, name) || strings.HasPrefix(value, encryptedPrefix) {
+			return "", false
+		}
+		nonce := make([]byte, e.aead.NonceSize())
+		rand.Read(nonce)
+		sealed := e.aead.Seal(nonce, nonce, []byte(value), []byte(name))
+		return encryptedPrefix + base64.RawURLEncoding.EncodeToString(sealed), true
+	})
+	if text == event.Text {
+		return event
+	}
+	return calcut.NewEvent(text)
+}
+
+// decryptEvent returns a copy of event with every encrypted value
+// restored, and how many there were.
+func decryptEvent(aead cipher.AEAD, event calcut.Event) (calcut.Event, int, error) {
+	var err error
+	count := 0
+	text := calcut.MapProperties(event.Text, true, func(name, value string) (string, bool) {
+		if err != nil || !strings.HasPrefix(value, encryptedPrefix) {
+			return "", false
+		}
+		sealed, derr := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(value, encryptedPrefix))
+		if derr != nil || len(sealed) < aead.NonceSize() {
+			err = fmt.Errorf(i18n.T("%s의 %s: 암호문이 손상되었습니다"), event.UID, name)
+			return "", false
+		}
+		nonce, sealed := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+		plain, derr := aead.Open(nil, nonce, sealed, []byte(name))
+		if derr != nil {
+			err = fmt.Errorf(i18n.T("%s의 %s: 복호화할 수 없습니다 (키가 다르거나 내용이 바뀜)"), event.UID, name)
+			return "", false
+		}
+		count++
+		return string(plain), true
+	})
+	if err != nil {
+		return calcut.Event{}, 0, err
+	}
+	if count == 0 {
+		return event, 0, nil
+	}
+	return calcut.NewEvent(text), count, nil
+}
+
+// decryptCalendar implements "decrypt", which restores the values
+// -encrypt-fields encrypted in a calendar.
+func decryptCalendar(args []string) error {
+	fs := flag.NewFlagSet("decrypt", flag.ExitOnError)
+	output := fs.String("o", "", i18n.T("결과를 쓸 파일"))
+	keyFile := fs.String("key-file", "", i18n.T("암호화 키 파일 (기본: CALCUT_ENCRYPT_KEY 환경 변수)"))
+	fileMode := fs.String("file-mode", "0600", i18n.T("생성 파일 권한 (8진수, umask 적용)"))
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, i18n.T("사용법: split-ical decrypt [옵션] <입력.ics> -o <출력.ics>\n\n옵션:\n"))
+		fs.PrintDefaults()
+	}
+	inputs := parseInterspersed(fs, args)
+	if len(inputs) != 1 || *output == "" {
+		fs.Usage()
+		os.Exit(1)
+	}
+	mode, err := parseFileMode(*fileMode)
+	if err != nil {
+		return err
+	}
+	aead, err := loadEncryptKey(*keyFile)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(inputs[0])
+	if err != nil {
+		return err
+	}
+	parsed, err := calcut.ParseBytes(data, calcut.ParseLimits{})
+	if err != nil {
+		return fmt.Errorf("%s: %w", inputs[0], err)
+	}
+	events := make([]calcut.Event, len(parsed.Events))
+	values := 0
+	for i, event := range parsed.Events {
+		var n int
+		if events[i], n, err = decryptEvent(aead, event); err != nil {
+			return err
+		}
+		values += n
+	}
+
+	content := parsed.Build(events)
+	if err := validateOutputPath(*output); err != nil {
+		return err
+	}
+	if err := writeFile(*output, content, mode); err != nil {
+		return err
+	}
+	report(i18n.T("복호화 완료: 이벤트 %d개, 값 %d개 -> %s\n"), len(events), values, *output)
+	return nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+	"github.com/sedurm85/calcut/pkg/calcut"
+)
+
+// importRoute is a way of getting the split files into a calendar, as
+// -estimate reports it: by hand, one upload per file, or through an API,
+// one request per event or per UID at a sustained rate.
+type importRoute struct {
+	name   string
+	target string // the -target it imports into
+	// perUID counts one request per UID, since a recurring event and its
+	// overrides are one resource; otherwise every event is a request.
+	perUID bool
+	// rate is the requests per second the service sustains for one user
+	// before it throttles; 0 means a manual upload per file.
+	rate float64
+	// maxFile is the largest file the upload takes, 0 if not limited.
+	maxFile int64
+}
+
+// importRoutes are the routes -estimate reports on. The rates are the
+// published per-user quotas, or a rate that stays clear of throttling
+// where none is published.
+var importRoutes = []importRoute{
+	{name: "Google 웹 가져오기", target: "google", maxFile: 1 << 20},
+	{name: "Google Calendar API", target: "google", rate: 10},
+	{name: "Outlook 웹 가져오기", target: "outlook"},
+	{name: "Microsoft Graph", target: "outlook", rate: 4},
+	{name: "CalDAV", target: "plain", perUID: true, rate: 5},
+}
+
+// printEstimate prints to w how much work importing chunks would be by
+// each route, or by the routes into target if it is set. It is the
+// report the run was asked for, so it goes to w even with -quiet.
+func printEstimate(w io.Writer, chunks []calcut.Chunk, target string) {
+	events, resources := 0, 0
+	uids := make(map[string]bool)
+	for _, chunk := range chunks {
+		events += len(chunk.Events)
+		for _, e := range chunk.Events {
+			// An event without a UID is a resource of its own.
+			if e.UID == "" || !uids[e.UID] {
+				resources++
+			}
+			if e.UID != "" {
+				uids[e.UID] = true
+			}
+		}
+	}
+
+	fmt.Fprintf(w, i18n.T("%s가져오기 예상: 파일 %d개, 이벤트 %d개, UID %d개\n"), term.icon("📊 ", ""), len(chunks), events, resources)
+	for _, r := range importRoutes {
+		if target != "" && r.target != target {
+			continue
+		}
+		if r.rate == 0 {
+			line := fmt.Sprintf(i18n.T("업로드 %d번"), len(chunks))
+			if over := oversizedFiles(chunks, r.maxFile); over > 0 {
+				line += fmt.Sprintf(i18n.T(" (%s 넘는 파일 %d개는 거부됨)"), calcut.FormatBytes(r.maxFile), over)
+			}
+			fmt.Fprintf(w, "   %s: %s\n", i18n.T(r.name), line)
+			continue
+		}
+		requests := events
+		if r.perUID {
+			requests = resources
+		}
+		took := time.Duration(math.Ceil(float64(requests)/r.rate)) * time.Second
+		fmt.Fprintf(w, i18n.T("   %s: 요청 %d번, 초당 %g개 ≈ %s\n"), i18n.T(r.name), requests, r.rate, formatEstimate(took))
+	}
+	fmt.Fprintln(w, i18n.T("   (API 속도는 사용자 한 명의 일반 한도 기준 추정치이며 재시도와 할당량 초과는 포함하지 않음)"))
+	fmt.Fprintln(w)
+}
+
+// oversizedFiles counts the chunks larger than limit.
+func oversizedFiles(chunks []calcut.Chunk, limit int64) int {
+	n := 0
+	for _, chunk := range chunks {
+		if limit > 0 && chunk.Size > limit {
+			n++
+		}
+	}
+	return n
+}
+
+// formatEstimate writes d as roughly as an estimate deserves: "40초",
+// "52분", "2.2시간".
+func formatEstimate(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf(i18n.T("%d초"), int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf(i18n.T("%d분"), int(math.Round(d.Minutes())))
+	}
+	return fmt.Sprintf(i18n.T("%.1f시간"), d.Hours())
+}
@@ -0,0 +1,7 @@
+//go:build wasip1
+
+package main
+
+// WASI has no processes, so the hooks and -strategy-exec are rejected up
+// front rather than failing after the first file.
+const canRunCommands = false
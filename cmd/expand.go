@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+	"github.com/sedurm85/calcut/pkg/calcut"
+)
+
+// expandCalendar implements "expand", which writes a calendar whose
+// recurring events are replaced by their single instances, for importers
+// that cannot handle RRULE.
+func expandCalendar(args []string) error {
+	fs := flag.NewFlagSet("expand", flag.ExitOnError)
+	output := fs.String("o", "", i18n.T("결과를 쓸 파일"))
+	from := fs.String("from", "", i18n.T("이 날짜 이후에 시작하는 반복만 만듦 (예: 2024-03-05, 2024-03, today)"))
+	to := fs.String("to", "", i18n.T("이 날짜까지 시작하는 반복만 만듦 (예: 2024-12-31, next year)"))
+	strictDates := fs.Bool("strict-dates", false, i18n.T("-from, -to에 YYYY-MM-DD 날짜만 허용 (스크립트용)"))
+	tz := fs.String("tz", "", i18n.T("날짜와 시간대 없는 시각을 해석할 시간대 (기본: 시스템 시간대)"))
+	maxInstances := fs.Int("max-instances", calcut.DefaultMaxInstances, i18n.T("반복 일정 하나에서 만들 최대 개수 (-to 없이 끝없는 반복을 끊음)"))
+	uniqueUIDs := fs.Bool("unique-uids", false, i18n.T("반복마다 UID를 따로 붙이고 RECURRENCE-ID를 쓰지 않음"))
+	uidMapPath := fs.String("uid-map", "", i18n.T("-unique-uids로 바뀐 UID의 원래 값과 새 값을 기록할 CSV 파일 (old_uid,new_uid)"))
+	fileMode := fs.String("file-mode", "0644", i18n.T("생성 파일 권한 (8진수, umask 적용)"))
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, i18n.T("사용법: split-ical expand [옵션] <입력.ics> -o <출력.ics>\n\n옵션:\n"))
+		fs.PrintDefaults()
+	}
+	inputs := parseInterspersed(fs, args)
+	if len(inputs) != 1 || *output == "" {
+		fs.Usage()
+		os.Exit(1)
+	}
+	mode, err := parseFileMode(*fileMode)
+	if err != nil {
+		return err
+	}
+	loc, err := loadLocation(*tz)
+	if err != nil {
+		return err
+	}
+	window, err := parseWindow(*from, *to, *strictDates, time.Now(), loc)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(inputs[0])
+	if err != nil {
+		return err
+	}
+	parsed, err := calcut.ParseBytes(data, calcut.ParseLimits{})
+	if err != nil {
+		return fmt.Errorf("%s: %w", inputs[0], err)
+	}
+	expandOpts := calcut.ExpandOptions{Window: window, MaxInstances: *maxInstances, UniqueUIDs: *uniqueUIDs}
+	var uids *uidMap
+	if *uidMapPath != "" {
+		uids = newUIDMap()
+		expandOpts.Renamed = uids.add
+	}
+	events, err := calcut.Expand(parsed.Events, expandOpts)
+	if err != nil {
+		return err
+	}
+
+	content := parsed.Build(events)
+	if err := validateOutputPath(*output); err != nil {
+		return err
+	}
+	if err := writeFile(*output, content, mode); err != nil {
+		return err
+	}
+	if uids != nil {
+		if err := uids.write(*uidMapPath, mode); err != nil {
+			return err
+		}
+	}
+	report(i18n.T("펼치기 완료: 이벤트 %d개 -> %d개 -> %s (%s)\n"), len(parsed.Events), len(events), *output, calcut.FormatBytes(int64(len(content))))
+	return nil
+}
@@ -0,0 +1,35 @@
+//go:build !lite
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+	"github.com/sedurm85/calcut/pkg/calcut"
+)
+
+// fetchTimeout bounds the download of a calendar given by URL.
+const fetchTimeout = 30 * time.Second
+
+// fetchURL downloads a calendar over http or https, refusing more than
+// maxBytes.
+func fetchURL(url string, maxBytes int64) ([]byte, error) {
+	client := &http.Client{Timeout: fetchTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(i18n.T("%s: 내려받기 실패 (%s)"), url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err == nil && int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf(i18n.T("입력이 너무 큽니다 (최대 %s)"), calcut.FormatBytes(maxBytes))
+	}
+	return data, err
+}
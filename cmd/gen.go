@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+	"github.com/sedurm85/calcut/pkg/calcut"
+)
+
+// generateCalendar implements "gen", which writes a synthetic calendar
+// for benchmarks and for reproducing a problem without sharing the
+// calendar it happened with.
+func generateCalendar(args []string) error {
+	fs := flag.NewFlagSet("gen", flag.ExitOnError)
+	output := fs.String("o", "", i18n.T("결과를 쓸 파일 (기본: 표준 출력)"))
+	events := fs.Int("events", 1000, i18n.T("만들 이벤트 수 (반복 일정의 예외 회차 포함)"))
+	avgSize := fs.String("avg-size", "", i18n.T("이벤트 평균 크기, DESCRIPTION을 채워 맞춤 (예: 2k; 기본: 채우지 않음)"))
+	recurring := fs.String("recurring", "10%", i18n.T("RRULE이 있는 이벤트 비율 (예: 10%)"))
+	tzs := fs.String("tz", "Asia/Seoul,America/New_York,Europe/Berlin", i18n.T("이벤트에 고루 쓸 시간대, 쉼표로 구분 (UTC: 시간대 없이 UTC로)"))
+	summaries := fs.String("summaries", "", i18n.T("제목으로 쓸 문장 파일, 한 줄에 하나 (기본: 한국어와 영어 회의 제목)"))
+	start := fs.String("start", "2025-01-01", i18n.T("이벤트가 시작하는 첫날 (YYYY-MM-DD)"))
+	days := fs.Int("days", 365, i18n.T("이벤트를 흩어 놓을 날수"))
+	seed := fs.Uint64("seed", 1, i18n.T("난수 시드 (같은 옵션과 시드면 항상 같은 캘린더)"))
+	fileMode := fs.String("file-mode", "0644", i18n.T("생성 파일 권한 (8진수, umask 적용)"))
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, i18n.T("사용법: split-ical gen [옵션] [-o <출력.ics>]\n\n옵션:\n"))
+		fs.PrintDefaults()
+	}
+	if len(parseInterspersed(fs, args)) != 0 || *events < 0 || *days < 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	mode, err := parseFileMode(*fileMode)
+	if err != nil {
+		return err
+	}
+
+	opts := calcut.GenerateOptions{Events: *events, Days: *days, Seed: *seed}
+	if *avgSize != "" {
+		if opts.AvgSize, err = calcut.ParseSize(*avgSize); err != nil {
+			return err
+		}
+	}
+	if opts.Recurring, err = parsePercent(*recurring); err != nil {
+		return err
+	}
+	if opts.Start, err = time.Parse("2006-01-02", *start); err != nil {
+		return fmt.Errorf(i18n.T("잘못된 날짜: %s (YYYY-MM-DD)"), *start)
+	}
+	for _, tz := range strings.Split(*tzs, ",") {
+		if tz = strings.TrimSpace(tz); tz != "" && tz != "UTC" {
+			opts.Timezones = append(opts.Timezones, tz)
+		}
+	}
+	if *summaries != "" {
+		if opts.Summaries, err = readLines(*summaries); err != nil {
+			return err
+		}
+	}
+
+	if *output == "" {
+		return calcut.Generate(os.Stdout, opts)
+	}
+	if err := validateOutputPath(*output); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(longPath(*output), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	err = calcut.Generate(f, opts)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(longPath(*output))
+	if err != nil {
+		return err
+	}
+	report(i18n.T("생성 완료: 이벤트 %d개 -> %s (%s)\n"), *events, *output, calcut.FormatBytes(info.Size()))
+	return nil
+}
+
+// parsePercent parses a fraction written as a percentage, "10%" or "10".
+func parsePercent(s string) (float64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(s), "%"), 64)
+	if err != nil || v < 0 || v > 100 {
+		return 0, fmt.Errorf(i18n.T("잘못된 비율: %s (0%%~100%%)"), s)
+	}
+	return v / 100, nil
+}
+
+// readLines returns the non-blank lines of the file at path, trimmed.
+func readLines(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// globals are the options shared by every subcommand, given before its
+// name ("split-ical -quiet -output-dir out html ./split"). Split takes
+// them among its own options as well.
+var globals struct {
+	// outputDir, when set, is the default output directory of split,
+	// and of html and assemble instead of writing next to their input.
+	outputDir string
+	// quiet leaves out progress and summaries; errors, warnings and
+	// the data a command is asked for are still printed.
+	quiet bool
+}
+
+// parseGlobals takes the global options off the front of args and returns
+// the rest, starting with the subcommand name or, for the split alias,
+// with split's own options.
+func parseGlobals(args []string) ([]string, error) {
+	for len(args) > 0 {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(args[0], "-"), "=")
+		if !strings.HasPrefix(args[0], "-") {
+			return args, nil
+		}
+		switch name {
+		case "quiet":
+			quiet := true
+			if hasValue {
+				var err error
+				if quiet, err = strconv.ParseBool(value); err != nil {
+					return nil, fmt.Errorf("-quiet=%s: %w", value, err)
+				}
+			}
+			globals.quiet = quiet
+			args = args[1:]
+		case "output-dir":
+			if !hasValue {
+				if len(args) < 2 {
+					return args, nil
+				}
+				value, args = args[1], args[1:]
+			}
+			globals.outputDir = value
+			args = args[1:]
+		default:
+			return args, nil
+		}
+	}
+	return args, nil
+}
+
+// report prints a progress or summary line unless -quiet is given.
+func report(format string, args ...any) {
+	if !globals.quiet {
+		fmt.Printf(format, args...)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+	"github.com/sedurm85/calcut/pkg/calcut"
+)
+
+// categoriesMode is the -group-by value that buckets by CATEGORIES, the
+// one property whose several values each get a bucket.
+const categoriesMode = "CATEGORIES"
+
+// uncategorizedBucket collects events without CATEGORIES when splitting
+// with -group-by categories.
+const uncategorizedBucket = "uncategorized"
+
+var propertyNamePattern = regexp.MustCompile(`^[A-Z0-9-]+$`)
+
+// parseGroupBy checks a -group-by property name and returns it
+// upper-cased.
+func parseGroupBy(prop string) (string, error) {
+	name := strings.ToUpper(strings.TrimSpace(prop))
+	if !propertyNamePattern.MatchString(name) {
+		return "", fmt.Errorf(i18n.T("잘못된 -group-by 속성 이름: %s (예: categories, LOCATION, X-CUSTOM)"), prop)
+	}
+	return name, nil
+}
+
+// missingBucket names the bucket of events without prop.
+func missingBucket(prop string) string {
+	if prop == categoriesMode {
+		return uncategorizedBucket
+	}
+	return "no-" + strings.ToLower(prop)
+}
+
+// groupByKeys buckets groups by the value of prop in their first event.
+// For CATEGORIES a group with several categories is repeated once per
+// category, or only goes to the first one when first is set, so the
+// returned groups and keys line up for PlanByKey. Keys are file names
+// already, so values that sanitize alike share a file.
+func groupByKeys(groups [][]calcut.Event, prop string, first bool) ([][]calcut.Event, []string) {
+	var outGroups [][]calcut.Event
+	var keys []string
+	for _, group := range groups {
+		values := groupValues(group[0], prop)
+		if len(values) == 0 {
+			outGroups = append(outGroups, group)
+			keys = append(keys, missingBucket(prop))
+			continue
+		}
+		if first {
+			values = values[:1]
+		}
+		seen := make(map[string]bool)
+		for _, v := range values {
+			key := calcut.SanitizeFilename(v)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			outGroups = append(outGroups, group)
+			keys = append(keys, key)
+		}
+	}
+	return outGroups, keys
+}
+
+// groupValues returns the bucket values of prop in event: every category
+// for CATEGORIES, otherwise the value of the first occurrence. Calendar
+// addresses lose their "mailto:".
+func groupValues(event calcut.Event, prop string) []string {
+	if prop == categoriesMode {
+		var values []string
+		for _, c := range event.Details().Categories {
+			values = append(values, calcut.UnescapeText(c))
+		}
+		return values
+	}
+	for _, p := range calcut.TopLevelProperties(event.Text) {
+		if p.Name != prop || p.Value == "" {
+			continue
+		}
+		value := calcut.UnescapeText(p.Value)
+		if strings.HasPrefix(strings.ToLower(value), "mailto:") {
+			value = value[len("mailto:"):]
+		}
+		return []string{value}
+	}
+	return nil
+}
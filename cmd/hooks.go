@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+)
+
+// runHook runs a user-supplied shell command. Every "{}" in the command is
+// replaced with the quoted target path, which is also exported as
+// CALCUT_TARGET for hooks that prefer the environment.
+func runHook(ctx context.Context, command, target string) error {
+	expanded := strings.ReplaceAll(command, "{}", shellQuote(target))
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", expanded)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", expanded)
+	}
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), "CALCUT_TARGET="+target)
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf(i18n.T("훅 실행 실패 (%s): %w"), expanded, err)
+	}
+	return nil
+}
+
+func shellQuote(s string) string {
+	if runtime.GOOS == "windows" {
+		return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
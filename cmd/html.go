@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"flag"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+	"github.com/sedurm85/calcut/pkg/calcut"
+)
+
+// undatedPage collects the events without a readable DTSTART.
+const undatedPage = "undated"
+
+// htmlEvent is one event as the archive pages show it. File links to the
+// chunk holding it, relative to the pages.
+type htmlEvent struct {
+	start       time.Time
+	Summary     string
+	When        string
+	Location    string
+	Description string
+	Recurring   bool
+	File        string
+}
+
+// htmlMonth is a page of the archive: the events starting in one month,
+// or those without a date.
+type htmlMonth struct {
+	Key    string
+	Events []htmlEvent
+}
+
+// htmlChunk is a chunk file as listed on the index page.
+type htmlChunk struct {
+	Name   string
+	Link   string
+	Events int
+}
+
+var htmlPages = template.Must(template.New("").Funcs(template.FuncMap{"t": i18n.T, "lang": i18n.Locale}).Parse(`
+{{define "head"}}<!DOCTYPE html>
+<html lang="{{lang}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.}}</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 48rem; margin: 2rem auto; padding: 0 1rem; color: #222; }
+a { color: #1565c0; }
+ul { padding-left: 1.2rem; }
+details { border-bottom: 1px solid #ddd; padding: .5rem 0; }
+summary { cursor: pointer; }
+.when { color: #666; font-variant-numeric: tabular-nums; margin-right: .5rem; }
+.desc { white-space: pre-wrap; }
+</style>
+</head>
+<body>
+{{end}}
+{{define "index"}}{{template "head" .Title}}<h1>{{.Title}}</h1>
+<h2>{{t "월별"}}</h2>
+<ul>
+{{range .Months}}<li><a href="{{.Key}}.html">{{if eq .Key "undated"}}{{t "날짜 없음"}}{{else}}{{.Key}}{{end}}</a> ({{printf (t "%d개") (len .Events)}})</li>
+{{end}}</ul>
+<h2>{{t "파일"}}</h2>
+<ul>
+{{range .Chunks}}<li><a href="{{.Link}}">{{.Name}}</a> ({{printf (t "%d개") .Events}})</li>
+{{end}}</ul>
+</body>
+</html>
+{{end}}
+{{define "month"}}{{template "head" .Month.Key}}<p><a href="index.html">{{.Title}}</a></p>
+<h1>{{if eq .Month.Key "undated"}}{{t "날짜 없음"}}{{else}}{{.Month.Key}}{{end}}</h1>
+{{range .Month.Events}}<details>
+<summary><span class="when">{{.When}}</span>{{.Summary}}{{if .Recurring}} ({{t "반복"}}){{end}}</summary>
+{{if .Location}}<p>{{t "장소:"}} {{.Location}}</p>{{end}}
+{{if .Description}}<p class="desc">{{.Description}}</p>{{end}}
+<p><a href="{{.File}}">{{t ".ics 내려받기"}}</a></p>
+</details>
+{{end}}</body>
+</html>
+{{end}}`))
+
+// renderHTML implements "html", which turns a split output directory into
+// a static site: an index of months and chunk files, and a page per month
+// listing its events with their details and a link to their chunk.
+func renderHTML(args []string) error {
+	flags := flag.NewFlagSet("html", flag.ExitOnError)
+	output := flags.String("o", "", i18n.T("사이트를 쓸 디렉토리 (기본: 입력 디렉토리)"))
+	title := flags.String("title", i18n.T("캘린더 보관함"), i18n.T("페이지 제목"))
+	tz := flags.String("tz", "", i18n.T("날짜와 시각을 보여 줄 시간대 (기본: 시스템 시간대)"))
+	fileMode := flags.String("file-mode", "0644", i18n.T("생성 파일 권한 (8진수, umask 적용)"))
+	flags.Usage = func() {
+		fmt.Fprint(os.Stderr, i18n.T("사용법: split-ical html [옵션] <분할 출력 디렉토리> [-o 사이트 디렉토리]\n\n옵션:\n"))
+		flags.PrintDefaults()
+	}
+	inputs := parseInterspersed(flags, args)
+	if len(inputs) != 1 {
+		flags.Usage()
+		os.Exit(1)
+	}
+	mode, err := parseFileMode(*fileMode)
+	if err != nil {
+		return err
+	}
+	loc, err := loadLocation(*tz)
+	if err != nil {
+		return err
+	}
+	outDir := cmp.Or(*output, globals.outputDir, inputs[0])
+
+	files, err := searchFiles(inputs[0])
+	if err != nil {
+		return err
+	}
+	months := make(map[string]*htmlMonth)
+	var chunks []htmlChunk
+	for _, f := range files {
+		data, err := os.ReadFile(f.path)
+		if err != nil {
+			return err
+		}
+		parsed, err := calcut.ParseBytes(data, calcut.DefaultParseLimits())
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.path, err)
+		}
+		link, err := relativeLink(outDir, f.path)
+		if err != nil {
+			return err
+		}
+		chunks = append(chunks, htmlChunk{Name: f.name, Link: link, Events: len(parsed.Events)})
+		for _, ev := range parsed.Events {
+			e, key := newHTMLEvent(ev, link, loc)
+			if months[key] == nil {
+				months[key] = &htmlMonth{Key: key}
+			}
+			months[key].Events = append(months[key].Events, e)
+		}
+	}
+
+	var list []htmlMonth
+	for _, m := range months {
+		slices.SortStableFunc(m.Events, func(a, b htmlEvent) int { return a.start.Compare(b.start) })
+		list = append(list, *m)
+	}
+	// Dated months in order, the undated page last.
+	slices.SortFunc(list, func(a, b htmlMonth) int {
+		return cmp.Or(cmp.Compare(boolRank(a.Key == undatedPage), boolRank(b.Key == undatedPage)), cmp.Compare(a.Key, b.Key))
+	})
+
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return err
+	}
+	pages := 0
+	write := func(name, tmpl string, data any) error {
+		var buf bytes.Buffer
+		if err := htmlPages.ExecuteTemplate(&buf, tmpl, data); err != nil {
+			return err
+		}
+		path := filepath.Join(outDir, name)
+		if err := validateOutputPath(path); err != nil {
+			return err
+		}
+		pages++
+		return writeFile(path, buf.String(), mode)
+	}
+	if err := write("index.html", "index", struct {
+		Title  string
+		Months []htmlMonth
+		Chunks []htmlChunk
+	}{*title, list, chunks}); err != nil {
+		return err
+	}
+	for _, m := range list {
+		if err := write(m.Key+".html", "month", struct {
+			Title string
+			Month htmlMonth
+		}{*title, m}); err != nil {
+			return err
+		}
+	}
+	report(i18n.T("사이트 완료: 파일 %d개, 월 %d개, 페이지 %d개 -> %s/index.html\n"), len(chunks), len(list), pages, outDir)
+	return nil
+}
+
+// newHTMLEvent prepares ev for the pages and returns the key of the page
+// it goes on.
+func newHTMLEvent(ev calcut.Event, link string, loc *time.Location) (htmlEvent, string) {
+	d := ev.Details()
+	e := htmlEvent{
+		Summary:     calcut.UnescapeText(ev.Summary),
+		Location:    calcut.UnescapeText(d.Location.Value),
+		Description: calcut.UnescapeText(d.Description.Value),
+		Recurring:   d.RRule.Value != "",
+		File:        link,
+	}
+	if e.Summary == "" {
+		e.Summary = i18n.T("(제목 없음)")
+	}
+	start, allDay, err := ev.Start(loc)
+	if err != nil {
+		return e, undatedPage
+	}
+	e.start = start
+	if allDay {
+		e.When = start.Format(i18n.T("01-02 종일"))
+	} else {
+		e.When = start.Format("01-02 15:04")
+	}
+	return e, start.Format("2006-01")
+}
+
+// relativeLink returns the slash-separated link to path from a page in
+// dir.
+func relativeLink(dir, path string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(absDir, absPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
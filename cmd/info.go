@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+	"github.com/sedurm85/calcut/pkg/calcut"
+)
+
+// infoReport is the -json form of one file's report.
+type infoReport struct {
+	File string `json:"file"`
+	Size int64  `json:"size"`
+	calcut.CalendarStats
+}
+
+// showInfo implements "info", a report on a calendar before splitting it:
+// what it holds, over which dates, and which events make it large.
+func showInfo(args []string) error {
+	fs := flag.NewFlagSet("info", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, i18n.T("결과를 JSON으로 출력"))
+	top := fs.Int("top", 5, i18n.T("크기순으로 보여 줄 큰 이벤트 수"))
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, i18n.T("사용법: split-ical info [옵션] <입력.ics>...\n\n옵션:\n"))
+		fs.PrintDefaults()
+	}
+	inputs := parseInterspersed(fs, args)
+	if len(inputs) == 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	reports := make([]infoReport, 0, len(inputs))
+	for _, path := range inputs {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		parsed, err := calcut.ParseBytes(data, calcut.DefaultParseLimits())
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		reports = append(reports, infoReport{File: path, Size: int64(len(data)), CalendarStats: parsed.Stats(*top)})
+	}
+
+	if *asJSON {
+		data, err := json.MarshalIndent(reports, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	for i, r := range reports {
+		if i > 0 {
+			fmt.Println()
+		}
+		printInfo(r)
+	}
+	return nil
+}
+
+func printInfo(r infoReport) {
+	fmt.Printf("%s (%s)\n", r.File, calcut.FormatBytes(r.Size))
+	if r.ProdID != "" {
+		fmt.Printf(i18n.T("  PRODID:   %s\n"), r.ProdID)
+	}
+	var kinds []string
+	for _, kind := range calcut.ComponentKinds {
+		if n := r.Components[kind]; n > 0 {
+			kinds = append(kinds, fmt.Sprintf("%s %d", kind, n))
+		}
+	}
+	fmt.Printf(i18n.T("  이벤트:   %d개 (UID %d개)"), r.Events, r.UIDs)
+	if len(kinds) > 0 {
+		fmt.Printf(" - %s", strings.Join(kinds, ", "))
+	}
+	fmt.Println()
+	if r.From != "" {
+		fmt.Printf(i18n.T("  기간:     %s ~ %s\n"), r.From, r.To)
+	}
+	fmt.Printf(i18n.T("  반복:     반복 일정 %d개, 예외 회차 %d개\n"), r.Recurring, r.Overrides)
+	fmt.Printf(i18n.T("  시간대:   VTIMEZONE %d개"), r.Timezones)
+	if len(r.TZIDs) > 0 {
+		fmt.Printf(i18n.T(", 사용 중인 TZID %d개 (%s)"), len(r.TZIDs), strings.Join(r.TZIDs, ", "))
+	}
+	fmt.Println()
+
+	fmt.Println(i18n.T("  이벤트 크기:"))
+	most := 0
+	for _, b := range r.Sizes {
+		most = max(most, b.Events)
+	}
+	lower := int64(0)
+	for _, b := range r.Sizes {
+		label := fmt.Sprintf(i18n.T("%s 초과"), calcut.FormatBytes(lower))
+		if b.UpTo > 0 {
+			label = fmt.Sprintf(i18n.T("%s 이하"), calcut.FormatBytes(b.UpTo))
+		}
+		bar := 0
+		if most > 0 {
+			bar = (b.Events*30 + most - 1) / most
+		}
+		fmt.Printf("    %-12s %6d %s\n", label, b.Events, strings.Repeat("#", bar))
+		lower = b.UpTo
+	}
+
+	if len(r.Largest) > 0 {
+		fmt.Println(i18n.T("  큰 이벤트:"))
+		for _, e := range r.Largest {
+			fmt.Printf("    %10s  %s  %s\n", calcut.FormatBytes(e.Size), e.UID, strings.ReplaceAll(e.Summary, "\n", " "))
+		}
+	}
+}
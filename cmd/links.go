@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+	"github.com/sedurm85/calcut/pkg/calcut"
+)
+
+// linkEntry is one line of the links report.
+type linkEntry struct {
+	File    string `json:"file"`
+	UID     string `json:"uid"`
+	Summary string `json:"summary"`
+	calcut.Link
+}
+
+// listLinks implements "links", a report of the URLs and meeting links in
+// a calendar, to check what a shared archive would give away. Running the
+// split with -redact-profile links removes them.
+func listLinks(args []string) error {
+	fs := flag.NewFlagSet("links", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, i18n.T("결과를 JSON으로 출력"))
+	conferenceOnly := fs.Bool("conference", false, i18n.T("화상 회의 링크(Zoom, Meet, Teams 등)만 출력"))
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, i18n.T("사용법: split-ical links [옵션] <입력.ics>...\n\n옵션:\n"))
+		fs.PrintDefaults()
+	}
+	inputs := parseInterspersed(fs, args)
+	if len(inputs) == 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	entries := []linkEntry{}
+	for _, path := range inputs {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		parsed, err := calcut.ParseBytes(data, calcut.DefaultParseLimits())
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		for _, ev := range parsed.Events {
+			for _, link := range ev.Links() {
+				if *conferenceOnly && !link.Conference {
+					continue
+				}
+				entries = append(entries, linkEntry{File: path, UID: ev.UID, Summary: ev.Title(), Link: link})
+			}
+		}
+	}
+
+	if *asJSON {
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	conferences := 0
+	for _, e := range entries {
+		kind := "URL"
+		if e.Conference {
+			kind = i18n.T("회의")
+			conferences++
+		}
+		fmt.Printf("%s\t%s\t%s\t%s\t%s\n", kind, e.UID, e.Summary, e.Property, e.URL)
+	}
+	fmt.Fprintf(os.Stderr, i18n.T("링크 %d개 (화상 회의 %d개)\n"), len(entries), conferences)
+	return nil
+}
@@ -0,0 +1,29 @@
+//go:build lite
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+)
+
+// The lite build leaves out net/http: there is no "serve", and calendars
+// are read from files and standard input only and written to local
+// outputs only.
+
+func init() {
+	subcommands["serve"] = func([]string) error {
+		return errors.New(i18n.T("이 빌드에는 serve가 없습니다 (lite 태그 없이 빌드하세요)"))
+	}
+}
+
+func fetchURL(url string, maxBytes int64) ([]byte, error) {
+	return nil, fmt.Errorf(i18n.T("%s: 이 빌드는 URL 입력을 지원하지 않습니다 (lite 태그 없이 빌드하세요)"), url)
+}
+
+func newUploadArchive(ctx context.Context, rawURL string, workers, queue, retries int) (archive, error) {
+	return nil, errors.New(i18n.T("이 빌드는 업로드를 지원하지 않습니다 (lite 태그 없이 빌드하세요)"))
+}
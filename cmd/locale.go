@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+)
+
+// setupLocale picks the language of messages before any flag is parsed,
+// so that usage texts are translated too: from a -lang option anywhere on
+// the command line, which it removes from args, else from the LC_ALL,
+// LC_MESSAGES or LANG environment variable. It returns the remaining
+// arguments.
+func setupLocale(args []string) ([]string, error) {
+	lang := ""
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != "lang" {
+			rest = append(rest, arg)
+			continue
+		}
+		if !hasValue {
+			if i+1 == len(args) {
+				return nil, fmt.Errorf(i18n.T("-lang에 값이 없습니다 (%s 중 하나)"), strings.Join(i18n.Locales(), ", "))
+			}
+			i++
+			value = args[i]
+		}
+		lang = value
+	}
+	if lang != "" {
+		tag := strings.ToLower(lang)
+		if j := strings.IndexAny(tag, "-_."); j >= 0 {
+			tag = tag[:j]
+		}
+		if !slices.Contains(i18n.Locales(), tag) {
+			return nil, fmt.Errorf(i18n.T("알 수 없는 언어: %s (%s 중 하나)"), lang, strings.Join(i18n.Locales(), ", "))
+		}
+		i18n.SetLocale(tag)
+		return rest, nil
+	}
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(env); value != "" {
+			i18n.SetLocale(value)
+			break
+		}
+	}
+	return rest, nil
+}
@@ -1,350 +1,1118 @@
 package main
 
 import (
-	"flag"
+	"cmp"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
 	"os"
 	"path/filepath"
-	"regexp"
+	"runtime"
 	"strconv"
 	"strings"
-	"unicode/utf8"
+	"sync"
+	"time"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+	"github.com/sedurm85/calcut/pkg/calcut"
 )
 
-type Event struct {
-	Text    string
-	Summary string
-	UID     string
-	DTStart string
-}
+// splitOptions carries the settings shared by all split modes.
+type splitOptions struct {
+	outDir     string
+	prefix     string
+	maxBytes   int64
+	maxEvents  int
+	contiguous bool
+	printEvery int
+	fileMode   os.FileMode
+	postHook   string
+	// force overwrites existing output files instead of refusing to.
+	force bool
+	// sharedSkeleton writes the header and timezones once to
+	// skeletonName and only the events to each output file
+	// (-shared-skeleton).
+	sharedSkeleton bool
+	// jobs is how many files writeChunks builds and writes at once
+	// (-jobs).
+	jobs int
 
-type ParsedCalendar struct {
-	HeaderLines []string
-	Timezones   []string
-	Events      []Event
-}
+	// names, when set, names the output files instead of the default
+	// patterns (-name-template).
+	names calcut.NameTemplate
+	// strictNames fails on output files whose names collide instead of
+	// renaming them (-strict-names).
+	strictNames bool
 
-func parseIcal(content string) ParsedCalendar {
-	lines := strings.Split(content, "\n")
+	// archive, when set, receives the output files instead of outDir.
+	archive archive
+	// cas, when set, stores the contents of the output files, which
+	// become links to them (-cas-output).
+	cas *casStore
 
-	var headerLines []string
-	var timezones []string
-	var events []Event
+	// manifestOut, when set, receives the manifest instead of outDir
+	// (-stdout-manifest).
+	manifestOut io.Writer
 
-	var currentBlock []string
-	blockType := ""
-	// RFC 5545: BEGIN/END can nest (e.g. VALARM inside VEVENT)
-	nesting := 0
+	// allTimezones puts every VTIMEZONE of the input in every file.
+	allTimezones bool
+	// verbose prints the problems lenient parsing recovered from, which
+	// parseWarnings collects for the manifest.
+	verbose       bool
+	parseWarnings *[]manifestWarning
 
-	for _, line := range lines {
-		stripped := strings.TrimSpace(line)
+	// checksums stamps every event with its X-CALCUT-SHA256 (-checksum).
+	checksums bool
+	// lineEnding, when set, replaces the line ending of the input in the
+	// output files (-line-ending).
+	lineEnding string
+	// timezonesFirst writes the header properties naming a timezone
+	// after the VTIMEZONEs (-timezones-first).
+	timezonesFirst bool
+	// calendars, when set, keeps the VCALENDARs of the inputs in files of
+	// their own (-calendars split).
+	calendars *calendarSplit
 
-		if stripped == "BEGIN:VCALENDAR" || stripped == "END:VCALENDAR" {
-			continue
-		}
+	// colors are handed out to the output files in turn as their
+	// calendar color.
+	colors []calcut.Color
 
-		if strings.HasPrefix(stripped, "BEGIN:") && blockType == "" {
-			blockType = strings.SplitN(stripped, ":", 2)[1]
-			currentBlock = []string{line}
-			nesting = 1
-			continue
-		}
+	// kinds, window and hours select the input components to split:
+	// those of the -components kinds starting within -from/-to and
+	// overlapping the -within hours.
+	kinds  []string
+	window calcut.DateWindow
+	hours  calcut.HoursWindow
 
-		if blockType != "" {
-			currentBlock = append(currentBlock, line)
+	// dropCancelled drops cancelled events and series (-drop-cancelled).
+	dropCancelled bool
 
-			if strings.HasPrefix(stripped, "BEGIN:") {
-				nesting++
-			} else if strings.HasPrefix(stripped, "END:") {
-				nesting--
-			}
+	// partStat drops events by the -me attendee's answer, people keeps
+	// those of the -attendee and -organizer people, durations those
+	// within -min-duration and -max-duration and text those passing
+	// -match and -exclude.
+	partStat  calcut.PartStatFilter
+	people    calcut.PeopleFilter
+	durations calcut.DurationFilter
+	text      textFilter
 
-			if nesting == 0 {
-				blockText := strings.Join(currentBlock, "\n")
-
-				switch blockType {
-				case "VTIMEZONE":
-					timezones = append(timezones, blockText)
-				case "VEVENT":
-					events = append(events, Event{
-						Text:    blockText,
-						Summary: extractProperty(blockText, "SUMMARY"),
-						UID:     extractProperty(blockText, "UID"),
-						DTStart: extractProperty(blockText, "DTSTART"),
-					})
-				}
+	// langs are the languages whose SUMMARY variant names files and is
+	// listed, in order of preference (-prefer-lang).
+	langs []string
 
-				blockType = ""
-				currentBlock = nil
-			}
-			continue
+	// listSummaries prints each file's event summary in the detailed
+	// listing, which is what per-event mode shows instead of sizes.
+	listSummaries bool
+
+	calendarProps []stampProp
+	source        string
+	now           time.Time
+}
+
+// parseFileMode parses an octal permission string such as "0640". The
+// process umask is still applied on top when files are created.
+func parseFileMode(s string) (os.FileMode, error) {
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || mode > 0777 {
+		return 0, fmt.Errorf(i18n.T("잘못된 권한 값: %s (예: 0644)"), s)
+	}
+	return os.FileMode(mode), nil
+}
+
+// selectEvents applies the filters of opts that come before rewriting:
+// component kinds, cancelled events, and the attendee, duration and text
+// filters.
+func selectEvents(events []calcut.Event, opts splitOptions) []calcut.Event {
+	events = calcut.FilterKinds(events, opts.kinds)
+	if opts.dropCancelled {
+		events = calcut.FilterCancelled(events)
+	}
+	events = calcut.FilterPartStat(events, opts.partStat)
+	events = calcut.FilterPeople(events, opts.people)
+	events = calcut.FilterDuration(events, opts.durations)
+	return opts.text.filter(events)
+}
+
+// splitList splits a comma-separated flag value, dropping blanks.
+func splitList(s string) []string {
+	var list []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
 		}
+	}
+	return list
+}
 
-		if stripped != "" {
-			headerLines = append(headerLines, line)
+// firstError returns the first non-nil error. Callers pass the
+// cancellation cause first so that a signal or running out of the -timeout
+// budget is reported as such rather than as whatever operation happened to
+// be interrupted.
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
 		}
 	}
+	return nil
+}
 
-	return ParsedCalendar{
-		HeaderLines: headerLines,
-		Timezones:   timezones,
-		Events:      events,
+// exitOnError reports err and exits, with the exit code of a signal that
+// interrupted the run.
+func exitOnError(err error) {
+	var interrupted *interruptedError
+	if errors.As(err, &interrupted) {
+		fmt.Fprintf(os.Stderr, i18n.T("오류: %s\n"), err)
+		os.Exit(interrupted.exitCode())
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		fmt.Fprintln(os.Stderr, i18n.T("오류: 제한 시간(-timeout)을 초과했습니다"))
+		os.Exit(1)
 	}
+	fmt.Fprintf(os.Stderr, i18n.T("오류: %s\n"), err)
+	os.Exit(1)
 }
 
-func extractProperty(block, propName string) string {
-	for _, line := range strings.Split(block, "\n") {
-		// handles both "PROP:value" and "PROP;PARAM=x:value" (RFC 5545 §3.2)
-		if strings.HasPrefix(line, propName+":") || strings.HasPrefix(line, propName+";") {
-			idx := strings.Index(line, ":")
-			if idx >= 0 {
-				return strings.TrimSpace(line[idx+1:])
-			}
-		}
+// stdinPath is the input path that stands for standard input.
+const stdinPath = "-"
+
+// openInput opens the input file, or standard input for "-".
+func openInput(path string) (io.ReadCloser, error) {
+	if path == stdinPath {
+		return io.NopCloser(os.Stdin), nil
 	}
-	return ""
+	return os.Open(path)
 }
 
-var unsafeChars = regexp.MustCompile(`[<>:"/\\|?*]`)
-var multiUnderscore = regexp.MustCompile(`_+`)
+// readInput reads the input file, refusing files larger than maxBytes
+// (when positive) before loading them into memory.
+func readInput(path string, maxBytes int64) ([]byte, error) {
+	if path == stdinPath {
+		// The size of a pipe is not known up front; read one byte past
+		// the limit to tell.
+		r := io.Reader(os.Stdin)
+		if maxBytes > 0 {
+			r = io.LimitReader(r, maxBytes+1)
+		}
+		data, err := io.ReadAll(r)
+		if err == nil && maxBytes > 0 && int64(len(data)) > maxBytes {
+			return nil, fmt.Errorf(i18n.T("입력이 너무 큽니다 (최대 %s)"), calcut.FormatBytes(maxBytes))
+		}
+		return data, err
+	}
+	if maxBytes > 0 {
+		if _, err := inputSize(path, maxBytes); err != nil {
+			return nil, err
+		}
+	}
+	return os.ReadFile(path)
+}
 
-func sanitizeFilename(name string) string {
-	s := unsafeChars.ReplaceAllString(name, "")
-	s = strings.ReplaceAll(s, " ", "_")
-	s = multiUnderscore.ReplaceAllString(s, "_")
-	s = strings.Trim(s, "_")
-	if s == "" {
-		return "untitled"
+// inputSize returns the size of the input file, failing if it is larger
+// than maxBytes (when positive).
+func inputSize(path string, maxBytes int64) (int64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
 	}
-	return s
+	if maxBytes > 0 && info.Size() > maxBytes {
+		return 0, fmt.Errorf(i18n.T("입력이 너무 큽니다 (%s, 최대 %s)"), calcut.FormatBytes(info.Size()), calcut.FormatBytes(maxBytes))
+	}
+	return info.Size(), nil
 }
 
-func buildICS(headerLines, timezones []string, eventTexts []string) string {
-	var b strings.Builder
-	b.WriteString("BEGIN:VCALENDAR\n")
-	for _, h := range headerLines {
-		b.WriteString(h)
-		b.WriteByte('\n')
+func writeFile(path, content string, mode os.FileMode) error {
+	return os.WriteFile(longPath(path), []byte(content), mode)
+}
+
+// createFile is writeFile for a file that must not exist yet.
+func createFile(path, content string, mode os.FileMode) error {
+	f, err := os.OpenFile(longPath(path), os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+	if errors.Is(err, fs.ErrExist) {
+		return overwriteError(path)
 	}
-	for _, tz := range timezones {
-		b.WriteString(tz)
-		b.WriteByte('\n')
+	if err != nil {
+		return err
 	}
-	for _, ev := range eventTexts {
-		b.WriteString(ev)
-		b.WriteByte('\n')
+	_, err = f.WriteString(content)
+	if cerr := f.Close(); err == nil {
+		err = cerr
 	}
-	b.WriteString("END:VCALENDAR\n")
-	return b.String()
+	return err
 }
 
-func skeletonSize(headerLines, timezones []string) int {
-	s := buildICS(headerLines, timezones, nil)
-	return utf8.RuneCountInString(s)*0 + len(s)
+// writeOutput writes a file of the run: into the -zip archive when there
+// is one, otherwise into the output directory.
+func writeOutput(name, content string, opts splitOptions) error {
+	if opts.archive != nil {
+		return opts.archive.add(name, content)
+	}
+	return writeFile(filepath.Join(opts.outDir, name), content, opts.fileMode)
 }
 
-func parseSize(s string) (int64, error) {
-	s = strings.TrimSpace(strings.ToUpper(s))
-	suffixes := map[string]int64{
-		"GB": 1024 * 1024 * 1024,
-		"MB": 1024 * 1024,
-		"KB": 1024,
-		"G":  1024 * 1024 * 1024,
-		"M":  1024 * 1024,
-		"K":  1024,
-	}
-	for _, suffix := range []string{"GB", "MB", "KB", "G", "M", "K"} {
-		if strings.HasSuffix(s, suffix) {
-			numStr := s[:len(s)-len(suffix)]
-			num, err := strconv.ParseFloat(numStr, 64)
-			if err != nil {
-				return 0, fmt.Errorf("잘못된 크기: %s", s)
-			}
-			return int64(num * float64(suffixes[suffix])), nil
-		}
+// writeNewOutput is writeOutput refusing to replace an existing file in
+// the output directory unless -force is given.
+func writeNewOutput(name, content string, opts splitOptions) error {
+	if opts.cas != nil {
+		return opts.cas.link(filepath.Join(opts.outDir, name), content, opts.force)
+	}
+	if opts.archive == nil && !opts.force {
+		return createFile(filepath.Join(opts.outDir, name), content, opts.fileMode)
 	}
-	return strconv.ParseInt(s, 10, 64)
+	return writeOutput(name, content, opts)
 }
 
-func writeFile(path, content string) error {
-	return os.WriteFile(path, []byte(content), 0644)
+// writeChunk writes one output file and runs the post hook on it.
+func writeChunk(ctx context.Context, name, content string, opts splitOptions) error {
+	if err := writeNewOutput(name, content, opts); err != nil {
+		return err
+	}
+	if opts.postHook != "" {
+		return runHook(ctx, opts.postHook, filepath.Join(opts.outDir, name))
+	}
+	return nil
 }
 
-func splitPerEvent(parsed ParsedCalendar, outDir, prefix string) ([]string, error) {
-	var created []string
-	total := len(parsed.Events)
+// chunkWriter renders and writes chunks one at a time, reporting progress
+// as it goes. A zero total means the number of files is not known up front.
+type chunkWriter struct {
+	ctx     context.Context
+	opts    splitOptions
+	total   int
+	prog    *progress
+	written []manifestFile
+}
 
-	for i, event := range parsed.Events {
-		idx := i + 1
-		summaryPart := "event"
-		if event.Summary != "" {
-			summaryPart = sanitizeFilename(event.Summary)
-		}
+func newChunkWriter(ctx context.Context, total int, opts splitOptions) *chunkWriter {
+	w := &chunkWriter{
+		ctx:   ctx,
+		opts:  opts,
+		total: total,
+		prog:  newProgress(total, opts.printEvery),
+	}
+	w.prog.note = w.queueNote
+	return w
+}
 
-		var filename string
-		if prefix != "" {
-			filename = fmt.Sprintf("%s_%03d_%s.ics", prefix, idx, summaryPart)
-		} else {
-			filename = fmt.Sprintf("%03d_%s.ics", idx, summaryPart)
-		}
+// queuer is implemented by outputs that queue files, such as -upload.
+type queuer interface {
+	queued() (depth, capacity int)
+}
 
-		content := buildICS(parsed.HeaderLines, parsed.Timezones, []string{event.Text})
-		filePath := filepath.Join(outDir, filename)
-		if err := writeFile(filePath, content); err != nil {
-			return nil, err
-		}
-		created = append(created, filePath)
+// queueNote describes how full the output's queue is, if it has one.
+func (w *chunkWriter) queueNote() string {
+	q, ok := w.opts.archive.(queuer)
+	if !ok {
+		return ""
+	}
+	depth, capacity := q.queued()
+	return fmt.Sprintf(i18n.T(", 업로드 대기 %d/%d"), depth, capacity)
+}
 
-		fmt.Printf("  [%d/%d] %s\n", idx, total, filename)
-		if event.Summary != "" {
-			fmt.Printf("        제목: %s\n", event.Summary)
-		}
+func (w *chunkWriter) write(parsed calcut.ParsedCalendar, chunk calcut.Chunk) error {
+	content, err := w.render(parsed, chunk, len(w.written)+1)
+	if err != nil {
+		return err
 	}
-	return created, nil
+	if err := writeChunk(w.ctx, chunk.Filename, content, w.opts); err != nil {
+		return err
+	}
+	w.done(chunk, int64(len(content)))
+	return nil
 }
 
-func splitBySize(parsed ParsedCalendar, outDir, prefix string, maxBytes int64) ([]string, error) {
-	skelSize := int64(skeletonSize(parsed.HeaderLines, parsed.Timezones))
-	var created []string
-	var currentEvents []string
-	currentSize := skelSize
-	chunkIdx := 1
-	tag := prefix
-	if tag == "" {
-		tag = "part"
-	}
-
-	flush := func() error {
-		filename := fmt.Sprintf("%s_%03d.ics", tag, chunkIdx)
-		content := buildICS(parsed.HeaderLines, parsed.Timezones, currentEvents)
-		fileSize := len(content)
-		filePath := filepath.Join(outDir, filename)
-		if err := writeFile(filePath, content); err != nil {
-			return err
+// render builds the idx-th file, for chunk.
+func (w *chunkWriter) render(parsed calcut.ParsedCalendar, chunk calcut.Chunk, idx int) (string, error) {
+	if header := w.opts.calendars.header(chunk); header != nil {
+		parsed.HeaderLines = header
+	}
+	if w.opts.sharedSkeleton {
+		return parsed.BuildFragment(chunk.Events), nil
+	}
+	return buildChunk(parsed, chunk, chunkData{Index: idx, Total: w.total, Filename: chunk.Filename}, w.opts)
+}
+
+// done records and reports a written file of size bytes, as the next in
+// order.
+func (w *chunkWriter) done(chunk calcut.Chunk, size int64) {
+	opts := w.opts
+	w.written = append(w.written, newManifestFile(chunk, size))
+	idx := len(w.written)
+	if chunk.Oversized {
+		label := chunk.Events[0].Title()
+		if len(chunk.Events) > 1 {
+			label = fmt.Sprintf(i18n.T("%s 외 %d개"), label, len(chunk.Events)-1)
 		}
-		created = append(created, filePath)
-		fmt.Printf("  [%d] %s  (%s, %d events)\n", chunkIdx, filename, formatBytes(int64(fileSize)), len(currentEvents))
-		chunkIdx++
-		currentEvents = nil
-		currentSize = skelSize
-		return nil
+		fmt.Printf("  %s\n", term.paint(colorYellow, fmt.Sprintf(i18n.T("%s이벤트 '%s' (%s) 단독으로도 %s 초과"),
+			term.icon("⚠️  ", "[!] "), label, calcut.FormatBytes(chunk.Size), calcut.FormatBytes(opts.maxBytes))))
 	}
 
-	for _, event := range parsed.Events {
-		eventBytes := int64(len(event.Text)) + 1
-		projected := currentSize + eventBytes
+	w.prog.step(idx)
+	if !w.prog.detailed() {
+		return
+	}
+	if !opts.listSummaries {
+		fmt.Printf("  [%d] %s  (%s, %d events%s)\n", idx, chunk.Filename, calcut.FormatBytes(size), len(chunk.Events), w.queueNote())
+		return
+	}
+	if w.total > 0 {
+		fmt.Printf("  [%d/%d] %s\n", idx, w.total, chunk.Filename)
+	} else {
+		fmt.Printf("  [%d] %s\n", idx, chunk.Filename)
+	}
+	if summary := chunk.Events[0].Title(); summary != "" {
+		fmt.Printf(i18n.T("        제목: %s\n"), summary)
+	}
+	if len(chunk.Events) > 1 {
+		fmt.Printf(i18n.T("        연결된 이벤트 %d개 포함\n"), len(chunk.Events)-1)
+	}
+}
 
-		if eventBytes+skelSize > maxBytes {
-			if len(currentEvents) > 0 {
-				if err := flush(); err != nil {
-					return nil, err
+// writeParallel is write for every chunk with jobs workers. Files are
+// numbered by their place in chunks and reported in that order as they
+// complete, so the listing and the manifest are those of a run without
+// -jobs. After stop or a failure no further file is started, and the
+// files being written are finished.
+func (w *chunkWriter) writeParallel(stop context.Context, parsed calcut.ParsedCalendar, chunks []calcut.Chunk, jobs int) ([]manifestFile, error) {
+	type result struct {
+		content string
+		err     error
+		skipped bool
+		ready   chan struct{}
+	}
+	results := make([]result, len(chunks))
+	for i := range results {
+		results[i].ready = make(chan struct{})
+	}
+	quit, cancel := context.WithCancel(stop)
+	defer cancel()
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(jobs, len(chunks)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				r := &results[i]
+				r.content, r.err = w.render(parsed, chunks[i], i+1)
+				if r.err == nil {
+					r.err = writeChunk(w.ctx, chunks[i].Filename, r.content, w.opts)
 				}
+				close(r.ready)
 			}
-			fmt.Printf("  ⚠️  이벤트 '%s' (%s) 단독으로도 %s 초과\n",
-				event.Summary, formatBytes(eventBytes+skelSize), formatBytes(maxBytes))
-			currentEvents = []string{event.Text}
-			if err := flush(); err != nil {
-				return nil, err
+		}()
+	}
+	go func() {
+		defer close(next)
+		for i := range chunks {
+			select {
+			case next <- i:
+				continue
+			case <-quit.Done():
 			}
-			continue
+			for j := i; j < len(chunks); j++ {
+				results[j].skipped = true
+				close(results[j].ready)
+			}
+			return
 		}
+	}()
+
+	var err error
+	for i := range chunks {
+		r := &results[i]
+		<-r.ready
+		switch {
+		case r.skipped || err != nil:
+		case r.err != nil:
+			err = r.err
+			cancel()
+		default:
+			w.done(chunks[i], int64(len(r.content)))
+		}
+	}
+	wg.Wait()
+	if err != nil {
+		return nil, err
+	}
+	if stop.Err() != nil {
+		return w.written, context.Cause(stop)
+	}
+	return w.written, nil
+}
 
-		if projected > maxBytes && len(currentEvents) > 0 {
-			if err := flush(); err != nil {
+// writeChunks renders and writes planned chunks. All paths are validated
+// before the first file is written, and writing stops between files once
+// stop is done.
+//
+// stop is checked between files only, so an interrupted run never leaves a
+// half-written file behind; ctx bounds the hooks and is normally the
+// parent of stop.
+func writeChunks(ctx, stop context.Context, parsed calcut.ParsedCalendar, chunks []calcut.Chunk, opts splitOptions) ([]manifestFile, error) {
+	filenames := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		filenames[i] = chunk.Filename
+	}
+	if opts.sharedSkeleton {
+		filenames = append(filenames, skeletonName)
+	}
+	if opts.archive == nil {
+		if err := validateOutputPaths(opts.outDir, filenames); err != nil {
+			return nil, err
+		}
+		if !opts.force {
+			if err := checkNoOverwrite(opts.outDir, filenames); err != nil {
 				return nil, err
 			}
 		}
-
-		currentEvents = append(currentEvents, event.Text)
-		currentSize += eventBytes
 	}
 
-	if len(currentEvents) > 0 {
-		if err := flush(); err != nil {
+	if opts.sharedSkeleton {
+		if err := writeNewOutput(skeletonName, parsed.Build(nil), opts); err != nil {
+			return nil, err
+		}
+	}
+	w := newChunkWriter(ctx, len(chunks), opts)
+	if jobs := opts.jobs; jobs != 1 {
+		if jobs == 0 {
+			jobs = runtime.NumCPU()
+		}
+		return w.writeParallel(stop, parsed, chunks, jobs)
+	}
+	for _, chunk := range chunks {
+		if stop.Err() != nil {
+			return w.written, context.Cause(stop)
+		}
+		if err := w.write(parsed, chunk); err != nil {
 			return nil, err
 		}
 	}
+	return w.written, nil
+}
 
-	return created, nil
+func init() {
+	// Registered here as split's usage lists the subcommands.
+	subcommands["split"] = splitCalendars
 }
 
-func formatBytes(b int64) string {
-	switch {
-	case b >= 1024*1024:
-		return fmt.Sprintf("%.1f MB", float64(b)/(1024*1024))
-	case b >= 1024:
-		return fmt.Sprintf("%.1f KB", float64(b)/1024)
-	default:
-		return fmt.Sprintf("%d bytes", b)
-	}
+// subcommands run instead of a split when named as the first argument.
+// Those that need the network register themselves from files left out of
+// builds with the lite tag.
+var subcommands = map[string]func(args []string) error{
+	"assemble":     assembleCalendars,
+	"atom":         writeAtomFeed,
+	"compare-runs": compareRuns,
+	"decrypt":      decryptCalendar,
+	"diff":         diffCalendars,
+	"expand":       expandCalendar,
+	"gen":          generateCalendar,
+	"html":         renderHTML,
+	"index":        buildSearchIndex,
+	"info":         showInfo,
+	"links":        listLinks,
+	"merge":        mergeCalendars,
+	"repro":        anonymizeCalendar,
+	"today":        showToday,
+	"validate":     validateCalendars,
+	"verify":       verifyChecksums,
+	"week":         showWeek,
 }
 
 func main() {
-	outputDir := flag.String("output-dir", "./split_output", "출력 디렉토리")
-	prefix := flag.String("prefix", "", "출력 파일명 접두사")
-	maxSize := flag.String("max-size", "", "파일당 최대 크기 (예: 1M, 512K, 2MB)")
-	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "사용법: split-ical [옵션] <입력파일.ics>\n\n옵션:\n")
-		flag.PrintDefaults()
-		fmt.Fprintf(os.Stderr, "\n예시:\n")
-		fmt.Fprintf(os.Stderr, "  split-ical calendar.ics\n")
-		fmt.Fprintf(os.Stderr, "  split-ical -max-size 1M -output-dir ./결과 calendar.ics\n")
-	}
-	flag.Parse()
-
-	if flag.NArg() < 1 {
-		flag.Usage()
-		os.Exit(1)
+	args, err := setupLocale(os.Args[1:])
+	if err == nil {
+		args, err = parseGlobals(args)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, i18n.T("오류: %s\n"), err)
+		os.Exit(2)
 	}
-	inputPath := flag.Arg(0)
+	run := splitCalendars
+	if len(args) > 0 {
+		if cmd, ok := subcommands[args[0]]; ok {
+			run, args = cmd, args[1:]
+		}
+	}
+	// Without a subcommand the arguments are split's: "split-ical
+	// calendar.ics" is short for "split-ical split calendar.ics".
+	if err := run(args); err != nil {
+		exitOnError(err)
+	}
+}
 
-	data, err := os.ReadFile(inputPath)
+// splitCalendars implements "split", the default command, which splits
+// one or more calendars into files.
+func splitCalendars(args []string) error {
+	f, inputPaths, err := parseSplitFlags(args)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "오류: 파일을 읽을 수 없습니다 - %s\n", err)
-		os.Exit(1)
+		return err
 	}
+	globals.quiet = f.quiet
+	term = detectConsole(f.noEmoji, f.noColor)
 
-	parsed := parseIcal(string(data))
-	if len(parsed.Events) == 0 {
-		fmt.Fprintln(os.Stderr, "경고: 이벤트가 없습니다.")
-		os.Exit(0)
+	ctx := context.Background()
+	if f.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, f.timeout)
+		defer cancel()
 	}
+	stop, stopSignals := stopOnSignal(ctx)
+	defer stopSignals()
 
-	if err := os.MkdirAll(*outputDir, 0755); err != nil {
-		fmt.Fprintf(os.Stderr, "오류: 디렉토리 생성 실패 - %s\n", err)
-		os.Exit(1)
+	inputPath := inputPaths[0]
+
+	limits := calcut.ParseLimits{MaxComponents: f.maxComponents, Strict: f.strict}
+	if f.maxInputSize != "" {
+		limits.MaxBytes, err = calcut.ParseSize(f.maxInputSize)
+		if err != nil {
+			return err
+		}
 	}
 
-	var maxBytes int64
-	if *maxSize != "" {
-		maxBytes, err = parseSize(*maxSize)
+	var groupProp string
+	if f.groupBy != "" {
+		if groupProp, err = parseGroupBy(f.groupBy); err != nil {
+			return err
+		}
+	}
+	var center calcut.Geo
+	var radiusKm float64
+	if f.by == proximityMode {
+		if center, err = calcut.ParseGeo(f.near); err == nil {
+			radiusKm, err = parseRadius(f.radius)
+		}
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "오류: %s\n", err)
-			os.Exit(1)
+			return err
 		}
 	}
+	loc, err := loadLocation(f.tz)
+	if err != nil {
+		return err
+	}
 
-	fmt.Printf("\n📅 iCalendar 분할 시작\n")
-	fmt.Printf("   입력: %s (%s, %d events)\n", inputPath, formatBytes(int64(len(data))), len(parsed.Events))
-	fmt.Printf("   출력: %s\n", *outputDir)
-	if maxBytes > 0 {
-		fmt.Printf("   최대 크기: %s (%s)\n", formatBytes(maxBytes), *maxSize)
-	} else {
-		fmt.Printf("   모드: 이벤트당 1파일\n")
+	opts := splitOptions{
+		outDir:         f.outputDir,
+		prefix:         f.prefix,
+		contiguous:     !f.noContiguous,
+		maxEvents:      f.maxEvents,
+		allTimezones:   f.allTimezones,
+		checksums:      f.checksums,
+		timezonesFirst: f.timezonesFirst,
+		verbose:        f.verbose,
+		parseWarnings:  new([]manifestWarning),
+		printEvery:     f.printEvery,
+		postHook:       f.postHook,
+		force:          f.force,
+		sharedSkeleton: f.sharedSkeleton,
+		jobs:           f.jobs,
+		source:         filepath.Base(inputPath),
+		now:            time.Now(),
+	}
+	if inputPath == stdinPath {
+		opts.source = "stdin"
+	}
+	for _, path := range inputPaths[1:] {
+		opts.source += "," + filepath.Base(path)
+	}
+	opts.dropCancelled = f.dropCancelled
+	if f.onlyAccepted || f.dropDeclined {
+		opts.partStat = calcut.PartStatFilter{Address: f.me, OnlyAccepted: f.onlyAccepted, DropDeclined: f.dropDeclined}
+	}
+	opts.people = calcut.PeopleFilter{Attendees: splitList(f.attendee), Organizers: splitList(f.organizer)}
+	// Standard output carries data from here on, so progress goes to
+	// standard error instead.
+	stdout := os.Stdout
+	if f.stdoutFormat != "" || f.stdoutManifest {
+		os.Stdout = os.Stderr
+	}
+	if globals.quiet {
+		if os.Stdout, err = os.OpenFile(os.DevNull, os.O_WRONLY, 0); err != nil {
+			os.Stdout = os.Stderr
+		}
+	}
+	if f.stdoutManifest {
+		opts.manifestOut = stdout
+	}
+	switch f.lineEnding {
+	case "source":
+	case "crlf":
+		opts.lineEnding = "\r\n"
+	case "lf":
+		opts.lineEnding = "\n"
+	default:
+		return fmt.Errorf(i18n.T("잘못된 -line-ending: %s (source, crlf, lf 중 하나)"), f.lineEnding)
+	}
+	if opts.kinds, err = calcut.ParseComponentKinds(f.components); err != nil {
+		return err
+	}
+	now := time.Now()
+	if f.nowFlag != "" {
+		if now, _, err = calcut.ParseDatePeriod(f.nowFlag, now, loc); err != nil {
+			return err
+		}
+	}
+	if f.last != "" || f.next != "" {
+		var before, after calcut.Span
+		for _, s := range []struct {
+			value string
+			span  *calcut.Span
+		}{{f.last, &before}, {f.next, &after}} {
+			if s.value == "" {
+				continue
+			}
+			if *s.span, err = calcut.ParseSpan(s.value); err != nil {
+				return err
+			}
+		}
+		opts.window = calcut.RelativeDateWindow(now, before, after, loc)
+	} else if opts.window, err = parseWindow(f.from, f.to, f.strictDates, now, loc); err != nil {
+		return err
+	}
+	opts.strictNames = f.strictNames
+	opts.langs = splitList(f.preferLang)
+	if f.nameTemplate != "" {
+		if opts.names, err = calcut.ParseNameTemplate(f.nameTemplate); err != nil {
+			return err
+		}
+	}
+	if f.within != "" {
+		if opts.hours, err = calcut.ParseHoursWindow(f.within, loc); err != nil {
+			return err
+		}
+	}
+	if opts.text, err = newTextFilter(f.match, f.exclude, f.matchDescription); err != nil {
+		return err
+	}
+	opts.durations.Loc = loc
+	for _, d := range []struct {
+		value string
+		bound *time.Duration
+	}{{f.minDuration, &opts.durations.Min}, {f.maxDuration, &opts.durations.Max}} {
+		if d.value == "" {
+			continue
+		}
+		if *d.bound, err = calcut.ParseEventDuration(d.value); err != nil {
+			return err
+		}
+	}
+	if opts.durations.Max > 0 && opts.durations.Min > opts.durations.Max {
+		return errors.New(i18n.T("-min-duration이 -max-duration보다 깁니다"))
+	}
+	if opts.colors, err = calcut.ParseColors(f.colors); err != nil {
+		return err
+	}
+	if opts.calendarProps, err = parseStampProps(f.calendarSpecs); err != nil {
+		return err
+	}
+	if opts.fileMode, err = parseFileMode(f.fileMode); err != nil {
+		return err
+	}
+	dirPerm, err := parseFileMode(f.dirMode)
+	if err != nil {
+		return err
+	}
+
+	rw := &rewriter{recode: f.recode, source: opts.source, now: opts.now}
+	if f.shift != "" {
+		if rw.shift, err = calcut.ParseShift(f.shift); err != nil {
+			return err
+		}
+	}
+	if f.transformScript != "" {
+		if rw.script, err = loadTransformScript(f.transformScript); err != nil {
+			return err
+		}
+	}
+	if rw.stamps, err = parseStampProps(f.stampSpecs); err != nil {
+		return err
+	}
+	if f.target != "" {
+		if rw.target, err = newConferenceInliner(f.target); err != nil {
+			return err
+		}
+		rw.alarms = targetAlarms[f.target]
+		rw.alarms.Loc = loc
+	}
+	if f.redactProfile != "" {
+		if rw.redact, err = newRedactor(f.redactProfile); err != nil {
+			return err
+		}
+	}
+	if f.encryptFields != "" {
+		if rw.encrypt, err = newFieldEncryptor(f.encryptFields, f.encryptKeyFile); err != nil {
+			return err
+		}
+	}
+	if f.auditPath != "" || rw.redact != nil {
+		rw.audit = newAuditLog(rw.source, rw.now)
+	}
+	if f.uidMapPath != "" {
+		rw.uids = newUIDMap()
+	}
+
+	var parsed calcut.ParsedCalendar
+	var size int64
+	duplicates := 0
+	calendarCount := 0
+	if f.stream && inputPath != stdinPath {
+		size, err = inputSize(inputPath, limits.MaxBytes)
+		if err != nil {
+			return fmt.Errorf(i18n.T("파일을 읽을 수 없습니다 - %w"), err)
+		}
+	} else if !f.stream {
+		// Several inputs are merged first, so -dedupe sees every copy.
+		cals := make([]calcut.ParsedCalendar, len(inputPaths))
+		var all []calcut.ParsedCalendar
+		for i, path := range inputPaths {
+			data, err := readInput(path, limits.MaxBytes)
+			if err != nil {
+				return fmt.Errorf(i18n.T("파일을 읽을 수 없습니다 - %w"), err)
+			}
+			size += int64(len(data))
+			found, err := calcut.ParseCalendarsContext(stop, data, limits)
+			if err != nil {
+				if len(inputPaths) > 1 {
+					err = fmt.Errorf("%s: %w", path, err)
+				}
+				return firstError(context.Cause(stop), err)
+			}
+			cals[i] = calcut.JoinCalendars(found)
+			all = append(all, found...)
+			noteParseWarnings(path, cals[i].Warnings, opts)
+		}
+		calendarCount = len(all)
+		if f.calendarMode == "split" {
+			opts.calendars = newCalendarSplit(all)
+		}
+		parsed = cals[0]
+		if len(cals) > 1 {
+			parsed = calcut.Merge(cals, calcut.MergeOptions{})
+		}
+		parsed.LineEnding = cmp.Or(opts.lineEnding, parsed.LineEnding)
+		parsed.TimezonesFirst = opts.timezonesFirst
+		if f.dedupe {
+			total := len(parsed.Events)
+			parsed.Events = calcut.Dedupe(parsed.Events)
+			duplicates = total - len(parsed.Events)
+		}
+		parsed.Events, err = rw.rewriteAll(stop, selectEvents(parsed.Events, opts))
+		if err == nil && f.expand {
+			parsed.Events, err = calcut.Expand(parsed.Events, calcut.ExpandOptions{Window: opts.window})
+		} else if err == nil {
+			parsed.Events = calcut.FilterWindow(parsed.Events, opts.window)
+		}
+		parsed.Events = calcut.FilterHours(parsed.Events, opts.hours)
+		if opts.checksums {
+			// Last, so the checksums cover the events as written.
+			parsed.Events = calcut.StampChecksums(parsed.Events)
+		}
+		parsed.Events = calcut.PreferLanguages(parsed.Events, opts.langs)
+		if err != nil {
+			return firstError(context.Cause(stop), err)
+		}
+		if len(parsed.Events) == 0 {
+			fmt.Fprintln(os.Stderr, i18n.T("경고: 이벤트가 없습니다."))
+			writeAuditLog(rw.audit, f.auditPath, opts)
+			writeUIDMap(rw.uids, f.uidMapPath, opts.fileMode)
+			noteParseSummary(opts)
+			if len(*opts.parseWarnings) > 0 && !f.estimate && (opts.manifestOut != nil || f.zipPath == "" && f.stdoutFormat == "" && f.upload == "" && !f.runDir) {
+				// Nothing is written, but the manifest still tells tools
+				// that the input was damaged.
+				var err error
+				if opts.manifestOut == nil {
+					err = os.MkdirAll(longPath(f.outputDir), dirPerm)
+				}
+				if err == nil {
+					err = writeManifest(manifest{Warnings: *opts.parseWarnings}, opts)
+				}
+				if err != nil {
+					fmt.Fprintf(os.Stderr, i18n.T("경고: %s 기록 실패 - %s\n"), manifestName, err)
+				}
+			}
+			return nil
+		}
+		if f.sortEvents {
+			parsed.Events = calcut.SortByStart(parsed.Events, loc)
+		}
+	}
+
+	if f.zipPath == "" && f.stdoutFormat == "" && f.upload == "" && !f.estimate {
+		if err := os.MkdirAll(longPath(f.outputDir), dirPerm); err != nil {
+			return fmt.Errorf(i18n.T("디렉토리 생성 실패 - %w"), err)
+		}
+	}
+	cleaned := 0
+	if f.clean && !f.estimate {
+		if cleaned, err = cleanPrevious(f.outputDir); err != nil {
+			return fmt.Errorf(i18n.T("이전 결과 삭제 실패 - %w"), err)
+		}
+	}
+	if f.casOutput != "" {
+		opts.cas = &casStore{dir: f.casOutput, mode: opts.fileMode, dirMode: dirPerm}
+	}
+	if f.runDir && !f.estimate {
+		opts.outDir, err = newRunDir(f.outputDir, time.Now(), dirPerm)
+		if err != nil {
+			return fmt.Errorf(i18n.T("디렉토리 생성 실패 - %w"), err)
+		}
+	}
+
+	if f.maxSize != "" {
+		opts.maxBytes, err = calcut.ParseSize(f.maxSize)
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf(i18n.T("\n%siCalendar 분할 시작\n"), term.icon("📅 ", ""))
+	input := inputPath
+	if inputPath == stdinPath {
+		input = opts.source
+	} else if len(inputPaths) > 1 {
+		input = fmt.Sprintf(i18n.T("%s 외 %d개"), inputPath, len(inputPaths)-1)
+	}
+	switch {
+	case f.stream && inputPath == stdinPath:
+		fmt.Printf(i18n.T("   입력: %s (스트리밍)\n"), input)
+	case f.stream:
+		fmt.Printf(i18n.T("   입력: %s (%s, 스트리밍)\n"), input, calcut.FormatBytes(size))
+	default:
+		fmt.Printf(i18n.T("   입력: %s (%s, %d events)\n"), input, calcut.FormatBytes(size), len(parsed.Events))
+	}
+	output := opts.outDir
+	if f.zipPath != "" {
+		output = f.zipPath
+	} else if f.stdoutFormat != "" {
+		output = "stdout (" + f.stdoutFormat + ")"
+	} else if f.upload != "" {
+		output = f.upload
+		if u, err := url.Parse(f.upload); err == nil {
+			output = u.Redacted()
+		}
+	}
+	fmt.Printf(i18n.T("   출력: %s\n"), output)
+	if f.clean {
+		fmt.Printf(i18n.T("   정리: 이전 결과 %d개 파일 삭제\n"), cleaned)
+	}
+	if opts.cas != nil {
+		fmt.Printf(i18n.T("   저장소: %s\n"), opts.cas.dir)
+	}
+	if len(opts.kinds) > 0 {
+		fmt.Printf(i18n.T("   컴포넌트: %s\n"), strings.Join(opts.kinds, ", "))
+	}
+	if f.last != "" || f.next != "" {
+		fmt.Printf(i18n.T("   기간: %s ~ %s (끝 시각 제외)\n"), opts.window.From.Format("2006-01-02 15:04"), opts.window.To.Format("2006-01-02 15:04"))
+	} else if !opts.window.IsZero() {
+		fmt.Printf(i18n.T("   기간: %s ~ %s\n"), f.from, f.to)
+	}
+	if !opts.hours.IsZero() {
+		fmt.Printf(i18n.T("   시간: %s (%s)\n"), f.within, loc)
+	}
+	if f.dedupe {
+		fmt.Printf(i18n.T("   중복: %d개 제외 (UID별 최신 판만 남김)\n"), duplicates)
+	}
+	if f.dropCancelled {
+		fmt.Println(i18n.T("   취소: 취소된 이벤트 제외"))
+	}
+	if f.onlyAccepted {
+		fmt.Printf(i18n.T("   참석: %s가 수락한 회의만\n"), f.me)
+	} else if f.dropDeclined {
+		fmt.Printf(i18n.T("   참석: %s가 거절한 회의 제외\n"), f.me)
+	}
+	if f.match != "" {
+		fmt.Printf(i18n.T("   일치: /%s/\n"), f.match)
+	}
+	if f.exclude != "" {
+		fmt.Printf(i18n.T("   제외: /%s/\n"), f.exclude)
+	}
+	switch {
+	case f.minDuration != "" && f.maxDuration != "":
+		fmt.Printf(i18n.T("   길이: %s ~ %s\n"), f.minDuration, f.maxDuration)
+	case f.minDuration != "":
+		fmt.Printf(i18n.T("   길이: %s 이상\n"), f.minDuration)
+	case f.maxDuration != "":
+		fmt.Printf(i18n.T("   길이: %s 이하\n"), f.maxDuration)
+	}
+	if f.attendee != "" {
+		fmt.Printf(i18n.T("   참석자: %s\n"), f.attendee)
+	}
+	if f.organizer != "" {
+		fmt.Printf(i18n.T("   주최자: %s\n"), f.organizer)
+	}
+	if len(opts.colors) > 0 {
+		fmt.Printf(i18n.T("   색: %d가지 차례로\n"), len(opts.colors))
+	}
+	if calendarCount > 1 {
+		fmt.Print(calendarsNote(calendarCount, f.calendarMode))
+	}
+	if f.strategyExec != "" {
+		fmt.Printf(i18n.T("   전략: %s\n"), f.strategyExec)
+	} else if f.groupBy != "" {
+		fmt.Printf(i18n.T("   그룹: %s 값별\n"), groupProp)
+	} else if f.by == proximityMode {
+		fmt.Printf(i18n.T("   위치별: %s 반경 %s 안/밖\n"), f.near, f.radius)
+	} else if f.by != "" {
+		fmt.Printf(i18n.T("   기간별: %s (%s)\n"), f.by, loc)
+	} else if f.parts > 0 {
+		fmt.Printf(i18n.T("   모드: %d개 파일로 고르게 (%s 기준)\n"), f.parts, f.partsBy)
+	} else if opts.maxBytes == 0 && opts.maxEvents == 0 && opts.calendars == nil {
+		fmt.Print(i18n.T("   모드: 이벤트당 1파일\n"))
+	}
+	// With a strategy above the limits apply within each of its buckets.
+	if opts.maxBytes > 0 {
+		fmt.Printf(i18n.T("   최대 크기: %s (%s)\n"), calcut.FormatBytes(opts.maxBytes), f.maxSize)
+	}
+	if opts.maxEvents > 0 {
+		fmt.Printf(i18n.T("   최대 이벤트: 파일당 %d개\n"), opts.maxEvents)
 	}
 	fmt.Println()
 
-	var files []string
-	if maxBytes > 0 {
-		files, err = splitBySize(parsed, *outputDir, *prefix, maxBytes)
+	switch {
+	case f.estimate:
+	case f.zipPath != "":
+		if opts.archive, err = createZipArchive(f.zipPath, opts.fileMode, opts.now); err != nil {
+			return fmt.Errorf(i18n.T("zip 파일 생성 실패 - %w"), err)
+		}
+	case f.stdoutFormat != "":
+		if opts.archive, err = newStdoutArchive(f.stdoutFormat, stdout, opts.fileMode, opts.now); err != nil {
+			return err
+		}
+	case f.upload != "":
+		if opts.archive, err = newUploadArchive(ctx, f.upload, f.uploadWorkers, f.uploadQueue, f.uploadRetries); err != nil {
+			return err
+		}
+	}
+
+	if f.preHook != "" && !f.estimate {
+		if err := runHook(ctx, f.preHook, output); err != nil {
+			return firstError(context.Cause(stop), err)
+		}
+	}
+
+	var chunks []calcut.Chunk
+	var files []manifestFile
+	if f.stream {
+		opts.listSummaries = opts.maxBytes == 0 && opts.maxEvents == 0
+		files, err = splitStream(ctx, stop, inputPath, limits, rw, opts)
 	} else {
-		files, err = splitPerEvent(parsed, *outputDir, *prefix)
+		splitOpts := calcut.SplitOptions{
+			Prefix:           opts.prefix,
+			MaxBytes:         opts.maxBytes,
+			MaxEvents:        opts.maxEvents,
+			Contiguous:       opts.contiguous,
+			Pack:             f.pack,
+			Related:          !f.noRelated,
+			Reserve:          calendarPropsReserve(len(parsed.Events), opts),
+			KeepAllTimezones: opts.allTimezones,
+		}
+		groups := calcut.GroupEvents(parsed.Events, splitOpts.Related)
+
+		switch {
+		case opts.calendars != nil:
+			chunks = opts.calendars.plan(parsed, splitOpts)
+		case f.strategyExec != "":
+			var buckets []string
+			buckets, err = bucketsFromExec(stop, f.strategyExec, groups)
+			if err == nil {
+				chunks = calcut.PlanByKey(parsed, groups, buckets, splitOpts)
+			}
+		case f.groupBy != "":
+			byValue, keys := groupByKeys(groups, groupProp, f.firstCategory)
+			chunks = calcut.PlanByKey(parsed, byValue, keys, splitOpts)
+			calcut.SortChunksByKey(chunks, missingBucket(groupProp))
+		case f.by == proximityMode:
+			chunks = calcut.PlanByKey(parsed, groups, proximityKeys(groups, center, radiusKm), splitOpts)
+			sortProximityChunks(chunks)
+		case f.by != "":
+			chunks, err = calcut.PlanByPeriod(parsed, groups, f.by, loc, splitOpts)
+		case f.parts > 0:
+			chunks = calcut.PlanParts(parsed, groups, f.parts, f.partsBy == "events", splitOpts)
+			if len(chunks) < f.parts {
+				fmt.Fprintf(os.Stderr, i18n.T("경고: 나눌 수 있는 이벤트 묶음이 %d개뿐이라 파일도 그만큼만 만듭니다\n"), len(chunks))
+			}
+		case opts.maxBytes > 0 || opts.maxEvents > 0:
+			chunks = calcut.PlanBySize(parsed, groups, splitOpts)
+		default:
+			opts.listSummaries = true
+			chunks = calcut.PlanPerEvent(parsed, groups, splitOpts)
+		}
+
+		if err == nil {
+			opts.names.Rename(chunks, opts.prefix)
+			if opts.sharedSkeleton {
+				for i := range chunks {
+					chunks[i].Filename = fragmentName(chunks[i].Filename)
+				}
+			}
+			err = calcut.UniqueFilenames(chunks, opts.strictNames)
+		}
+		if err == nil && f.estimate {
+			printEstimate(stdout, chunks, f.target)
+			return nil
+		}
+		if err == nil {
+			files, err = writeChunks(ctx, stop, parsed, chunks, opts)
+		}
 	}
 
+	writeAuditLog(rw.audit, f.auditPath, opts)
+	writeUIDMap(rw.uids, f.uidMapPath, opts.fileMode)
+	noteParseSummary(opts)
+
+	m := manifest{Planned: len(chunks), Files: files, Warnings: *opts.parseWarnings}
+	if opts.sharedSkeleton {
+		m.Skeleton = skeletonName
+	}
+	var interrupted *interruptedError
+	if errors.As(err, &interrupted) {
+		m.Partial = true
+		m.Interrupted = interrupted.sig.String()
+		if merr := writeManifest(m, opts); merr != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("경고: %s 기록 실패 - %s\n"), manifestName, merr)
+		}
+		if f.stream {
+			fmt.Fprintf(os.Stderr, i18n.T("중단됨: %d개 파일 생성 (%s에 partial로 기록)\n"), len(files), manifestName)
+		} else {
+			fmt.Fprintf(os.Stderr, i18n.T("중단됨: %d/%d개 파일 생성 (%s에 partial로 기록)\n"), len(files), len(chunks), manifestName)
+		}
+	}
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "오류: %s\n", err)
-		os.Exit(1)
+		if opts.archive != nil {
+			if interrupted != nil {
+				opts.archive.close()
+			} else {
+				opts.archive.discard()
+			}
+		}
+		return firstError(context.Cause(stop), err)
+	}
+	if merr := writeManifest(m, opts); merr != nil {
+		fmt.Fprintf(os.Stderr, i18n.T("경고: %s 기록 실패 - %s\n"), manifestName, merr)
+	}
+	if opts.archive != nil {
+		if err := opts.archive.close(); err != nil {
+			return fmt.Errorf(i18n.T("출력 기록 실패 - %w"), err)
+		}
+	}
+
+	if f.runDir {
+		if err := updateLatestLink(f.outputDir, opts.outDir); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("경고: latest 링크 갱신 실패 - %s\n"), err)
+		}
 	}
 
-	fmt.Printf("\n✅ 완료: %d개 파일 생성됨 → %s/\n\n", len(files), *outputDir)
+	if opts.archive == nil {
+		output += "/"
+	}
+	done := fmt.Sprintf(i18n.T("%s완료: %d개 파일 생성됨 %s %s"), term.icon("✅ ", ""), len(files), term.icon("→", "->"), output)
+	fmt.Printf("\n%s\n", term.paint(colorGreen, done))
+	if opts.cas != nil {
+		fmt.Printf(i18n.T("저장소: 새로 저장 %d개, 이전 내용 재사용 %d개\n"), opts.cas.added, opts.cas.reused)
+	}
+	fmt.Println()
+	return nil
 }
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+	"github.com/sedurm85/calcut/pkg/calcut"
+)
+
+const manifestName = "index.json"
+
+// skeletonName is the file -shared-skeleton writes the calendar header
+// and timezones to. The output files are then fragments holding only
+// events, named with fragmentExt.
+const (
+	skeletonName = "skeleton.ics"
+	fragmentExt  = ".frag"
+)
+
+// fragmentName is the name of the fragment standing for the .ics file
+// name.
+func fragmentName(name string) string {
+	return strings.TrimSuffix(name, filepath.Ext(name)) + fragmentExt
+}
+
+// manifest describes the files a run produced. Partial is set when the run
+// was interrupted before all planned files were written; Planned is zero
+// when the number was not known in advance (-stream). Skeleton names the
+// calendar the files are fragments of, with -shared-skeleton: each file
+// is restored by inserting it before the END:VCALENDAR of the skeleton.
+type manifest struct {
+	Partial     bool           `json:"partial"`
+	Interrupted string         `json:"interrupted,omitempty"`
+	Planned     int            `json:"planned,omitempty"`
+	Skeleton    string         `json:"skeleton,omitempty"`
+	Files       []manifestFile `json:"files"`
+	// Warnings are the problems of the input the parser recovered from
+	// (see -strict).
+	Warnings []manifestWarning `json:"warnings,omitempty"`
+}
+
+// manifestFile describes one output file: its size in bytes, number of
+// events, the dates (YYYY-MM-DD) their DTSTARTs span, and their UIDs.
+type manifestFile struct {
+	Name   string   `json:"name"`
+	Size   int64    `json:"size"`
+	Events int      `json:"events"`
+	From   string   `json:"from,omitempty"`
+	To     string   `json:"to,omitempty"`
+	UIDs   []string `json:"uids"`
+}
+
+// manifestWarning is a problem lenient parsing recovered from in an input
+// file; Line is 0 when it concerns the file as a whole.
+type manifestWarning struct {
+	File    string `json:"file"`
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+// noteParseWarnings adds the parse warnings of file to those of the run
+// and, with -verbose, prints them.
+func noteParseWarnings(file string, warnings []*calcut.ParseError, opts splitOptions) {
+	for _, w := range warnings {
+		*opts.parseWarnings = append(*opts.parseWarnings, manifestWarning{File: file, Line: w.Line, Message: w.Msg})
+		if opts.verbose {
+			fmt.Fprintf(os.Stderr, i18n.T("경고: %s: %s\n"), file, w)
+		}
+	}
+}
+
+// noteParseSummary prints how many structural problems of the input were
+// recovered from, unless -verbose has already printed them one by one.
+func noteParseSummary(opts splitOptions) {
+	if n := len(*opts.parseWarnings); n > 0 && !opts.verbose {
+		fmt.Fprintf(os.Stderr, i18n.T("경고: 입력 구조의 문제 %d개를 고쳐 읽었습니다 (하나하나 보려면 -verbose, 오류로 끝내려면 -strict)\n"), n)
+	}
+}
+
+func newManifestFile(chunk calcut.Chunk, size int64) manifestFile {
+	f := manifestFile{
+		Name:   chunk.Filename,
+		Size:   size,
+		Events: len(chunk.Events),
+		UIDs:   chunkUIDs(chunk.Events),
+	}
+	f.From, f.To = calcut.DateRange(chunk.Events)
+	return f
+}
+
+func writeManifest(m manifest, opts splitOptions) error {
+	if m.Files == nil {
+		m.Files = []manifestFile{}
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if opts.manifestOut != nil {
+		_, err = fmt.Fprintf(opts.manifestOut, "%s\n", data)
+		return err
+	}
+	return writeOutput(manifestName, string(data)+"\n", opts)
+}
+
+// readManifest reads a manifest file, or the manifest of an output
+// directory.
+func readManifest(path string) (manifest, error) {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, manifestName)
+	}
+	var m manifest
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return m, err
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return m, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// cleanPrevious removes the files listed in the manifest of outDir, the
+// output of an earlier run, and returns how many it removed. Without a
+// manifest there is nothing to remove. Names that would leave outDir are
+// skipped.
+func cleanPrevious(outDir string) (int, error) {
+	m, err := readManifest(outDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	names := make([]string, 0, len(m.Files)+1)
+	for _, f := range m.Files {
+		names = append(names, f.Name)
+	}
+	if m.Skeleton != "" {
+		names = append(names, m.Skeleton)
+	}
+	removed := 0
+	for _, name := range names {
+		if !filepath.IsLocal(name) {
+			continue
+		}
+		err := os.Remove(longPath(filepath.Join(outDir, name)))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// chunkUIDs lists the distinct UIDs of events in order; a series and its
+// overrides share one.
+func chunkUIDs(events []calcut.Event) []string {
+	uids := []string{}
+	seen := make(map[string]bool)
+	for _, event := range events {
+		if event.UID != "" && !seen[event.UID] {
+			seen[event.UID] = true
+			uids = append(uids, event.UID)
+		}
+	}
+	return uids
+}
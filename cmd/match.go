@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+	"github.com/sedurm85/calcut/pkg/calcut"
+)
+
+// textFilter selects events by their text with -match and -exclude. The
+// patterns see the unescaped values of props, so "\," in the file is
+// matched by a plain comma.
+type textFilter struct {
+	match, exclude *regexp.Regexp
+	props          []string
+}
+
+// newTextFilter compiles the -match and -exclude patterns, either of which
+// may be empty. SUMMARY is searched, and DESCRIPTION as well when
+// description is set.
+func newTextFilter(match, exclude string, description bool) (textFilter, error) {
+	f := textFilter{props: []string{"SUMMARY"}}
+	if description {
+		f.props = append(f.props, "DESCRIPTION")
+	}
+	var err error
+	if match != "" {
+		if f.match, err = regexp.Compile(match); err != nil {
+			return f, fmt.Errorf(i18n.T("잘못된 -match 정규식: %w"), err)
+		}
+	}
+	if exclude != "" {
+		if f.exclude, err = regexp.Compile(exclude); err != nil {
+			return f, fmt.Errorf(i18n.T("잘못된 -exclude 정규식: %w"), err)
+		}
+	}
+	return f, nil
+}
+
+// keep reports whether event passes: some searched property matches
+// -match, and none matches -exclude.
+func (f textFilter) keep(event calcut.Event) bool {
+	if f.match == nil && f.exclude == nil {
+		return true
+	}
+	matched := f.match == nil
+	for _, p := range calcut.TopLevelProperties(event.Text) {
+		if !slices.Contains(f.props, p.Name) {
+			continue
+		}
+		value := calcut.UnescapeText(p.Value)
+		if f.exclude != nil && f.exclude.MatchString(value) {
+			return false
+		}
+		if f.match != nil && f.match.MatchString(value) {
+			matched = true
+		}
+	}
+	return matched
+}
+
+// filter returns the events f keeps, in input order. Each component is
+// judged on its own, like the -me and -attendee filters.
+func (f textFilter) filter(events []calcut.Event) []calcut.Event {
+	if f.match == nil && f.exclude == nil {
+		return events
+	}
+	var out []calcut.Event
+	for _, e := range events {
+		if f.keep(e) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+	"github.com/sedurm85/calcut/pkg/calcut"
+)
+
+// mergeCalendars implements "merge a.ics b.ics ... -o merged.ics", the
+// inverse of a split.
+func mergeCalendars(args []string) error {
+	fs := flag.NewFlagSet("merge", flag.ExitOnError)
+	output := fs.String("o", "", i18n.T("병합 결과를 쓸 파일"))
+	dedupeUID := fs.Bool("dedupe-uid", false, i18n.T("UID(와 RECURRENCE-ID)가 같은 이벤트는 처음 것만 남김"))
+	keepLatest := fs.Bool("keep-latest", false, i18n.T("-dedupe-uid에서 처음 것 대신 SEQUENCE, LAST-MODIFIED가 가장 최신인 것을 남김"))
+	fileMode := fs.String("file-mode", "0644", i18n.T("생성 파일 권한 (8진수, umask 적용)"))
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, i18n.T("사용법: split-ical merge [옵션] <입력.ics>... -o <출력.ics>\n\n옵션:\n"))
+		fs.PrintDefaults()
+	}
+	inputs := parseInterspersed(fs, args)
+	if len(inputs) == 0 || *output == "" {
+		fs.Usage()
+		os.Exit(1)
+	}
+	mode, err := parseFileMode(*fileMode)
+	if err != nil {
+		return err
+	}
+
+	cals := make([]calcut.ParsedCalendar, len(inputs))
+	total := 0
+	for i, path := range inputs {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		cals[i], err = calcut.ParseBytes(data, calcut.ParseLimits{})
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		total += len(cals[i].Events)
+	}
+
+	merged := calcut.Merge(cals, calcut.MergeOptions{DedupeUID: *dedupeUID || *keepLatest, KeepLatest: *keepLatest})
+	content := merged.Build(merged.Events)
+	if err := validateOutputPath(*output); err != nil {
+		return err
+	}
+	if err := writeFile(*output, content, mode); err != nil {
+		return err
+	}
+
+	report(i18n.T("병합 완료: %d개 파일, %d개 이벤트"), len(inputs), len(merged.Events))
+	if dropped := total - len(merged.Events); dropped > 0 {
+		report(i18n.T(" (중복 %d개 제외)"), dropped)
+	}
+	report(i18n.T(", 시간대 %d개 -> %s (%s)\n"), len(merged.Timezones), *output, calcut.FormatBytes(int64(len(content))))
+	return nil
+}
+
+// parseInterspersed parses fs from args, allowing flags after positional
+// arguments as well, and returns the positional ones.
+func parseInterspersed(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		fs.Parse(args)
+		if fs.NArg() == 0 {
+			return positional
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
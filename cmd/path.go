@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+)
+
+// validateOutputPath checks a final output path against the platform's
+// length limits before anything is written, so a run fails up front with a
+// clear message instead of halfway through with a cryptic OS error.
+func validateOutputPath(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if n := pathLength(abs); n > maxPathLength {
+		return fmt.Errorf(i18n.T("출력 경로가 너무 깁니다 (%d자, 최대 %d자): %s"), n, maxPathLength, abs)
+	}
+	for _, part := range strings.Split(filepath.ToSlash(abs), "/") {
+		if n := pathLength(part); n > maxNameLength {
+			return fmt.Errorf(i18n.T("파일/디렉토리 이름이 너무 깁니다 (%d자, 최대 %d자): %s"), n, maxNameLength, part)
+		}
+	}
+	return nil
+}
+
+func validateOutputPaths(outDir string, filenames []string) error {
+	for _, name := range filenames {
+		if err := validateOutputPath(filepath.Join(outDir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkNoOverwrite fails if any of the files already exists in outDir, so
+// that a run does not clobber earlier output unless asked to (-force).
+func checkNoOverwrite(outDir string, filenames []string) error {
+	for _, name := range filenames {
+		path := filepath.Join(outDir, name)
+		if _, err := os.Lstat(longPath(path)); err == nil {
+			return overwriteError(path)
+		}
+	}
+	return nil
+}
+
+func overwriteError(path string) error {
+	return fmt.Errorf(i18n.T("이미 있는 파일은 덮어쓰지 않습니다: %s (-force로 덮어쓰거나 -clean으로 이전 결과를 지우세요)"), path)
+}
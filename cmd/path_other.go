@@ -0,0 +1,17 @@
+//go:build !windows
+
+package main
+
+// Typical Linux/macOS limits: PATH_MAX and NAME_MAX, both in bytes.
+const (
+	maxPathLength = 4096
+	maxNameLength = 255
+)
+
+func pathLength(s string) int {
+	return len(s)
+}
+
+func longPath(path string) string {
+	return path
+}
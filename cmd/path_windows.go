@@ -0,0 +1,34 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+)
+
+// With the \\?\ prefix Win32 accepts paths up to 32767 UTF-16 units, but
+// each component is still limited to 255.
+const (
+	maxPathLength = 32767
+	maxNameLength = 255
+)
+
+func pathLength(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
+
+// longPath converts path to the extended-length form so writes are not
+// capped at MAX_PATH (260 characters).
+func longPath(path string) string {
+	if strings.HasPrefix(path, `\\?\`) {
+		return path
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	if strings.HasPrefix(abs, `\\`) {
+		return `\\?\UNC\` + abs[2:]
+	}
+	return `\\?\` + abs
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"time"
+	// Windows has no zoneinfo database of its own; TZID parameters and -tz
+	// must resolve the same on every platform.
+	_ "time/tzdata"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+	"github.com/sedurm85/calcut/pkg/calcut"
+)
+
+// parseWindow parses -from and -to: plain YYYY-MM-DD dates with
+// -strict-dates, otherwise also the forms ParseDatePeriod knows, such as
+// "2024-03" or "90 days ago" counted from now.
+func parseWindow(from, to string, strict bool, now time.Time, loc *time.Location) (calcut.DateWindow, error) {
+	if strict {
+		return calcut.ParseDateWindow(from, to, loc)
+	}
+	return calcut.ParseNaturalDateWindow(from, to, now, loc)
+}
+
+func loadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("알 수 없는 시간대: %s"), name)
+	}
+	return loc, nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+	"github.com/sedurm85/calcut/pkg/calcut"
+)
+
+// Buckets of -by proximity: events within -radius of -near, the others,
+// and those without GEO or X-APPLE-STRUCTURED-LOCATION.
+const (
+	nearBucket    = "near"
+	farBucket     = "far"
+	noGeoBucket   = "no-location"
+	proximityMode = "proximity"
+)
+
+// parseRadius reads a distance such as "5km", "500m" or "2" (kilometres)
+// and returns it in kilometres.
+func parseRadius(s string) (float64, error) {
+	v := strings.ToLower(strings.TrimSpace(s))
+	scale := 1.0
+	switch {
+	case strings.HasSuffix(v, "km"):
+		v = strings.TrimSuffix(v, "km")
+	case strings.HasSuffix(v, "m"):
+		v = strings.TrimSuffix(v, "m")
+		scale = 0.001
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf(i18n.T("잘못된 반경: %s (예: 5km, 500m)"), s)
+	}
+	return n * scale, nil
+}
+
+// proximityKeys returns the -by proximity bucket of every group, from the
+// position of its first event that has one.
+func proximityKeys(groups [][]calcut.Event, center calcut.Geo, radiusKm float64) []string {
+	keys := make([]string, len(groups))
+	for i, group := range groups {
+		keys[i] = noGeoBucket
+		for _, event := range group {
+			geo, ok := event.Geo()
+			if !ok {
+				continue
+			}
+			keys[i] = farBucket
+			if center.DistanceKm(geo) <= radiusKm {
+				keys[i] = nearBucket
+			}
+			break
+		}
+	}
+	return keys
+}
+
+// sortProximityChunks orders -by proximity chunks near, far, no-location.
+func sortProximityChunks(chunks []calcut.Chunk) {
+	order := []string{nearBucket, farBucket, noGeoBucket}
+	slices.SortStableFunc(chunks, func(a, b calcut.Chunk) int {
+		return slices.Index(order, a.Key) - slices.Index(order, b.Key)
+	})
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+	"github.com/sedurm85/calcut/pkg/calcut"
+)
+
+// redactProfile lists what a -redact-profile removes from every event.
+type redactProfile struct {
+	// remove are dropped entirely, from nested components as well (an
+	// EMAIL alarm names its recipient in an ATTENDEE of its own).
+	remove []string
+	// scrub are free-text properties in which matches of mask are
+	// replaced.
+	scrub []string
+	mask  []*regexp.Regexp
+}
+
+var redactProfiles = map[string]redactProfile{
+	"gdpr": {
+		remove: []string{"ATTENDEE", "ORGANIZER", "CONTACT"},
+		scrub:  []string{"DESCRIPTION", "X-ALT-DESC", "COMMENT"},
+		mask:   []*regexp.Regexp{emailPattern, phonePattern},
+	},
+	// links keeps meeting links out of archives that are shared.
+	"links": {
+		remove: calcut.LinkProperties,
+		scrub:  []string{"DESCRIPTION", "X-ALT-DESC", "COMMENT", "LOCATION"},
+		mask:   []*regexp.Regexp{calcut.URLPattern},
+	},
+}
+
+const redactedText = "[REDACTED]"
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`)
+	// Separated numbers such as 010-1234-5678, +82 2 123 4567 or
+	// (555) 123-4567, and E.164 numbers written without separators. Dates
+	// like 2024-03-15 do not match.
+	phonePattern = regexp.MustCompile(`(\+\d{1,3}[\s.-]?)?(\(\d{1,4}\)[\s.-]?|\d{1,4}[\s.-])\d{3,4}[\s.-]\d{4}\b|\+\d{8,15}\b`)
+)
+
+type redactor struct {
+	profiles []redactProfile
+}
+
+// newRedactor applies the profiles of a comma-separated list such as
+// "gdpr,links", in order.
+func newRedactor(list string) (*redactor, error) {
+	r := &redactor{}
+	for _, name := range strings.Split(list, ",") {
+		profile, ok := redactProfiles[strings.TrimSpace(name)]
+		if !ok {
+			var names []string
+			for n := range redactProfiles {
+				names = append(names, n)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf(i18n.T("알 수 없는 -redact-profile: %s (%s 중 하나)"), name, strings.Join(names, ", "))
+		}
+		r.profiles = append(r.profiles, profile)
+	}
+	return r, nil
+}
+
+// redact returns a copy of event with the profiles applied.
+func (r *redactor) redact(event calcut.Event) calcut.Event {
+	text := event.Text
+	for _, profile := range r.profiles {
+		text = profile.apply(text)
+	}
+	if text == event.Text {
+		return event
+	}
+	return calcut.NewEvent(text)
+}
+
+func (p redactProfile) apply(text string) string {
+	for _, name := range p.remove {
+		text = calcut.StripProperty(text, name)
+	}
+	for _, prop := range calcut.TopLevelProperties(text) {
+		if !slices.Contains(p.scrub, prop.Name) {
+			continue
+		}
+		// Match on the unescaped text so that e.g. the "n" of an escaped
+		// line break is not taken for part of an address.
+		plain := calcut.UnescapeText(prop.Value)
+		scrubbed := plain
+		for _, re := range p.mask {
+			scrubbed = re.ReplaceAllString(scrubbed, redactedText)
+		}
+		if scrubbed != plain {
+			text = calcut.SetProperty(text, prop.Name, calcut.EscapeText(scrubbed))
+		}
+	}
+	return text
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/sedurm85/calcut/pkg/calcut"
+)
+
+// rewriter applies the per-event rewrites that run before splitting: the
+// -recode conversion to UTF-8, so the others see plain text, the -shift
+// move in time, so the script sees the times it will be written with, the
+// transform script, the stamp properties, the -target meeting links and
+// alarms, and last the redaction profile, so nothing the others add
+// escapes it. It works one event at a time so the in-memory and the
+// -stream paths share it, and reports every change to audit, and every
+// changed UID to uids, when set.
+type rewriter struct {
+	recode  bool
+	shift   time.Duration
+	script  *scriptTransform
+	stamps  []stampProp
+	target  *conferenceInliner
+	alarms  calcut.AlarmPolicy
+	redact  *redactor
+	encrypt *fieldEncryptor
+	source  string
+	now     time.Time
+	audit   *auditLog
+	uids    *uidMap
+
+	kept int
+}
+
+// rewrite returns the rewritten event, or keep == false when the script
+// dropped it.
+func (r *rewriter) rewrite(event calcut.Event) (calcut.Event, bool, error) {
+	uid := event.UID
+	if r.recode {
+		if out, ok := calcut.RecodeEvent(event); ok {
+			r.audit.record("recode", event, out)
+			event = out
+		}
+	}
+	if r.shift != 0 {
+		if out, ok := calcut.ShiftEvent(event, r.shift); ok {
+			r.audit.record("shift", event, out)
+			event = out
+		}
+	}
+	if r.script != nil {
+		out, keep, err := r.script.applyEvent(event)
+		if err != nil {
+			return calcut.Event{}, false, err
+		}
+		if !keep {
+			r.audit.dropped("transform-script", event)
+			return calcut.Event{}, false, nil
+		}
+		r.audit.record("transform-script", event, out)
+		event = out
+	}
+	r.kept++
+	if len(r.stamps) > 0 {
+		out, err := stampEvent(event, r.kept, r.stamps, r.source, r.now)
+		if err != nil {
+			return calcut.Event{}, false, err
+		}
+		r.audit.record("stamp-prop", event, out)
+		event = out
+	}
+	if r.target != nil {
+		out := r.target.inline(event)
+		r.audit.record("target", event, out)
+		event = out
+	}
+	if out, ok := calcut.NormalizeAlarms(event, r.alarms); ok {
+		r.audit.record("target-alarms", event, out)
+		event = out
+	}
+	if r.redact != nil {
+		out := r.redact.redact(event)
+		r.audit.record("redact-profile", event, out)
+		event = out
+	}
+	if r.encrypt != nil {
+		out := r.encrypt.encrypt(event)
+		r.audit.record("encrypt-fields", event, out)
+		event = out
+	}
+	r.uids.add(uid, event.UID)
+	return event, true, nil
+}
+
+// rewriteAll rewrites events and returns the survivors as a new slice.
+func (r *rewriter) rewriteAll(ctx context.Context, events []calcut.Event) ([]calcut.Event, error) {
+	var out []calcut.Event
+	for _, event := range events {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		event, keep, err := r.rewrite(event)
+		if err != nil {
+			return nil, err
+		}
+		if keep {
+			out = append(out, event)
+		}
+	}
+	return out, nil
+}
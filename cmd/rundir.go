@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const latestLink = "latest"
+
+// newRunDir creates a timestamped subdirectory of outDir for a single run.
+// A numeric suffix is added if two runs start within the same second.
+func newRunDir(outDir string, now time.Time, perm os.FileMode) (string, error) {
+	base := now.Format("20060102-150405")
+	name := base
+	for i := 2; ; i++ {
+		dir := filepath.Join(outDir, name)
+		err := os.Mkdir(longPath(dir), perm)
+		if err == nil {
+			return dir, nil
+		}
+		if !os.IsExist(err) {
+			return "", err
+		}
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
+// updateLatestLink points outDir/latest at runDir. The link is relative so
+// the whole output tree can be moved, and it is swapped in with a rename so
+// readers never observe a missing link.
+func updateLatestLink(outDir, runDir string) error {
+	link := filepath.Join(outDir, latestLink)
+	tmp := link + ".tmp"
+	os.Remove(tmp)
+	if err := os.Symlink(filepath.Base(runDir), tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, link); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+	"github.com/sedurm85/calcut/pkg/calcut"
+)
+
+// searchEntry is what the search index knows of one UID: the file it was
+// split into, the date (YYYY-MM-DD) it starts, its summary to show as a
+// result, and the words to match. A series and its overrides share one
+// entry.
+type searchEntry struct {
+	File    string   `json:"file"`
+	Date    string   `json:"date,omitempty"`
+	Summary string   `json:"summary,omitempty"`
+	Tokens  []string `json:"tokens"`
+}
+
+// buildSearchIndex implements "index", which writes a JSON search index
+// mapping each UID to a searchEntry, for a static page that offers search
+// over a split archive without a server.
+func buildSearchIndex(args []string) error {
+	flags := flag.NewFlagSet("index", flag.ExitOnError)
+	output := flags.String("o", "", i18n.T("인덱스를 쓸 파일 (기본: 표준 출력)"))
+	fileMode := flags.String("file-mode", "0644", i18n.T("생성 파일 권한 (8진수, umask 적용)"))
+	preferLang := flags.String("prefer-lang", "", i18n.T("SUMMARY가 언어별(LANGUAGE)로 여럿이면 결과에 보여 줄 언어, 쉼표로 구분해 우선순위대로 (예: ko,en)"))
+	flags.Usage = func() {
+		fmt.Fprint(os.Stderr, i18n.T("사용법: split-ical index [옵션] <입력.ics 또는 분할 출력 디렉토리>... [-o search.json]\n\n옵션:\n"))
+		flags.PrintDefaults()
+	}
+	inputs := parseInterspersed(flags, args)
+	if len(inputs) == 0 {
+		flags.Usage()
+		os.Exit(1)
+	}
+	mode, err := parseFileMode(*fileMode)
+	if err != nil {
+		return err
+	}
+
+	langs := splitList(*preferLang)
+	index := make(map[string]*searchEntry)
+	events := 0
+	for _, input := range inputs {
+		files, err := searchFiles(input)
+		if err != nil {
+			return err
+		}
+		for _, f := range files {
+			data, err := os.ReadFile(f.path)
+			if err != nil {
+				return err
+			}
+			parsed, err := calcut.ParseBytes(data, calcut.DefaultParseLimits())
+			if err != nil {
+				return fmt.Errorf("%s: %w", f.path, err)
+			}
+			for _, ev := range calcut.PreferLanguages(parsed.Events, langs) {
+				events++
+				addSearchEntry(index, f.name, ev)
+			}
+		}
+	}
+
+	data, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		fmt.Println(string(data))
+		return nil
+	}
+	if err := validateOutputPath(*output); err != nil {
+		return err
+	}
+	if err := writeFile(*output, string(data)+"\n", mode); err != nil {
+		return err
+	}
+	report(i18n.T("인덱스 완료: 이벤트 %d개, UID %d개 -> %s (%s)\n"), events, len(index), *output, calcut.FormatBytes(int64(len(data)+1)))
+	return nil
+}
+
+// searchFile is an input calendar and the name the index gives it.
+type searchFile struct {
+	path, name string
+}
+
+// searchFiles lists the calendars of an input: the file itself, named by
+// its base name, or the .ics files below a split output directory, named
+// by their slash-separated path inside it.
+func searchFiles(input string) ([]searchFile, error) {
+	info, err := os.Stat(input)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []searchFile{{path: input, name: filepath.Base(input)}}, nil
+	}
+	var files []searchFile
+	err = filepath.WalkDir(input, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".ics") {
+			return err
+		}
+		rel, err := filepath.Rel(input, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, searchFile{path: path, name: filepath.ToSlash(rel)})
+		return nil
+	})
+	return files, err
+}
+
+// addSearchEntry indexes ev as found in file. Overrides add their words to
+// the entry of their series and move its date to the earliest start.
+func addSearchEntry(index map[string]*searchEntry, file string, ev calcut.Event) {
+	if ev.UID == "" {
+		return
+	}
+	date, _ := calcut.DateRange([]calcut.Event{ev})
+	entry, ok := index[ev.UID]
+	if !ok {
+		index[ev.UID] = &searchEntry{File: file, Date: date, Summary: calcut.UnescapeText(ev.Summary), Tokens: ev.SearchTokens()}
+		return
+	}
+	if date != "" && (entry.Date == "" || date < entry.Date) {
+		entry.Date = date
+	}
+	if entry.Summary == "" {
+		entry.Summary = calcut.UnescapeText(ev.Summary)
+	}
+	for _, t := range ev.SearchTokens() {
+		if !slices.Contains(entry.Tokens, t) {
+			entry.Tokens = append(entry.Tokens, t)
+		}
+	}
+}
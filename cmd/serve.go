@@ -0,0 +1,207 @@
+//go:build !lite
+
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+	"github.com/sedurm85/calcut/pkg/calcut"
+)
+
+func init() {
+	subcommands["serve"] = serveCalendars
+}
+
+// serveCalendars implements "serve", an HTTP front end so that a team can
+// deploy calcut once instead of installing the CLI on every machine.
+//
+// POST /split takes a multipart form with the calendar in a "file" field
+// and the split options as fields named like the CLI flags (max-size,
+// max-events, prefix, by, tz, components, from, to). It answers with a zip
+// of the chunks, or with their manifest when format=json.
+func serveCalendars(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", "localhost:8080", i18n.T("수신 주소 (예: :8080)"))
+	maxInputSize := fs.String("max-input-size", "50M", i18n.T("업로드 파일 최대 크기"))
+	maxComponents := fs.Int("max-components", 1_000_000, i18n.T("업로드 캘린더의 최대 컴포넌트 수 (0: 제한 없음)"))
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, i18n.T("사용법: split-ical serve [옵션]\n\n옵션:\n"))
+		fs.PrintDefaults()
+		fmt.Fprint(os.Stderr, i18n.T("\n예시:\n"))
+		fmt.Fprintf(os.Stderr, "  curl -F file=@calendar.ics -F max-size=1M http://localhost:8080/split -o result.zip\n")
+	}
+	fs.Parse(args)
+
+	limits := calcut.DefaultParseLimits()
+	limits.MaxComponents = *maxComponents
+	var err error
+	if limits.MaxBytes, err = calcut.ParseSize(*maxInputSize); err != nil {
+		return err
+	}
+	if limits.MaxBytes <= 0 {
+		return errors.New(i18n.T("-max-input-size는 0보다 커야 합니다"))
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /split", func(w http.ResponseWriter, r *http.Request) {
+		serveSplit(w, r, limits)
+	})
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	report(i18n.T("서버 시작: http://%s/split (업로드 최대 %s)\n"), *addr, calcut.FormatBytes(limits.MaxBytes))
+	return server.ListenAndServe()
+}
+
+// serveRequest holds the split options of one POST /split.
+type serveRequest struct {
+	opts   calcut.SplitOptions
+	by     string
+	loc    *time.Location
+	kinds  []string
+	window calcut.DateWindow
+	asJSON bool
+}
+
+func parseServeRequest(r *http.Request) (serveRequest, error) {
+	req := serveRequest{opts: calcut.SplitOptions{
+		Prefix:     r.FormValue("prefix"),
+		Contiguous: true,
+		Related:    true,
+	}}
+	var err error
+	if v := r.FormValue("max-size"); v != "" {
+		if req.opts.MaxBytes, err = calcut.ParseSize(v); err != nil {
+			return req, err
+		}
+	}
+	if v := r.FormValue("max-events"); v != "" {
+		if req.opts.MaxEvents, err = strconv.Atoi(v); err != nil || req.opts.MaxEvents < 0 {
+			return req, fmt.Errorf(i18n.T("max-events는 0 이상의 정수여야 합니다: %s"), v)
+		}
+	}
+	if req.by = r.FormValue("by"); req.by != "" {
+		if _, err := calcut.PeriodKey(time.Time{}, req.by); err != nil {
+			return req, err
+		}
+	}
+	if req.loc, err = loadLocation(r.FormValue("tz")); err != nil {
+		return req, err
+	}
+	if req.kinds, err = calcut.ParseComponentKinds(r.FormValue("components")); err != nil {
+		return req, err
+	}
+	if req.window, err = calcut.ParseDateWindow(r.FormValue("from"), r.FormValue("to"), req.loc); err != nil {
+		return req, err
+	}
+	switch format := r.FormValue("format"); format {
+	case "", "zip":
+	case "json":
+		req.asJSON = true
+	default:
+		return req, fmt.Errorf(i18n.T("알 수 없는 형식: %s (zip, json)"), format)
+	}
+	return req, nil
+}
+
+func serveSplit(w http.ResponseWriter, r *http.Request, limits calcut.ParseLimits) {
+	// Leave room for the multipart framing and the option fields.
+	r.Body = http.MaxBytesReader(w, r.Body, limits.MaxBytes+1<<20)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, fmt.Sprintf(i18n.T("업로드가 너무 큽니다 (최대 %s)"), calcut.FormatBytes(limits.MaxBytes)), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, i18n.T("file 필드에 .ics 파일이 필요합니다"), http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	req, err := parseServeRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	parsed, err := calcut.ParseBytesContext(r.Context(), data, limits)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	parsed.Events = calcut.FilterWindow(calcut.FilterKinds(parsed.Events, req.kinds), req.window)
+	if len(parsed.Events) == 0 {
+		http.Error(w, i18n.T("이벤트가 없습니다"), http.StatusUnprocessableEntity)
+		return
+	}
+
+	groups := calcut.GroupEvents(parsed.Events, req.opts.Related)
+	var chunks []calcut.Chunk
+	switch {
+	case req.by != "":
+		chunks, err = calcut.PlanByPeriod(parsed, groups, req.by, req.loc, req.opts)
+	case req.opts.MaxBytes > 0 || req.opts.MaxEvents > 0:
+		chunks = calcut.PlanBySize(parsed, groups, req.opts)
+	default:
+		chunks = calcut.PlanPerEvent(parsed, groups, req.opts)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	contents := make([]string, len(chunks))
+	m := manifest{Planned: len(chunks), Files: make([]manifestFile, len(chunks))}
+	for i, chunk := range chunks {
+		contents[i] = parsed.BuildChunk(chunk)
+		m.Files[i] = newManifestFile(chunk, int64(len(contents[i])))
+	}
+	index, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if req.asJSON {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(append(index, '\n'))
+		return
+	}
+
+	name := strings.TrimSuffix(filepath.Base(header.Filename), filepath.Ext(header.Filename))
+	if name == "" || name == "." {
+		name = "calendar"
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name + ".zip"}))
+	now := time.Now()
+	zw := zip.NewWriter(w)
+	for i, chunk := range chunks {
+		if err := calcut.AddZipEntry(zw, chunk.Filename, contents[i], now); err != nil {
+			// The headers are out already; a broken archive is all the
+			// client can be told.
+			return
+		}
+	}
+	calcut.AddZipEntry(zw, manifestName, string(index)+"\n", now)
+	zw.Close()
+}
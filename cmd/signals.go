@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+)
+
+// interruptedError is the cancellation cause recorded when the run is
+// stopped by a signal.
+type interruptedError struct {
+	sig os.Signal
+}
+
+func (e *interruptedError) Error() string {
+	return fmt.Sprintf(i18n.T("%s 신호로 중단됨"), e.sig)
+}
+
+// exitCode follows the shell convention of 128 + signal number.
+func (e *interruptedError) exitCode() int {
+	if s, ok := e.sig.(syscall.Signal); ok {
+		return 128 + int(s)
+	}
+	return 130
+}
+
+// stopOnSignal returns a context derived from parent that is canceled with
+// an *interruptedError on the first SIGINT or SIGTERM, letting the run
+// finish the file it is writing. A second signal exits immediately.
+func stopOnSignal(parent context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(parent)
+	ch := make(chan os.Signal, 2)
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		sig, ok := <-ch
+		if !ok {
+			return
+		}
+		fmt.Fprint(os.Stderr, i18n.T("\n중단 요청을 받았습니다. 현재 파일을 마저 쓰고 종료합니다 (한 번 더 누르면 즉시 종료)\n"))
+		cancel(&interruptedError{sig: sig})
+		if sig, ok := <-ch; ok {
+			os.Exit((&interruptedError{sig: sig}).exitCode())
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(ch)
+		close(ch)
+		cancel(nil)
+	}
+}
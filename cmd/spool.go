@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/sedurm85/calcut/pkg/calcut"
+)
+
+// eventSpool keeps the events the -stream size split has planned but not
+// written yet in a hidden file of the output directory, rendered and in
+// order, so that memory holds no more than the event being read. Chunks
+// take their events from the front; the file is emptied whenever no event
+// is waiting, so it never grows past one output file.
+type eventSpool struct {
+	f     *os.File
+	write int64   // where the next event goes
+	read  int64   // where the first waiting event starts
+	sizes []int64 // rendered size of each waiting event
+}
+
+func newEventSpool(dir string) (*eventSpool, error) {
+	f, err := os.CreateTemp(longPath(dir), ".calcut-spool-*")
+	if err != nil {
+		return nil, err
+	}
+	return &eventSpool{f: f}, nil
+}
+
+// add appends an event as BuildFragment renders it.
+func (s *eventSpool) add(rendered string) error {
+	n, err := s.f.WriteAt([]byte(rendered), s.write)
+	s.write += int64(n)
+	s.sizes = append(s.sizes, int64(n))
+	return err
+}
+
+// copyTo writes the first n waiting events to w and returns their size.
+func (s *eventSpool) copyTo(w io.Writer, n int) (int64, error) {
+	var size int64
+	for _, sz := range s.sizes[:n] {
+		size += sz
+	}
+	copied, err := io.Copy(w, io.NewSectionReader(s.f, s.read, size))
+	if err != nil {
+		return copied, err
+	}
+	s.read += size
+	s.sizes = s.sizes[n:]
+	if len(s.sizes) == 0 {
+		s.read, s.write, s.sizes = 0, 0, nil
+		err = s.f.Truncate(0)
+	}
+	return copied, err
+}
+
+// close removes the spool file.
+func (s *eventSpool) close() {
+	s.f.Close()
+	os.Remove(s.f.Name())
+}
+
+// writeSpooled is write for a chunk whose events wait in spool, planned
+// by a SizeChunker with DropText set: the file is written from the spool
+// as it is read, without its content ever being in memory. It writes to
+// the output directory only, not to -zip, -stdout, -upload or
+// -cas-output.
+func (w *chunkWriter) writeSpooled(parsed calcut.ParsedCalendar, chunk calcut.Chunk, spool *eventSpool) error {
+	var head, tail string
+	if !w.opts.sharedSkeleton {
+		cal, err := chunkCalendar(parsed, chunk, chunkData{Index: len(w.written) + 1, Total: w.total, Filename: chunk.Filename}, w.opts)
+		if err != nil {
+			return err
+		}
+		head, tail = cal.BuildChunkEnds(chunk)
+	}
+
+	path := filepath.Join(w.opts.outDir, chunk.Filename)
+	flag := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !w.opts.force {
+		flag |= os.O_EXCL
+	}
+	f, err := os.OpenFile(longPath(path), flag, w.opts.fileMode)
+	if errors.Is(err, fs.ErrExist) {
+		return overwriteError(path)
+	}
+	if err != nil {
+		return err
+	}
+	out := bufio.NewWriter(f)
+	out.WriteString(head)
+	size, err := spool.copyTo(out, len(chunk.Events))
+	out.WriteString(tail)
+	if ferr := out.Flush(); err == nil {
+		err = ferr
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if w.opts.postHook != "" {
+		if err := runHook(w.ctx, w.opts.postHook, path); err != nil {
+			return err
+		}
+	}
+	w.done(chunk, int64(len(head))+size+int64(len(tail)))
+	return nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+	"github.com/sedurm85/calcut/pkg/calcut"
+)
+
+const version = "1.0.0"
+
+// stringList collects the values of a repeatable flag.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ", ")
+}
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// stampData is the template context for -stamp-prop values.
+type stampData struct {
+	Version string
+	Now     time.Time
+	Source  string
+	Index   int
+	UID     string
+	Summary string
+	DTStart string
+}
+
+type stampProp struct {
+	name string
+	tmpl *template.Template
+}
+
+// parseStampProps parses "NAME:template" specs. The value part is a Go
+// text/template evaluated once per event.
+func parseStampProps(specs []string) ([]stampProp, error) {
+	var props []stampProp
+	for _, spec := range specs {
+		name, value, ok := strings.Cut(spec, ":")
+		name = strings.ToUpper(strings.TrimSpace(name))
+		if !ok || name == "" || strings.ContainsAny(name, ";\r\n") {
+			return nil, fmt.Errorf(i18n.T("잘못된 -stamp-prop 형식: %s (예: X-ARCHIVED-BY:calcut {{.Version}})"), spec)
+		}
+		tmpl, err := template.New(name).Option("missingkey=error").Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf(i18n.T("-stamp-prop %s 템플릿 오류: %w"), name, err)
+		}
+		props = append(props, stampProp{name: name, tmpl: tmpl})
+	}
+	return props, nil
+}
+
+// stampEvent returns a copy of event with every stamp property set,
+// replacing an existing property of the same name; index is the event's
+// 1-based position. Templates are only executed, never modified, so props
+// can be shared between goroutines.
+func stampEvent(event calcut.Event, index int, props []stampProp, source string, now time.Time) (calcut.Event, error) {
+	var b strings.Builder
+	text := event.Text
+	data := stampData{
+		Version: version,
+		Now:     now,
+		Source:  source,
+		Index:   index,
+		UID:     event.UID,
+		Summary: event.Summary,
+		DTStart: event.DTStart,
+	}
+	for _, p := range props {
+		b.Reset()
+		if err := p.tmpl.Execute(&b, data); err != nil {
+			return calcut.Event{}, fmt.Errorf(i18n.T("-stamp-prop %s 적용 실패: %w"), p.name, err)
+		}
+		value := strings.NewReplacer("\r", "", "\n", `\n`).Replace(b.String())
+		text = calcut.SetProperty(text, p.name, value)
+	}
+	return calcut.NewEvent(text), nil
+}
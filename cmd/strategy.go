@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+	"github.com/sedurm85/calcut/pkg/calcut"
+)
+
+// strategyEvent is the JSON line sent to a -strategy-exec program for each
+// event. The program answers with one line per event holding a bucket name.
+// Summary is unescaped; Details and Text are as written in the calendar.
+type strategyEvent struct {
+	Index   int    `json:"index"`
+	Kind    string `json:"kind"`
+	UID     string `json:"uid"`
+	Summary string `json:"summary"`
+	DTStart string `json:"dtstart"`
+	Color   string `json:"color,omitempty"`
+	// Geo is the event's GEO or else the coordinates of its
+	// X-APPLE-STRUCTURED-LOCATION, whose title is Place.
+	Geo     *calcut.Geo    `json:"geo,omitempty"`
+	Place   string         `json:"place,omitempty"`
+	Details calcut.Details `json:"details"`
+	Text    string         `json:"text"`
+}
+
+const defaultBucket = "unassigned"
+
+// bucketsFromExec asks an external program to assign every group to a
+// bucket. Only the first event of each group is sent; linked events follow
+// it. Input is written from a separate goroutine so programs that buffer
+// their output cannot deadlock against a full pipe.
+func bucketsFromExec(ctx context.Context, program string, groups [][]calcut.Event) ([]string, error) {
+	cmd := exec.CommandContext(ctx, program)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf(i18n.T("전략 프로그램 실행 실패: %w"), err)
+	}
+
+	writeErr := make(chan error, 1)
+	go func() {
+		enc := json.NewEncoder(stdin)
+		for i, group := range groups {
+			ev := group[0]
+			line := strategyEvent{
+				Index:   i + 1,
+				Kind:    ev.Kind,
+				UID:     ev.UID,
+				Summary: calcut.UnescapeText(ev.Summary),
+				DTStart: ev.DTStart,
+				Color:   ev.Color(),
+				Details: ev.Details(),
+				Text:    ev.Text,
+			}
+			if geo, ok := ev.Geo(); ok {
+				line.Geo = &geo
+			}
+			if loc, ok := ev.StructuredLocation(); ok {
+				line.Place = loc.Title
+			}
+			if err := enc.Encode(line); err != nil {
+				stdin.Close()
+				writeErr <- err
+				return
+			}
+		}
+		writeErr <- stdin.Close()
+	}()
+
+	var buckets []string
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		bucket := strings.TrimSpace(scanner.Text())
+		if bucket == "" {
+			bucket = defaultBucket
+		}
+		buckets = append(buckets, bucket)
+	}
+	scanErr := scanner.Err()
+	waitErr := cmd.Wait()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	if err := <-writeErr; err != nil && waitErr == nil {
+		return nil, fmt.Errorf(i18n.T("전략 프로그램에 이벤트 전달 실패: %w"), err)
+	}
+	if scanErr != nil {
+		return nil, scanErr
+	}
+	if waitErr != nil {
+		return nil, fmt.Errorf(i18n.T("전략 프로그램 오류: %w"), waitErr)
+	}
+	if len(buckets) != len(groups) {
+		return nil, fmt.Errorf(i18n.T("전략 프로그램 응답 수 불일치: 이벤트 %d개, 응답 %d개"), len(groups), len(buckets))
+	}
+	return buckets, nil
+}
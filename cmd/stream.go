@@ -0,0 +1,189 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+	"github.com/sedurm85/calcut/pkg/calcut"
+)
+
+// splitStream is the -stream counterpart of planning plus writeChunks: it
+// reads the input one event at a time and writes each file as soon as it
+// is complete, so memory stays bounded by the largest file rather than the
+// input. Only consecutive events sharing a UID are grouped (exports list a
+// series' overrides right after it), and the total number of files is not
+// known while writing. Split by size into the output directory, the events
+// of the file being filled wait in an eventSpool on disk rather than in
+// memory, which then holds little more than the event being read.
+func splitStream(ctx, stop context.Context, path string, limits calcut.ParseLimits, rw *rewriter, opts splitOptions) ([]manifestFile, error) {
+	f, err := openInput(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	stream := calcut.NewStream(f, limits)
+	w := newChunkWriter(ctx, 0, opts)
+	splitOpts := calcut.SplitOptions{
+		Prefix:    opts.prefix,
+		MaxBytes:  opts.maxBytes,
+		MaxEvents: opts.maxEvents,
+		// The total is unknown up front, so leave room for the widest
+		// numbers the templates could see.
+		Reserve:          calendarPropsReserve(math.MaxInt32, opts),
+		KeepAllTimezones: opts.allTimezones,
+	}
+
+	var spool *eventSpool
+	if (opts.maxBytes > 0 || opts.maxEvents > 0) && opts.archive == nil && opts.cas == nil {
+		if spool, err = newEventSpool(opts.outDir); err != nil {
+			return nil, err
+		}
+		defer spool.close()
+	}
+
+	var skeleton calcut.ParsedCalendar
+	var chunker *calcut.SizeChunker
+	var group []calcut.Event
+	groups, files := 0, 0
+	names := calcut.FilenameSet{Strict: opts.strictNames}
+	write := func(chunks []calcut.Chunk) error {
+		for _, chunk := range chunks {
+			if files++; !opts.names.IsZero() {
+				chunk.Filename = opts.names.Name(opts.prefix, files, chunk)
+			}
+			if opts.sharedSkeleton {
+				chunk.Filename = fragmentName(chunk.Filename)
+			}
+			if err := names.Claim(&chunk); err != nil {
+				return err
+			}
+			if opts.archive == nil {
+				if err := validateOutputPath(filepath.Join(opts.outDir, chunk.Filename)); err != nil {
+					return err
+				}
+			}
+			var err error
+			if spool != nil {
+				err = w.writeSpooled(skeleton, chunk, spool)
+			} else {
+				err = w.write(skeleton, chunk)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	flush := func() error {
+		// Groups hold one UID each, so a cancelled series is seen whole.
+		if opts.dropCancelled {
+			group = calcut.FilterCancelled(group)
+		}
+		group = calcut.FilterHours(calcut.FilterWindow(group, opts.window), opts.hours)
+		if len(group) == 0 {
+			return nil
+		}
+		groups++
+		var chunks []calcut.Chunk
+		if opts.maxBytes > 0 || opts.maxEvents > 0 {
+			if spool != nil {
+				for _, event := range group {
+					if err := spool.add(skeleton.BuildFragment([]calcut.Event{event})); err != nil {
+						return err
+					}
+				}
+			}
+			chunks = chunker.Add(group)
+		} else {
+			chunk := calcut.Chunk{Filename: calcut.PerEventFilename(opts.prefix, groups, group[0]), Events: group, Timezones: skeleton.Timezones}
+			if !opts.allTimezones {
+				chunk.Timezones = skeleton.TimezonesFor(group)
+			}
+			chunks = []calcut.Chunk{chunk}
+		}
+		group = nil
+		return write(chunks)
+	}
+
+	n := 0
+	for {
+		if stop.Err() != nil {
+			return w.written, context.Cause(stop)
+		}
+		event, err := stream.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return w.written, err
+		}
+		if (len(opts.kinds) > 0 && !slices.Contains(opts.kinds, event.Kind)) || !opts.partStat.Keep(event) || !opts.people.Keep(event) || !opts.durations.Keep(event) || !opts.text.keep(event) {
+			continue
+		}
+		event, keep, err := rw.rewrite(event)
+		if err != nil {
+			return w.written, err
+		}
+		if !keep {
+			continue
+		}
+		if opts.checksums {
+			event = calcut.StampChecksum(event)
+		}
+		if len(opts.langs) > 0 {
+			event.Summary = event.Localized("SUMMARY", opts.langs)
+		}
+		n++
+		if n == 1 {
+			skeleton = stream.Calendar()
+			skeleton.LineEnding = cmp.Or(opts.lineEnding, skeleton.LineEnding)
+			skeleton.TimezonesFirst = opts.timezonesFirst
+			chunker = calcut.NewSizeChunker(skeleton, splitOpts)
+			chunker.DropText = spool != nil
+		}
+
+		if len(group) > 0 && (event.UID == "" || event.UID != group[0].UID) {
+			if err := flush(); err != nil {
+				return w.written, err
+			}
+		}
+		group = append(group, event)
+	}
+	if err := flush(); err != nil {
+		return w.written, err
+	}
+	if chunker != nil {
+		if err := write(chunker.Flush()); err != nil {
+			return w.written, err
+		}
+	}
+	w.prog.finish(len(w.written))
+
+	if len(w.written) == 0 {
+		fmt.Fprintln(os.Stderr, i18n.T("경고: 이벤트가 없습니다."))
+	}
+	final := stream.Calendar()
+	final.LineEnding = cmp.Or(opts.lineEnding, final.LineEnding)
+	final.TimezonesFirst = opts.timezonesFirst
+	noteParseWarnings(path, final.Warnings, opts)
+	if opts.sharedSkeleton {
+		// Fragments do not depend on the skeleton, so it can wait for
+		// whatever came after the first event.
+		if n > 0 {
+			return w.written, writeNewOutput(skeletonName, final.Build(nil), opts)
+		}
+		return w.written, nil
+	}
+	if n > 0 && (len(final.HeaderLines) != len(skeleton.HeaderLines) || len(final.Timezones) != len(skeleton.Timezones)) {
+		fmt.Fprintln(os.Stderr, i18n.T("경고: 첫 이벤트 뒤에 나온 VCALENDAR 속성/VTIMEZONE은 출력 파일에 포함되지 않았습니다"))
+	}
+	return w.written, nil
+}
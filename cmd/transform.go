@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+	"github.com/sedurm85/calcut/pkg/calcut"
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+)
+
+// scriptTransform applies a Starlark -transform-script to events. The
+// script must define transform(event), which receives a dict of the event's
+// top-level properties (name -> value of the first occurrence) and returns
+// the modified dict, or None to drop the event. Setting a property to None
+// removes every occurrence of it; new keys are appended as new properties.
+//
+// A scriptTransform owns a Starlark thread and must not be used from
+// several goroutines at once; load one per goroutine instead. The script's
+// globals are frozen after loading, so scripts cannot carry state from one
+// event to the next.
+type scriptTransform struct {
+	thread *starlark.Thread
+	fn     starlark.Callable
+}
+
+func loadTransformScript(path string) (*scriptTransform, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	thread := &starlark.Thread{
+		Name:  "transform",
+		Print: func(_ *starlark.Thread, msg string) { fmt.Fprintln(os.Stderr, msg) },
+	}
+	globals, err := starlark.ExecFileOptions(&syntax.FileOptions{}, thread, path, src, nil)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.T("변환 스크립트 로드 실패: %w"), err)
+	}
+	fn, ok := globals["transform"].(starlark.Callable)
+	if !ok {
+		return nil, fmt.Errorf(i18n.T("변환 스크립트에 transform(event) 함수가 없습니다: %s"), path)
+	}
+	return &scriptTransform{thread: thread, fn: fn}, nil
+}
+
+// applyEvent runs the script on a single event; keep is false when the
+// script dropped it.
+func (t *scriptTransform) applyEvent(event calcut.Event) (calcut.Event, bool, error) {
+	text, keep, err := t.applyOne(event.Text)
+	if err != nil {
+		return calcut.Event{}, false, fmt.Errorf(i18n.T("이벤트 '%s' 변환 실패: %w"), event.UID, err)
+	}
+	if !keep {
+		return calcut.Event{}, false, nil
+	}
+	return calcut.NewEvent(text), true, nil
+}
+
+func (t *scriptTransform) applyOne(text string) (string, bool, error) {
+	props := calcut.TopLevelProperties(text)
+	original := make(map[string]string, len(props))
+	in := starlark.NewDict(len(props))
+	for _, p := range props {
+		if _, seen := original[p.Name]; seen {
+			continue
+		}
+		original[p.Name] = p.Value
+		in.SetKey(starlark.String(p.Name), starlark.String(p.Value))
+	}
+
+	result, err := starlark.Call(t.thread, t.fn, starlark.Tuple{in}, nil)
+	if err != nil {
+		return "", false, err
+	}
+	if result == starlark.None {
+		return "", false, nil
+	}
+	out, ok := result.(*starlark.Dict)
+	if !ok {
+		return "", false, fmt.Errorf(i18n.T("transform()는 dict 또는 None을 반환해야 합니다 (%s)"), result.Type())
+	}
+
+	for _, p := range props {
+		if _, found, _ := out.Get(starlark.String(p.Name)); !found {
+			text = calcut.RemoveProperty(text, p.Name)
+		}
+	}
+	for _, item := range out.Items() {
+		name, ok := starlark.AsString(item[0])
+		if !ok {
+			return "", false, fmt.Errorf(i18n.T("속성 이름은 문자열이어야 합니다 (%s)"), item[0])
+		}
+		name = strings.ToUpper(name)
+		if item[1] == starlark.None {
+			text = calcut.RemoveProperty(text, name)
+			continue
+		}
+		value, ok := starlark.AsString(item[1])
+		if !ok {
+			return "", false, fmt.Errorf(i18n.T("%s 값은 문자열이어야 합니다 (%s)"), name, item[1].Type())
+		}
+		if old, ok := original[name]; ok && old == value {
+			continue
+		}
+		text = calcut.SetProperty(text, name, value)
+	}
+	return text, true, nil
+}
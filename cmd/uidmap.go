@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"os"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+)
+
+// uidMap collects the UIDs a run rewrote, for -uid-map, so that systems
+// still holding the original UIDs can be pointed at the new ones. A nil
+// *uidMap records nothing.
+type uidMap struct {
+	pairs [][2]string
+	seen  map[[2]string]bool
+}
+
+func newUIDMap() *uidMap {
+	return &uidMap{seen: make(map[[2]string]bool)}
+}
+
+// add notes that the event with UID from was written with UID to. Each
+// pair is kept once, in the order first seen, so a series and its
+// overrides rewritten alike give one row. Events without a UID are left
+// out: nothing can refer to them by it.
+func (m *uidMap) add(from, to string) {
+	if m == nil || from == to || from == "" {
+		return
+	}
+	pair := [2]string{from, to}
+	if m.seen[pair] {
+		return
+	}
+	m.seen[pair] = true
+	m.pairs = append(m.pairs, pair)
+}
+
+// write writes m to path as CSV with an "old_uid,new_uid" header.
+func (m *uidMap) write(path string, mode os.FileMode) error {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	w.Write([]string{"old_uid", "new_uid"})
+	for _, pair := range m.pairs {
+		w.Write(pair[:])
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return writeFile(path, b.String(), mode)
+}
+
+// writeUIDMap writes m to path, warning instead of failing the run as
+// writeAuditLog does.
+func writeUIDMap(m *uidMap, path string, mode os.FileMode) {
+	if m == nil {
+		return
+	}
+	if err := m.write(path, mode); err != nil {
+		fmt.Fprintf(os.Stderr, i18n.T("경고: UID 대응표 기록 실패 - %s\n"), err)
+	}
+}
@@ -0,0 +1,287 @@
+//go:build !lite
+
+package main
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+	"github.com/sedurm85/calcut/pkg/calcut"
+)
+
+// uploadTimeout bounds the upload of a single file.
+const uploadTimeout = 5 * time.Minute
+
+// uploadBackoff is the wait before the first retry of a failed upload,
+// doubled for each further one.
+const uploadBackoff = 500 * time.Millisecond
+
+// uploadJob is a file waiting in the upload queue.
+type uploadJob struct {
+	name    string
+	content string
+}
+
+// checksums returns the MD5 and SHA-256 of the file in hex.
+func (j uploadJob) checksums() (md5Hex, sha256Hex string) {
+	m := md5.Sum([]byte(j.content))
+	s := sha256.Sum256([]byte(j.content))
+	return hex.EncodeToString(m[:]), hex.EncodeToString(s[:])
+}
+
+// uploadArchive sends the output files with HTTP PUT to a base URL, such
+// as a WebDAV folder or a bucket accepting PUT (-upload). Files go
+// through a bounded queue drained by a few workers: the split keeps going
+// while uploads are slow, and waits once the queue is full, so at most
+// the queue plus one file per worker is held in memory.
+//
+// Files the server already holds with the same content are skipped, so an
+// interrupted run can simply be started again, and failed uploads are
+// retried, which is safe as PUT replaces the whole file.
+type uploadArchive struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	base    *url.URL
+	client  *http.Client
+	token   string
+	retries int
+	queue   chan uploadJob
+	wg      sync.WaitGroup
+
+	mu       sync.Mutex
+	err      error
+	uploaded int
+	skipped  int
+	bytes    int64
+
+	// maxDepth is the longest the queue got, waited how long adding
+	// files blocked on a full queue.
+	maxDepth int
+	waited   time.Duration
+}
+
+// newUploadArchive starts workers uploading to rawURL, which must be an
+// http or https URL of a folder. User and password in the URL are sent as
+// basic authentication; CALCUT_UPLOAD_TOKEN, when set, as a bearer token.
+func newUploadArchive(ctx context.Context, rawURL string, workers, queue, retries int) (archive, error) {
+	base, err := url.Parse(rawURL)
+	if err != nil || (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+		return nil, fmt.Errorf(i18n.T("잘못된 업로드 주소: %s (http 또는 https 폴더 주소)"), rawURL)
+	}
+	if workers < 1 || queue < 1 {
+		return nil, errors.New(i18n.T("-upload-workers와 -upload-queue는 1 이상이어야 합니다"))
+	}
+	if retries < 0 {
+		return nil, errors.New(i18n.T("-upload-retries는 0 이상이어야 합니다"))
+	}
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	a := &uploadArchive{
+		ctx:     ctx,
+		cancel:  cancel,
+		base:    base,
+		client:  &http.Client{Timeout: uploadTimeout},
+		token:   os.Getenv("CALCUT_UPLOAD_TOKEN"),
+		retries: retries,
+		queue:   make(chan uploadJob, queue),
+	}
+	for range workers {
+		a.wg.Add(1)
+		go a.work()
+	}
+	return a, nil
+}
+
+func (a *uploadArchive) work() {
+	defer a.wg.Done()
+	for job := range a.queue {
+		if a.ctx.Err() != nil {
+			continue
+		}
+		skipped, err := a.put(job)
+		a.mu.Lock()
+		switch {
+		case err != nil && a.err == nil:
+			a.err = err
+			a.cancel()
+		case err != nil:
+		case skipped:
+			a.skipped++
+		default:
+			a.uploaded++
+			a.bytes += int64(len(job.content))
+		}
+		a.mu.Unlock()
+	}
+}
+
+// put uploads job unless the server has it already, which it reports
+// as skipped.
+func (a *uploadArchive) put(job uploadJob) (skipped bool, err error) {
+	target := a.base.JoinPath(job.name).String()
+	md5Hex, sha256Hex := job.checksums()
+	if a.exists(target, job, md5Hex, sha256Hex) {
+		return true, nil
+	}
+	contentType := "text/calendar; charset=utf-8"
+	if job.name == manifestName || job.name == auditLogName {
+		contentType = "application/json"
+	}
+	md5Sum, _ := hex.DecodeString(md5Hex)
+	resp, err := a.do(func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(a.ctx, http.MethodPut, target, strings.NewReader(job.content))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", contentType)
+		// Checksums servers can keep and report back on HEAD: S3 and
+		// compatible stores check Content-MD5 and keep x-amz-meta-*
+		// headers, Nextcloud and ownCloud keep OC-Checksum.
+		req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(md5Sum))
+		req.Header.Set("X-Amz-Meta-Calcut-Sha256", sha256Hex)
+		req.Header.Set("OC-Checksum", "SHA256:"+sha256Hex)
+		return req, nil
+	})
+	if err != nil {
+		return false, fmt.Errorf(i18n.T("%s 업로드 실패: %w"), job.name, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return false, fmt.Errorf(i18n.T("%s 업로드 실패 (%s)"), job.name, resp.Status)
+	}
+	return false, nil
+}
+
+// exists reports whether the server holds job's content at target: a HEAD
+// request finds a file of the same length whose ETag is its MD5 (as S3
+// gives for whole-file uploads) or whose kept checksum matches. Anything
+// else, failures included, counts as absent and is uploaded again.
+func (a *uploadArchive) exists(target string, job uploadJob, md5Hex, sha256Hex string) bool {
+	resp, err := a.do(func() (*http.Request, error) {
+		return http.NewRequestWithContext(a.ctx, http.MethodHead, target, nil)
+	})
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.ContentLength != int64(len(job.content)) {
+		return false
+	}
+	etag := strings.Trim(strings.TrimPrefix(resp.Header.Get("ETag"), "W/"), `"`)
+	if strings.EqualFold(etag, md5Hex) || strings.EqualFold(resp.Header.Get("X-Amz-Meta-Calcut-Sha256"), sha256Hex) {
+		return true
+	}
+	for _, sum := range strings.Fields(resp.Header.Get("OC-Checksum")) {
+		if strings.EqualFold(sum, "SHA256:"+sha256Hex) || strings.EqualFold(sum, "MD5:"+md5Hex) {
+			return true
+		}
+	}
+	return false
+}
+
+// do sends the request newRequest makes, again after a growing pause when
+// the connection fails or the server answers 408, 429 or 5xx, up to
+// -upload-retries times. The body of the last response is drained so the
+// connection can be reused.
+func (a *uploadArchive) do(newRequest func() (*http.Request, error)) (*http.Response, error) {
+	wait := uploadBackoff
+	for attempt := 0; ; attempt++ {
+		req, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+		if a.token != "" {
+			req.Header.Set("Authorization", "Bearer "+a.token)
+		}
+		resp, err := a.client.Do(req)
+		retry := err != nil
+		if err == nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+			retry = resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		}
+		if !retry || attempt == a.retries || a.ctx.Err() != nil {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		select {
+		case <-time.After(wait):
+		case <-a.ctx.Done():
+			return nil, a.ctx.Err()
+		}
+		wait *= 2
+	}
+}
+
+// add queues a file, waiting while the queue is full. It returns the
+// error of a failed upload, after which nothing more is queued.
+func (a *uploadArchive) add(name, content string) error {
+	if err := a.failed(); err != nil {
+		return err
+	}
+	job := uploadJob{name: name, content: content}
+	select {
+	case a.queue <- job:
+	default:
+		start := time.Now()
+		select {
+		case a.queue <- job:
+		case <-a.ctx.Done():
+		}
+		a.mu.Lock()
+		a.waited += time.Since(start)
+		a.mu.Unlock()
+	}
+	a.mu.Lock()
+	a.maxDepth = max(a.maxDepth, len(a.queue))
+	a.mu.Unlock()
+	return a.failed()
+}
+
+func (a *uploadArchive) failed() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.err != nil {
+		return a.err
+	}
+	return a.ctx.Err()
+}
+
+// queued reports how many files wait in the queue and how many it holds.
+func (a *uploadArchive) queued() (depth, capacity int) {
+	return len(a.queue), cap(a.queue)
+}
+
+// close waits for the queued files to be uploaded and reports the run.
+func (a *uploadArchive) close() error {
+	close(a.queue)
+	a.wg.Wait()
+	err := a.failed()
+	a.cancel()
+	fmt.Printf(i18n.T("업로드: 파일 %d개 (%s) -> %s, 이미 있어 건너뜀 %d개, 대기열 최대 %d/%d개, 대기열이 차서 기다린 시간 %s\n"),
+		a.uploaded, calcut.FormatBytes(a.bytes), a.base.Redacted(), a.skipped, a.maxDepth, cap(a.queue), a.waited.Round(time.Millisecond))
+	return err
+}
+
+// discard stops the uploads. Files already uploaded stay on the server.
+func (a *uploadArchive) discard() {
+	a.cancel()
+	close(a.queue)
+	a.wg.Wait()
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+	"github.com/sedurm85/calcut/pkg/calcut"
+)
+
+// validateReport is the -json output of validate for one file.
+type validateReport struct {
+	File     string         `json:"file"`
+	Valid    bool           `json:"valid"`
+	Errors   int            `json:"errors"`
+	Warnings int            `json:"warnings"`
+	Issues   []calcut.Issue `json:"issues"`
+}
+
+// validateCalendars implements "validate", a linter for the RFC 5545
+// problems that make splits or imports go wrong. It fails when any file
+// has an error; warnings alone do not.
+func validateCalendars(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, i18n.T("결과를 JSON으로 출력"))
+	noWarnings := fs.Bool("no-warnings", false, i18n.T("경고는 출력하지 않고 오류만 출력"))
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, i18n.T("사용법: split-ical validate [옵션] <입력.ics>...\n\n옵션:\n"))
+		fs.PrintDefaults()
+	}
+	inputs := parseInterspersed(fs, args)
+	if len(inputs) == 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	term = detectConsole(false, false)
+
+	var reports []validateReport
+	failed := 0
+	for _, path := range inputs {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		r := validateReport{File: path, Issues: []calcut.Issue{}}
+		for _, issue := range calcut.ValidateLimits(data, calcut.DefaultParseLimits()) {
+			if issue.Severity == calcut.SeverityError {
+				r.Errors++
+			} else {
+				r.Warnings++
+				if *noWarnings {
+					continue
+				}
+			}
+			r.Issues = append(r.Issues, issue)
+		}
+		r.Valid = r.Errors == 0
+		if !r.Valid {
+			failed++
+		}
+		reports = append(reports, r)
+	}
+
+	if *asJSON {
+		data, err := json.MarshalIndent(reports, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	} else {
+		for _, r := range reports {
+			printValidateReport(r)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf(i18n.T("%d개 파일에 오류가 있습니다"), failed)
+	}
+	return nil
+}
+
+func printValidateReport(r validateReport) {
+	for _, issue := range r.Issues {
+		label := term.paint(colorRed, i18n.T("오류"))
+		if issue.Severity != calcut.SeverityError {
+			label = term.paint(colorYellow, i18n.T("경고"))
+		}
+		if issue.Line > 0 {
+			fmt.Printf("%s:%d: %s: %s [%s]\n", r.File, issue.Line, label, issue.Message, issue.Code)
+		} else {
+			fmt.Printf("%s: %s: %s [%s]\n", r.File, label, issue.Message, issue.Code)
+		}
+	}
+	status := term.paint(colorGreen, term.icon("✅ ", "")+i18n.T("통과"))
+	if !r.Valid {
+		status = term.paint(colorRed, term.icon("❌ ", "")+i18n.T("실패"))
+	}
+	fmt.Printf(i18n.T("%s: %s (오류 %d개, 경고 %d개)\n"), r.File, status, r.Errors, r.Warnings)
+}
@@ -1,3 +1,10 @@
-module split-ical
+module github.com/sedurm85/calcut
 
 go 1.25.6
+
+require (
+	go.starlark.net v0.0.0-20260908191801-89a6a09411d5
+	golang.org/x/text v0.40.0
+)
+
+require golang.org/x/sys v0.42.0 // indirect
@@ -0,0 +1,116 @@
+// Package i18n translates user-facing messages. Messages are written in
+// Korean, the project's source language, and the Korean format string
+// doubles as the key into the catalogs of other languages, so untranslated
+// text falls back to Korean rather than disappearing.
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Default is the source language of every message.
+const Default = "ko"
+
+// current is the locale Error and T render in. Programs with a single
+// user, such as the CLI, pick it with SetLocale; those serving several,
+// such as the WASM module, pass a locale to Sprintf and Message instead.
+var current = Default
+
+// SetLocale sets the locale of Error and T, normalized as Normalize does.
+// It is not synchronized, so it is called once at startup, before any
+// goroutine renders a message.
+func SetLocale(locale string) {
+	current = Normalize(locale)
+}
+
+// Locale returns the locale set with SetLocale.
+func Locale() string {
+	return current
+}
+
+// catalogs maps a locale to its translations, keyed by Korean format
+// string. Each translation takes the same verbs in the same order.
+var catalogs = map[string]map[string]string{
+	"en": en,
+}
+
+// Locales lists the supported locales, Default first.
+func Locales() []string {
+	return []string{Default, "en"}
+}
+
+// Normalize maps a locale such as "en-US" or "ko_KR.UTF-8" to a supported
+// one, falling back to Default.
+func Normalize(locale string) string {
+	lang := strings.ToLower(locale)
+	if i := strings.IndexAny(lang, "-_."); i >= 0 {
+		lang = lang[:i]
+	}
+	if _, ok := catalogs[lang]; ok {
+		return lang
+	}
+	return Default
+}
+
+// T returns the translation of a Korean format string in the locale set
+// with SetLocale, unformatted, for fmt.Printf and fmt.Errorf (which,
+// unlike Errorf, keeps %w) and for fixed texts such as flag usage.
+func T(format string) string {
+	if translated, ok := catalogs[current][format]; ok {
+		return translated
+	}
+	return format
+}
+
+// Sprintf formats a Korean format string in locale. Error arguments that
+// can be localized are rendered in locale too.
+func Sprintf(locale, format string, args ...any) string {
+	if translated, ok := catalogs[Normalize(locale)][format]; ok {
+		format = translated
+	}
+	for i, arg := range args {
+		if err, ok := arg.(error); ok {
+			args[i] = Message(locale, err)
+		}
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Localizer is implemented by errors that can render themselves in a
+// given locale.
+type Localizer interface {
+	Localize(locale string) string
+}
+
+// Error is an error whose message is kept as format and arguments so that
+// it can be rendered in any locale; Error() renders it in the locale set
+// with SetLocale, Korean by default.
+type Error struct {
+	Format string
+	Args   []any
+}
+
+// Errorf returns an *Error; it takes the same verbs as fmt.Errorf except
+// %w.
+func Errorf(format string, args ...any) error {
+	return &Error{Format: format, Args: args}
+}
+
+func (e *Error) Error() string {
+	return e.Localize(current)
+}
+
+func (e *Error) Localize(locale string) string {
+	return Sprintf(locale, e.Format, append([]any(nil), e.Args...)...)
+}
+
+// Message renders err in locale when it is a Localizer, and returns its
+// plain message otherwise. Wrapping hides the Localizer, so errors meant
+// for translation should carry their context in their own arguments.
+func Message(locale string, err error) string {
+	if l, ok := err.(Localizer); ok {
+		return l.Localize(locale)
+	}
+	return err.Error()
+}
@@ -0,0 +1,60 @@
+package calcut
+
+import (
+	"slices"
+	"time"
+)
+
+// agendaLookback is how long before an agenda's start an occurrence may
+// begin and still be on it, for multi-day events still running. Series
+// are expanded from this far back.
+const agendaLookback = 31 * 24 * time.Hour
+
+// AgendaItem is one occurrence on an agenda.
+type AgendaItem struct {
+	Event  Event
+	Start  time.Time
+	End    time.Time
+	AllDay bool
+}
+
+// Agenda lists the occurrences of events that overlap from through to
+// (exclusive), recurring events expanded, ordered by start with all-day
+// events first on their day. Cancelled occurrences are left out. Floating
+// and DATE values are taken in loc, and the times are given in it.
+func Agenda(events []Event, from, to time.Time, loc *time.Location) ([]AgendaItem, error) {
+	window := DateWindow{From: from.Add(-agendaLookback), To: to, Loc: loc}
+	instances, err := Expand(events, ExpandOptions{Window: window, MaxInstances: 1 << 20})
+	if err != nil {
+		return nil, err
+	}
+	var items []AgendaItem
+	for _, e := range instances {
+		if e.IsCancelled() {
+			continue
+		}
+		start, allDay, err := e.Start(loc)
+		if err != nil {
+			continue
+		}
+		end, err := e.End(loc)
+		if err != nil || end.Before(start) {
+			end = start
+		}
+		// An event taking no time is on the agenda if its start is.
+		if !start.Before(to) || !end.After(from) && !(end.Equal(start) && !start.Before(from)) {
+			continue
+		}
+		items = append(items, AgendaItem{Event: e, Start: start, End: end, AllDay: allDay})
+	}
+	slices.SortStableFunc(items, func(a, b AgendaItem) int {
+		if c := a.Start.Compare(b.Start); c != 0 || a.AllDay == b.AllDay {
+			return c
+		}
+		if a.AllDay {
+			return -1
+		}
+		return 1
+	})
+	return items, nil
+}
@@ -0,0 +1,119 @@
+package calcut
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// AlarmPolicy says how NormalizeAlarms rewrites the VALARMs of an event
+// for a calendar system that does not take every alarm as it is.
+type AlarmPolicy struct {
+	// Relative rewrites absolute triggers (VALUE=DATE-TIME) as offsets
+	// from DTSTART, which every importer reads.
+	Relative bool
+	// Drop lists the ACTIONs of alarms to remove, such as "EMAIL".
+	Drop []string
+	// Loc is the zone floating and all-day start times are taken in, as
+	// Event.Start does; nil means time.Local.
+	Loc *time.Location
+}
+
+// IsZero reports whether p leaves every alarm as it is.
+func (p AlarmPolicy) IsZero() bool {
+	return !p.Relative && len(p.Drop) == 0
+}
+
+// valarm is a VALARM block of an event, by physical line.
+type valarm struct {
+	begin, end int
+	action     string
+	trigger    *logicalLine
+}
+
+// NormalizeAlarms returns event with its VALARMs rewritten by p, and
+// reports whether any was. An absolute trigger of an event whose DTSTART
+// cannot be read is left as it is.
+func NormalizeAlarms(event Event, p AlarmPolicy) (Event, bool) {
+	if p.IsZero() {
+		return event, false
+	}
+	alarms := findAlarms(event.Text)
+	if len(alarms) == 0 {
+		return event, false
+	}
+	loc := p.Loc
+	if loc == nil {
+		loc = time.Local
+	}
+	lines := strings.Split(event.Text, "\n")
+	eol := ""
+	if strings.HasSuffix(lines[0], "\r") {
+		eol = "\r"
+	}
+	replaced := make(map[int]string)
+	drop := make(map[int]bool)
+	for _, a := range alarms {
+		if slices.Contains(p.Drop, a.action) {
+			for i := a.begin; i <= a.end; i++ {
+				drop[i] = true
+			}
+			continue
+		}
+		if !p.Relative || a.trigger == nil {
+			continue
+		}
+		_, value := splitContentLine(a.trigger.text)
+		if isDurationValue(value) {
+			continue
+		}
+		at, _, err := ParseDateTime(value, "", false, loc)
+		if err != nil {
+			continue
+		}
+		start, _, err := event.Start(loc)
+		if err != nil {
+			continue
+		}
+		replaced[a.trigger.first] = "TRIGGER:" + formatDuration(at.Sub(start)) + eol
+		for i := a.trigger.first + 1; i <= a.trigger.last; i++ {
+			drop[i] = true
+		}
+	}
+	if len(replaced) == 0 && len(drop) == 0 {
+		return event, false
+	}
+	out := lines[:0:0]
+	for i, line := range lines {
+		if r, ok := replaced[i]; ok {
+			out = append(out, r)
+		} else if !drop[i] {
+			out = append(out, line)
+		}
+	}
+	return NewEvent(strings.Join(out, "\n")), true
+}
+
+// findAlarms lists the VALARM blocks of an event's text, with their
+// upper-cased ACTION and their TRIGGER line.
+func findAlarms(text string) []valarm {
+	var alarms []valarm
+	var cur *valarm
+	for _, l := range logicalLines(strings.Split(text, "\n")) {
+		switch {
+		case strings.EqualFold(l.text, "BEGIN:VALARM"):
+			cur = &valarm{begin: l.first}
+		case cur == nil:
+		case strings.EqualFold(l.text, "END:VALARM"):
+			cur.end = l.last
+			alarms = append(alarms, *cur)
+			cur = nil
+		case PropertyName(l.text) == "ACTION":
+			_, value := splitContentLine(l.text)
+			cur.action = strings.ToUpper(value)
+		case PropertyName(l.text) == "TRIGGER":
+			cur.trigger = &l
+		}
+	}
+	return alarms
+}
@@ -0,0 +1,162 @@
+package calcut
+
+import (
+	"cmp"
+	"encoding/xml"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultAtomEntries is the number of events an Atom feed lists when
+// AtomOptions.Entries is not set.
+const DefaultAtomEntries = 20
+
+// DefaultAtomHorizon is how far ahead an Atom feed looks when
+// AtomOptions.Horizon is not set.
+const DefaultAtomHorizon = 365 * 24 * time.Hour
+
+// AtomOptions controls Atom.
+type AtomOptions struct {
+	// Title, ID and Author describe the feed; ID should stay the same
+	// between runs so readers recognize the feed. Link, when set, is the
+	// page the feed belongs to.
+	Title, ID, Author, Link string
+	// Entries caps the events listed; DefaultAtomEntries when zero.
+	Entries int
+	// Now is when "upcoming" starts, time.Now() when zero. Only events
+	// starting before Now plus Horizon are listed (DefaultAtomHorizon
+	// when zero).
+	Now     time.Time
+	Horizon time.Duration
+	// Loc is used for floating and DATE values and for the times shown
+	// in entries, time.Local if nil.
+	Loc *time.Location
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Link    *atomLink   `xml:"link,omitempty"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	Title   string    `xml:"title"`
+	ID      string    `xml:"id"`
+	Updated string    `xml:"updated"`
+	Link    *atomLink `xml:"link,omitempty"`
+	Summary string    `xml:"summary"`
+}
+
+// Atom renders the next upcoming events as an Atom feed (RFC 4287), for
+// pages that can show a feed but not a calendar. Recurring events are
+// expanded so each upcoming occurrence is an entry of its own, ordered by
+// start. Entry IDs combine the UID and start, so an occurrence keeps its
+// ID as the feed moves on.
+func Atom(events []Event, opts AtomOptions) ([]byte, error) {
+	loc := opts.Loc
+	if loc == nil {
+		loc = time.Local
+	}
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	limit := opts.Entries
+	if limit <= 0 {
+		limit = DefaultAtomEntries
+	}
+	horizon := opts.Horizon
+	if horizon <= 0 {
+		horizon = DefaultAtomHorizon
+	}
+	// The window bounds the expansion, so the instance cap is lifted for
+	// old series whose upcoming occurrences are far from DTSTART.
+	window := DateWindow{From: now, To: now.Add(horizon), Loc: loc}
+	upcoming, err := Expand(events, ExpandOptions{Window: window, MaxInstances: 1 << 20})
+	if err != nil {
+		return nil, err
+	}
+
+	type dated struct {
+		event  Event
+		start  time.Time
+		allDay bool
+	}
+	var list []dated
+	for _, e := range upcoming {
+		if start, allDay, err := e.Start(loc); err == nil {
+			list = append(list, dated{e, start, allDay})
+		}
+	}
+	slices.SortStableFunc(list, func(a, b dated) int { return cmp.Compare(a.start.UnixNano(), b.start.UnixNano()) })
+	if len(list) > limit {
+		list = list[:limit]
+	}
+
+	feed := atomFeed{
+		Title:   opts.Title,
+		ID:      opts.ID,
+		Updated: now.UTC().Format(time.RFC3339),
+		Author:  atomAuthor{Name: opts.Author},
+	}
+	if opts.Link != "" {
+		feed.Link = &atomLink{Href: opts.Link}
+	}
+	for _, d := range list {
+		feed.Entries = append(feed.Entries, atomEntryFor(d.event, d.start, d.allDay, now))
+	}
+	out, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(out, '\n')...), nil
+}
+
+// atomEntryFor renders one occurrence. Its summary tells when and where
+// it takes place, followed by the description.
+func atomEntryFor(e Event, start time.Time, allDay bool, now time.Time) atomEntry {
+	d := e.Details()
+	when := start.Format("2006-01-02 15:04 MST")
+	if allDay {
+		when = start.Format("2006-01-02")
+	}
+	lines := []string{when}
+	if loc := UnescapeText(d.Location.Value); loc != "" {
+		lines = append(lines, loc)
+	}
+	if desc := UnescapeText(d.Description.Value); desc != "" {
+		lines = append(lines, "", desc)
+	}
+
+	updated := now
+	for _, name := range []string{"LAST-MODIFIED", "DTSTAMP"} {
+		if t, _, err := ParseDateTime(ExtractProperty(e.Text, name), "", false, time.UTC); err == nil {
+			updated = t
+			break
+		}
+	}
+	entry := atomEntry{
+		Title:   UnescapeText(e.Summary),
+		ID:      "urn:ical-uid:" + url.PathEscape(e.UID) + ":" + start.UTC().Format("20060102T150405Z"),
+		Updated: updated.UTC().Format(time.RFC3339),
+		Summary: strings.Join(lines, "\n"),
+	}
+	if u := ExtractProperty(e.Text, "URL"); u != "" {
+		entry.Link = &atomLink{Href: u}
+	}
+	return entry
+}
@@ -0,0 +1,159 @@
+package calcut
+
+import (
+	"slices"
+	"strings"
+)
+
+// BuildICS assembles a complete VCALENDAR from header lines, timezone
+// blocks and event blocks, with CRLF line endings as RFC 5545 requires.
+// Lines over 75 octets, as rewriting properties can leave them, are folded
+// on the way. Timezones are written in TZID order, so that the same zones
+// always come out the same whatever order the events named them in.
+func BuildICS(headerLines, timezones []string, eventTexts []string) string {
+	return buildICS(headerLines, timezones, eventTexts, "\r\n")
+}
+
+// buildICS is BuildICS ending lines with eol.
+func buildICS(headerLines, timezones []string, eventTexts []string, eol string) string {
+	var b strings.Builder
+	b.WriteString("BEGIN:VCALENDAR" + eol)
+	for _, h := range headerLines {
+		b.WriteString(encode(h, eol))
+		b.WriteString(eol)
+	}
+	for _, tz := range sortTimezones(timezones) {
+		b.WriteString(encode(tz, eol))
+		b.WriteString(eol)
+	}
+	for _, ev := range eventTexts {
+		b.WriteString(encode(ev, eol))
+		b.WriteString(eol)
+	}
+	b.WriteString("END:VCALENDAR" + eol)
+	return b.String()
+}
+
+// sortTimezones returns timezones ordered by TZID, those without one
+// last. The slice is only copied when it is out of order.
+func sortTimezones(timezones []string) []string {
+	if len(timezones) < 2 {
+		return timezones
+	}
+	type zone struct{ id, block string }
+	zones := make([]zone, len(timezones))
+	for i, block := range timezones {
+		zones[i] = zone{ExtractProperty(block, "TZID"), block}
+	}
+	byID := func(a, b zone) int {
+		if (a.id == "") != (b.id == "") {
+			if a.id == "" {
+				return 1
+			}
+			return -1
+		}
+		return strings.Compare(a.id, b.id)
+	}
+	if slices.IsSortedFunc(zones, byID) {
+		return timezones
+	}
+	slices.SortStableFunc(zones, byID)
+	sorted := make([]string, len(zones))
+	for i, z := range zones {
+		sorted[i] = z.block
+	}
+	return sorted
+}
+
+// zoneHeader splits header into the lines to write before the timezones
+// and the X- properties to write after them because they name one of
+// them, such as X-WR-TIMEZONE, by value or TZID parameter.
+func zoneHeader(header, timezones []string) (early, late []string) {
+	ids := make(map[string]bool)
+	for _, block := range timezones {
+		if id := ExtractProperty(block, "TZID"); id != "" {
+			ids[id] = true
+		}
+	}
+	for _, line := range header {
+		unfolded := Unfold(line)
+		params, value := splitContentLine(unfolded)
+		if strings.HasPrefix(PropertyName(unfolded), "X-") && (ids[value] || ids[params["TZID"]]) {
+			late = append(late, line)
+		} else {
+			early = append(early, line)
+		}
+	}
+	return early, late
+}
+
+// SkeletonSize is the size of a calendar with the given header and
+// timezones but no events, i.e. the fixed overhead of every output file.
+func SkeletonSize(headerLines, timezones []string) int {
+	return len(BuildICS(headerLines, timezones, nil))
+}
+
+// lineEnding returns the line ending p is written with.
+func (p ParsedCalendar) lineEnding() string {
+	if p.LineEnding == "" {
+		return "\r\n"
+	}
+	return p.LineEnding
+}
+
+// Build renders a calendar containing the given events with p's header and
+// timezones.
+func (p ParsedCalendar) Build(events []Event) string {
+	texts := make([]string, 0, len(events))
+	header := p.HeaderLines
+	if p.TimezonesFirst {
+		// The late header lines go where buildICS puts the events.
+		header, texts = zoneHeader(header, p.Timezones)
+	}
+	for _, event := range events {
+		texts = append(texts, event.Text)
+	}
+	return buildICS(header, p.Timezones, texts, p.lineEnding())
+}
+
+// BuildChunk renders a planned chunk: p's header, the chunk's timezones
+// and its events.
+func (p ParsedCalendar) BuildChunk(c Chunk) string {
+	p.Timezones = c.Timezones
+	return p.Build(c.Events)
+}
+
+// BuildChunkEnds renders a planned chunk as BuildChunk does but without
+// its events: what comes before them and what after. A caller that keeps
+// the events elsewhere, such as in a file, writes head, then the events
+// as BuildFragment renders them, then tail.
+func (p ParsedCalendar) BuildChunkEnds(c Chunk) (head, tail string) {
+	tail = "END:VCALENDAR" + p.lineEnding()
+	p.Timezones = c.Timezones
+	return strings.TrimSuffix(p.Build(nil), tail), tail
+}
+
+// BuildFragment renders events without the calendar around them: what
+// Build writes between the timezones and END:VCALENDAR. Files written this
+// way can share one skeleton, a calendar without events, instead of each
+// repeating its header and timezones; Assemble puts a fragment and the
+// skeleton back together.
+func (p ParsedCalendar) BuildFragment(events []Event) string {
+	eol := p.lineEnding()
+	var b strings.Builder
+	for _, event := range events {
+		b.WriteString(encode(event.Text, eol))
+		b.WriteString(eol)
+	}
+	return b.String()
+}
+
+// Assemble inserts fragment, as BuildFragment renders it, into skeleton
+// before its END:VCALENDAR.
+func Assemble(skeleton, fragment string) string {
+	i := strings.LastIndex(strings.ToUpper(skeleton), "END:VCALENDAR")
+	if i < 0 {
+		return skeleton + fragment
+	}
+	return skeleton[:i] + fragment + skeleton[i:]
+}
@@ -0,0 +1,211 @@
+package calcut
+
+import (
+	"io"
+	"mime/quotedprintable"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/korean"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+)
+
+// charsets are the CHARSET parameter values of old exports, such as the
+// vCalendar 1.0 files of Outlook, that are not UTF-8. A nil encoding is
+// UTF-8 or a subset of it.
+var charsets = map[string]encoding.Encoding{
+	"UTF-8":          nil,
+	"US-ASCII":       nil,
+	"CP949":          korean.EUCKR,
+	"MS949":          korean.EUCKR,
+	"WINDOWS-949":    korean.EUCKR,
+	"EUC-KR":         korean.EUCKR,
+	"KS_C_5601-1987": korean.EUCKR,
+	"ISO-8859-1":     charmap.ISO8859_1,
+	"LATIN1":         charmap.ISO8859_1,
+	"WINDOWS-1252":   charmap.Windows1252,
+	"CP1252":         charmap.Windows1252,
+}
+
+// LegacyValue is the value of a content line decoded from the legacy
+// encodings RFC 5545 no longer has: the ENCODING=QUOTED-PRINTABLE and
+// CHARSET parameters of vCalendar 1.0, or raw bytes of another charset.
+type LegacyValue struct {
+	// Value is the decoded value, in UTF-8.
+	Value string
+	// Encoding describes what was decoded, such as
+	// "QUOTED-PRINTABLE, CP949", or is "" for a plain UTF-8 value.
+	Encoding string
+}
+
+// DecodeLegacyValue decodes the value of an unfolded content line. Values
+// that are not UTF-8 and declare no CHARSET are read as CP949 when they
+// decode cleanly as such, otherwise as Windows-1252.
+func DecodeLegacyValue(line string) (LegacyValue, error) {
+	params, value := splitContentLine(line)
+	var used []string
+	data := []byte(value)
+	if enc := strings.ToUpper(params["ENCODING"]); enc == "QUOTED-PRINTABLE" {
+		decoded, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(value)))
+		if err != nil {
+			return LegacyValue{}, i18n.Errorf("quoted-printable 값을 디코딩할 수 없습니다: %s", err)
+		}
+		data = decoded
+		used = append(used, enc)
+	}
+	name := strings.ToUpper(params["CHARSET"])
+	charset, known := charsets[name]
+	switch {
+	case name != "" && !known:
+		return LegacyValue{}, i18n.Errorf("알 수 없는 문자 집합: %s", params["CHARSET"])
+	case name == "" && !utf8.Valid(data):
+		name, charset = "WINDOWS-1252", charmap.Windows1252
+		if decoded, err := korean.EUCKR.NewDecoder().Bytes(data); err == nil && !strings.ContainsRune(string(decoded), utf8.RuneError) {
+			name, charset = "CP949", korean.EUCKR
+		}
+	}
+	if charset != nil {
+		decoded, err := charset.NewDecoder().Bytes(data)
+		if err != nil {
+			return LegacyValue{}, i18n.Errorf("%s 값을 디코딩할 수 없습니다: %s", name, err)
+		}
+		data = decoded
+		used = append(used, name)
+	}
+	return LegacyValue{Value: string(data), Encoding: strings.Join(used, ", ")}, nil
+}
+
+// isLegacyLine tells cheaply whether an unfolded content line may need
+// DecodeLegacyValue.
+func isLegacyLine(line string) bool {
+	head, _, _ := strings.Cut(line, ":")
+	head = strings.ToUpper(head)
+	return strings.Contains(head, ";ENCODING=") || strings.Contains(head, ";CHARSET=") || !utf8.ValidString(line)
+}
+
+// isSoftBreak tells whether a physical line of a quoted-printable value
+// ends in a soft line break, which continues the value on the next line
+// without the leading space of RFC 5545 folding.
+func isSoftBreak(line string) bool {
+	line = strings.TrimRight(line, "\r")
+	if !strings.HasSuffix(line, "=") {
+		return false
+	}
+	head, _, _ := strings.Cut(line, ":")
+	return strings.Contains(strings.ToUpper(head), "QUOTED-PRINTABLE")
+}
+
+// legacySummary returns the decoded SUMMARY of a component whose SUMMARY
+// line is legacy-encoded, and ok == false otherwise.
+func legacySummary(text string) (summary string, ok bool) {
+	seen := false
+	forEachTopLevelLine(text, func(l logicalLine) {
+		if seen || PropertyName(l.text) != "SUMMARY" {
+			return
+		}
+		seen = true
+		if !isLegacyLine(l.text) {
+			return
+		}
+		if v, err := DecodeLegacyValue(l.text); err == nil {
+			summary, ok = legacyText(v.Value), true
+		}
+	})
+	return summary, ok
+}
+
+// legacyText writes a decoded vCalendar 1.0 value as an RFC 5545 TEXT
+// value: line breaks become "\n" and the separators are escaped, while
+// the backslash escapes vCalendar already had are kept.
+func legacyText(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\':
+			b.WriteByte(c)
+			if i+1 < len(s) {
+				i++
+				b.WriteByte(s[i])
+			}
+		case '\r':
+		case '\n':
+			b.WriteString(`\n`)
+		case ',', ';':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// RecodeEvent rewrites the legacy-encoded values of event, in nested
+// components too, as plain UTF-8 TEXT without the ENCODING and CHARSET
+// parameters, and reports whether any was. Values that do not decode are
+// left as they are.
+func RecodeEvent(event Event) (Event, bool) {
+	lines := strings.Split(event.Text, "\n")
+	replaced := make(map[int]string)
+	drop := make(map[int]bool)
+	forEachLine(event.Text, func(l logicalLine, depth int) {
+		if !isLegacyLine(l.text) {
+			return
+		}
+		v, err := DecodeLegacyValue(l.text)
+		if err != nil || v.Encoding == "" {
+			return
+		}
+		replaced[l.first] = withoutParams(l.text, "ENCODING", "CHARSET") + legacyText(v.Value)
+		for i := l.first + 1; i <= l.last; i++ {
+			drop[i] = true
+		}
+	})
+	if len(replaced) == 0 {
+		return event, false
+	}
+	out := lines[:0:0]
+	for i, line := range lines {
+		if r, ok := replaced[i]; ok {
+			out = append(out, r)
+		} else if !drop[i] {
+			out = append(out, line)
+		}
+	}
+	return NewEvent(strings.Join(out, "\n")), true
+}
+
+// withoutParams returns the name and parameters of a content line, up to
+// and including the colon before its value, without the parameters
+// named. Quoted parameter values are kept as they are.
+func withoutParams(line string, names ...string) string {
+	var b strings.Builder
+	inQuotes := false
+	start := 0
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '"':
+			inQuotes = !inQuotes
+		case inQuotes:
+		case c == ';' || c == ':':
+			part := line[start:i]
+			name, _, _ := strings.Cut(part, "=")
+			if start == 0 || !slices.ContainsFunc(names, func(n string) bool { return strings.EqualFold(n, name) }) {
+				if start > 0 {
+					b.WriteByte(';')
+				}
+				b.WriteString(part)
+			}
+			if c == ':' {
+				b.WriteByte(':')
+				return b.String()
+			}
+			start = i + 1
+		}
+	}
+	return line
+}
@@ -0,0 +1,59 @@
+package calcut
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// ChecksumProperty holds the SHA-256 of an event as it was written, so
+// that audits of an archive can tell whether an event has been modified
+// since.
+const ChecksumProperty = "X-CALCUT-SHA256"
+
+// ChecksumStatus is what VerifyChecksum finds.
+type ChecksumStatus string
+
+const (
+	ChecksumValid    ChecksumStatus = "valid"
+	ChecksumModified ChecksumStatus = "modified"
+	ChecksumMissing  ChecksumStatus = "missing"
+)
+
+// EventChecksum returns the SHA-256, in hex, of event's text without its
+// ChecksumProperty. The text is unfolded first and line endings do not
+// count, so an archive re-folded or converted to CRLF by another program
+// still verifies.
+func EventChecksum(event Event) string {
+	text := Unfold(RemoveProperty(event.Text, ChecksumProperty))
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// StampChecksum returns event with its ChecksumProperty set to its
+// current checksum, replacing any earlier one.
+func StampChecksum(event Event) Event {
+	return NewEvent(SetProperty(event.Text, ChecksumProperty, EventChecksum(event)))
+}
+
+// StampChecksums stamps every event, as StampChecksum does.
+func StampChecksums(events []Event) []Event {
+	out := make([]Event, len(events))
+	for i, event := range events {
+		out[i] = StampChecksum(event)
+	}
+	return out
+}
+
+// VerifyChecksum compares event with the checksum it was stamped with.
+func VerifyChecksum(event Event) ChecksumStatus {
+	want := ExtractProperty(event.Text, ChecksumProperty)
+	switch {
+	case want == "":
+		return ChecksumMissing
+	case strings.EqualFold(want, EventChecksum(event)):
+		return ChecksumValid
+	}
+	return ChecksumModified
+}
@@ -0,0 +1,156 @@
+package calcut
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+)
+
+// Color is a calendar or event color. Name is a CSS3 color name, the only
+// form the COLOR property accepts (RFC 7986 §5.9), and may be empty when
+// the color was given as hex only; Hex is "#RRGGBB", the form Apple's
+// X-APPLE-CALENDAR-COLOR uses.
+type Color struct {
+	Name string
+	Hex  string
+}
+
+// Palette is a set of clearly distinct colors handed out to chunks in
+// turn.
+var Palette = []Color{
+	{"tomato", "#FF6347"},
+	{"dodgerblue", "#1E90FF"},
+	{"mediumseagreen", "#3CB371"},
+	{"orange", "#FFA500"},
+	{"mediumpurple", "#9370DB"},
+	{"gold", "#FFD700"},
+	{"deeppink", "#FF1493"},
+	{"darkcyan", "#008B8B"},
+	{"sienna", "#A0522D"},
+	{"slategray", "#708090"},
+	{"yellowgreen", "#9ACD32"},
+	{"crimson", "#DC143C"},
+}
+
+// basicColors are the CSS basic color keywords, accepted by name in
+// addition to the Palette.
+var basicColors = []Color{
+	{"black", "#000000"}, {"silver", "#C0C0C0"}, {"gray", "#808080"}, {"white", "#FFFFFF"},
+	{"maroon", "#800000"}, {"red", "#FF0000"}, {"purple", "#800080"}, {"fuchsia", "#FF00FF"},
+	{"green", "#008000"}, {"lime", "#00FF00"}, {"olive", "#808000"}, {"yellow", "#FFFF00"},
+	{"navy", "#000080"}, {"blue", "#0000FF"}, {"teal", "#008080"}, {"aqua", "#00FFFF"},
+}
+
+var hexColor = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// ParseColor reads a color given by name (the Palette or a CSS basic
+// color) or as #RRGGBB. A hex value of a known color gets its name too.
+func ParseColor(s string) (Color, error) {
+	s = strings.TrimSpace(s)
+	known := append(append([]Color(nil), Palette...), basicColors...)
+	if hexColor.MatchString(s) {
+		hex := strings.ToUpper(s)
+		for _, c := range known {
+			if c.Hex == hex {
+				return c, nil
+			}
+		}
+		return Color{Hex: hex}, nil
+	}
+	for _, c := range known {
+		if strings.EqualFold(c.Name, s) {
+			return c, nil
+		}
+	}
+	return Color{}, i18n.Errorf("알 수 없는 색: %s (#RRGGBB 또는 CSS 색 이름)", s)
+}
+
+// ParseColors reads a comma-separated list of colors, or "auto" for the
+// Palette.
+func ParseColors(list string) ([]Color, error) {
+	if list == "" {
+		return nil, nil
+	}
+	if strings.EqualFold(list, "auto") {
+		return Palette, nil
+	}
+	var colors []Color
+	for _, s := range strings.Split(list, ",") {
+		c, err := ParseColor(s)
+		if err != nil {
+			return nil, err
+		}
+		colors = append(colors, c)
+	}
+	return colors, nil
+}
+
+// Color returns the calendar color of p from its COLOR or
+// X-APPLE-CALENDAR-COLOR property; a zero Color if it has neither.
+func (p ParsedCalendar) Color() Color {
+	var c Color
+	for _, line := range p.HeaderLines {
+		line = strings.TrimSpace(Unfold(line))
+		_, value := splitContentLine(line)
+		switch PropertyName(line) {
+		case "COLOR":
+			c.Name = value
+		case "X-APPLE-CALENDAR-COLOR":
+			// Apple writes #RRGGBBAA; the alpha is dropped.
+			if len(value) == 9 {
+				value = value[:7]
+			}
+			c.Hex = strings.ToUpper(value)
+		}
+	}
+	return c
+}
+
+// Color returns the COLOR property of e (RFC 7986 §5.9), or "".
+func (e Event) Color() string {
+	for _, p := range TopLevelProperties(e.Text) {
+		if p.Name == "COLOR" {
+			return p.Value
+		}
+	}
+	return ""
+}
+
+// WithColor returns a copy of p whose calendar color is c: COLOR and
+// X-APPLE-CALENDAR-COLOR are replaced or added. Events keep their own
+// COLOR properties.
+func (p ParsedCalendar) WithColor(c Color) ParsedCalendar {
+	header := append([]string(nil), p.HeaderLines...)
+	if c.Name != "" {
+		header = setHeaderProperty(header, "COLOR", c.Name)
+	}
+	if c.Hex != "" {
+		header = setHeaderProperty(header, "X-APPLE-CALENDAR-COLOR", c.Hex)
+	}
+	p.HeaderLines = header
+	return p
+}
+
+// HeaderSize is the number of bytes WithColor(c) can add to a calendar.
+func (c Color) HeaderSize() int64 {
+	var n int64
+	if c.Name != "" {
+		n += int64(len("COLOR:")+len(c.Name)) + 1
+	}
+	if c.Hex != "" {
+		n += int64(len("X-APPLE-CALENDAR-COLOR:")+len(c.Hex)) + 1
+	}
+	return n
+}
+
+func setHeaderProperty(header []string, name, value string) []string {
+	line := name + ":" + value
+	for i, h := range header {
+		if PropertyName(strings.TrimSpace(h)) == name {
+			header[i] = line
+			return header
+		}
+	}
+	return append(header, line)
+}
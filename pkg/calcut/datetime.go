@@ -0,0 +1,152 @@
+package calcut
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+)
+
+// Start parses the event's DTSTART. allDay reports a DATE value (RFC 5545
+// §3.3.4), which like a floating time (§3.3.5, no zone at all) is taken
+// as written in loc. Times with a TZID are resolved through the IANA
+// database and converted to loc; a TZID it does not know, such as a
+// Windows zone name, is treated as floating.
+func (e Event) Start(loc *time.Location) (t time.Time, allDay bool, err error) {
+	var params map[string]string
+	var value string
+	found := false
+	forEachTopLevelLine(e.Text, func(l logicalLine) {
+		if found || PropertyName(l.text) != "DTSTART" {
+			return
+		}
+		found = true
+		params, value = splitContentLine(l.text)
+	})
+	if !found {
+		return time.Time{}, false, i18n.Errorf("DTSTART가 없습니다")
+	}
+	return ParseDateTime(value, params["TZID"], params["VALUE"] == "DATE", loc)
+}
+
+// End returns when e ends: its DTEND or DUE, else DTSTART plus DURATION.
+// Without any of them an all-day event lasts the day and others take no
+// time (RFC 5545 §3.6.1).
+func (e Event) End(loc *time.Location) (time.Time, error) {
+	start, allDay, err := e.Start(loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	d := e.Details()
+	for _, p := range []PropertyValue{d.End, d.Due} {
+		if p.Value != "" {
+			end, _, err := ParseDateTime(p.Value, p.Params["TZID"], p.Params["VALUE"] == "DATE", loc)
+			return end, err
+		}
+	}
+	if d.Duration.Value != "" {
+		dur, err := ParseDuration(d.Duration.Value)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return start.Add(dur), nil
+	}
+	if allDay {
+		return start.AddDate(0, 0, 1), nil
+	}
+	return start, nil
+}
+
+// ParseDateTime parses a DATE or DATE-TIME value as Event.Start does. date
+// forces the DATE form, as VALUE=DATE does; 8-digit values are dates
+// either way.
+func ParseDateTime(value, tzid string, date bool, loc *time.Location) (t time.Time, allDay bool, err error) {
+	value = strings.TrimSpace(value)
+	if date || len(value) == 8 {
+		t, err = time.ParseInLocation("20060102", value, loc)
+		if err != nil {
+			return time.Time{}, false, i18n.Errorf("잘못된 날짜: %s", value)
+		}
+		return t, true, nil
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err = time.Parse("20060102T150405Z", value)
+		if err != nil {
+			return time.Time{}, false, i18n.Errorf("잘못된 날짜/시각: %s", value)
+		}
+		return t.In(loc), false, nil
+	}
+
+	zone := loc
+	if tzid != "" {
+		if z := loadTZID(tzid); z != nil {
+			zone = z
+		}
+	}
+	t, err = time.ParseInLocation("20060102T150405", value, zone)
+	if err != nil {
+		return time.Time{}, false, i18n.Errorf("잘못된 날짜/시각: %s", value)
+	}
+	return t.In(loc), false, nil
+}
+
+// loadTZID resolves a TZID parameter to a location, or returns nil. Some
+// producers prefix IANA names with a path of their own (e.g.
+// "/mozilla.org/20050126_1/Europe/Berlin"), so trailing parts of the name
+// are tried as well.
+func loadTZID(tzid string) *time.Location {
+	name := strings.Trim(tzid, `"`)
+	for {
+		if loc, err := time.LoadLocation(name); err == nil && name != "" && name != "Local" {
+			return loc
+		}
+		i := strings.Index(name, "/")
+		if i < 0 {
+			return nil
+		}
+		name = name[i+1:]
+	}
+}
+
+// splitContentLine splits an unfolded content line into its parameters
+// (names upper-cased, surrounding quotes removed) and its value. Colons
+// and semicolons inside quoted parameter values do not count.
+func splitContentLine(line string) (params map[string]string, value string) {
+	params = make(map[string]string)
+	inQuotes := false
+	start := -1
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '"':
+			inQuotes = !inQuotes
+		case inQuotes:
+		case c == ';' || c == ':':
+			if start >= 0 {
+				k, v, _ := strings.Cut(line[start:i], "=")
+				params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+			}
+			if c == ':' {
+				return params, strings.TrimSpace(line[i+1:])
+			}
+			start = i + 1
+		}
+	}
+	return params, ""
+}
+
+// PeriodKey names the calendar period containing t: "2024" for "year",
+// "2024-03" for "month", and the ISO 8601 week "2024-W09" for "week".
+func PeriodKey(t time.Time, by string) (string, error) {
+	switch by {
+	case "year":
+		return t.Format("2006"), nil
+	case "month":
+		return t.Format("2006-01"), nil
+	case "week":
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%04d-W%02d", year, week), nil
+	}
+	return "", i18n.Errorf("알 수 없는 기간: %s (year, month, week 중 하나)", by)
+}
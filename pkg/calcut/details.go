@@ -0,0 +1,183 @@
+package calcut
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+)
+
+// PropertyValue is the value of a content line with its parameters (names
+// upper-cased, quotes removed). TEXT values are kept as written, escapes
+// included.
+type PropertyValue struct {
+	Value  string            `json:"value"`
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// Details holds the commonly used properties of a component, parsed once
+// so that callers do not each pick them out of Text. A property the
+// component does not have is left zero.
+type Details struct {
+	Start       PropertyValue   `json:"dtstart"`
+	End         PropertyValue   `json:"dtend"`
+	Due         PropertyValue   `json:"due"`
+	Duration    PropertyValue   `json:"duration"`
+	Location    PropertyValue   `json:"location"`
+	Description PropertyValue   `json:"description"`
+	Organizer   PropertyValue   `json:"organizer"`
+	Attendees   []PropertyValue `json:"attendees,omitempty"`
+	Categories  []string        `json:"categories,omitempty"`
+	RRule       PropertyValue   `json:"rrule"`
+	Status      PropertyValue   `json:"status"`
+}
+
+// Details parses the top-level properties of e. For properties that may
+// only appear once the first occurrence wins; every ATTENDEE is kept, and
+// the values of all CATEGORIES lines are combined.
+func (e Event) Details() Details {
+	var d Details
+	single := map[string]*PropertyValue{
+		"DTSTART":     &d.Start,
+		"DTEND":       &d.End,
+		"DUE":         &d.Due,
+		"DURATION":    &d.Duration,
+		"LOCATION":    &d.Location,
+		"DESCRIPTION": &d.Description,
+		"ORGANIZER":   &d.Organizer,
+		"RRULE":       &d.RRule,
+		"STATUS":      &d.Status,
+	}
+	forEachTopLevelLine(e.Text, func(l logicalLine) {
+		name := PropertyName(l.text)
+		params, value := splitContentLine(l.text)
+		if len(params) == 0 {
+			params = nil
+		}
+		v := PropertyValue{Value: value, Params: params}
+		switch name {
+		case "ATTENDEE":
+			d.Attendees = append(d.Attendees, v)
+		case "CATEGORIES":
+			d.Categories = append(d.Categories, splitTextList(value)...)
+		default:
+			if p := single[name]; p != nil && p.Value == "" {
+				*p = v
+			}
+		}
+	})
+	return d
+}
+
+// splitTextList splits a comma-separated list of TEXT values, leaving
+// escaped commas ("\,") inside their value. Empty items are dropped.
+func splitTextList(value string) []string {
+	var items []string
+	start := 0
+	add := func(end int) {
+		if item := strings.TrimSpace(value[start:end]); item != "" {
+			items = append(items, item)
+		}
+		start = end + 1
+	}
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case ',':
+			add(i)
+		}
+	}
+	if start < len(value) {
+		add(len(value))
+	}
+	return items
+}
+
+// durationUnits are the designators of a DURATION value before and after
+// its "T".
+var (
+	dateUnits = map[rune]time.Duration{'W': 7 * 24 * time.Hour, 'D': 24 * time.Hour}
+	timeUnits = map[rune]time.Duration{'H': time.Hour, 'M': time.Minute, 'S': time.Second}
+)
+
+// ParseDuration parses a DURATION value (RFC 5545 §3.3.6) such as "PT1H30M",
+// "P1D" or "-P2W". Days and weeks count as 24 hours and 7 days.
+func ParseDuration(s string) (time.Duration, error) {
+	v := strings.ToUpper(strings.TrimSpace(s))
+	sign := time.Duration(1)
+	switch {
+	case strings.HasPrefix(v, "-"):
+		sign = -1
+		v = v[1:]
+	case strings.HasPrefix(v, "+"):
+		v = v[1:]
+	}
+	if !strings.HasPrefix(v, "P") || len(v) < 3 {
+		return 0, i18n.Errorf("잘못된 기간 값: %s (예: PT1H30M)", s)
+	}
+	v = v[1:]
+
+	var d time.Duration
+	inTime := false
+	digits := ""
+	for _, c := range v {
+		switch {
+		case c >= '0' && c <= '9':
+			digits += string(c)
+			continue
+		case c == 'T' && !inTime && digits == "":
+			inTime = true
+			continue
+		}
+		n, err := strconv.Atoi(digits)
+		if err != nil {
+			return 0, i18n.Errorf("잘못된 기간 값: %s (예: PT1H30M)", s)
+		}
+		digits = ""
+		units := dateUnits
+		if inTime {
+			units = timeUnits
+		}
+		u, ok := units[c]
+		if !ok {
+			return 0, i18n.Errorf("잘못된 기간 값: %s (예: PT1H30M)", s)
+		}
+		d += time.Duration(n) * u
+	}
+	if digits != "" || strings.HasSuffix(v, "T") {
+		return 0, i18n.Errorf("잘못된 기간 값: %s (예: PT1H30M)", s)
+	}
+	return sign * d, nil
+}
+
+// formatDuration writes d as a DURATION value, the inverse of
+// ParseDuration: "-PT15M", "P1D", "P1DT2H". Fractions of a second are
+// dropped.
+func formatDuration(d time.Duration) string {
+	var b strings.Builder
+	if d < 0 {
+		b.WriteByte('-')
+		d = -d
+	}
+	b.WriteByte('P')
+	if days := d / (24 * time.Hour); days > 0 {
+		b.WriteString(strconv.Itoa(int(days)) + "D")
+		d -= days * 24 * time.Hour
+	}
+	if d >= time.Second || b.Len() <= 2 {
+		b.WriteByte('T')
+		h, m, s := d/time.Hour, d%time.Hour/time.Minute, d%time.Minute/time.Second
+		if h > 0 {
+			b.WriteString(strconv.Itoa(int(h)) + "H")
+		}
+		if m > 0 {
+			b.WriteString(strconv.Itoa(int(m)) + "M")
+		}
+		if s > 0 || h == 0 && m == 0 {
+			b.WriteString(strconv.Itoa(int(s)) + "S")
+		}
+	}
+	return b.String()
+}
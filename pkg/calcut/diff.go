@@ -0,0 +1,110 @@
+package calcut
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EventChange is an event found in both calendars of a Diff with different
+// content.
+type EventChange struct {
+	Old, New Event
+}
+
+// CalendarDiff is what changed between two versions of a calendar.
+type CalendarDiff struct {
+	Added     []Event
+	Removed   []Event
+	Changed   []EventChange
+	Unchanged int
+}
+
+// Diff compares two versions of a calendar. Events are matched by UID
+// and RECURRENCE-ID, so each override of a series is compared on its own;
+// events without a UID only match an identical copy. A matched event has
+// changed when anything but its DTSTAMP differs, which includes a new
+// SEQUENCE or LAST-MODIFIED. Results follow the order of old for removed
+// events and of new for the others.
+func Diff(old, new []Event) CalendarDiff {
+	type instance struct{ uid, recurrenceID string }
+	key := func(e Event) instance {
+		if e.UID == "" {
+			return instance{"\x00" + diffText(e), ""}
+		}
+		return instance{e.UID, ExtractProperty(e.Text, "RECURRENCE-ID")}
+	}
+	before := make(map[instance]Event, len(old))
+	for _, e := range old {
+		before[key(e)] = e
+	}
+	var d CalendarDiff
+	seen := make(map[instance]bool, len(new))
+	for _, e := range new {
+		k := key(e)
+		seen[k] = true
+		prev, ok := before[k]
+		switch {
+		case !ok:
+			d.Added = append(d.Added, e)
+		case diffText(prev) != diffText(e):
+			d.Changed = append(d.Changed, EventChange{Old: prev, New: e})
+		default:
+			d.Unchanged++
+		}
+	}
+	for _, e := range old {
+		if !seen[key(e)] {
+			d.Removed = append(d.Removed, e)
+		}
+	}
+	return d
+}
+
+// diffText is the text of e as Diff compares it: unfolded, without line
+// ending differences and without DTSTAMP, which exporters rewrite on
+// every export.
+func diffText(e Event) string {
+	text := Unfold(strings.ReplaceAll(RemoveProperty(e.Text, "DTSTAMP"), "\r\n", "\n"))
+	return strings.TrimSpace(text)
+}
+
+// Older reports whether the new version is an older revision than the
+// old one, ranked as Dedupe ranks copies, as when the calendars were
+// given in the wrong order or an export is stale.
+func (c EventChange) Older() bool {
+	return newerRevision(c.Old, c.New)
+}
+
+// Sequence returns the SEQUENCE of e, 0 when it has none.
+func (e Event) Sequence() int {
+	n, _ := strconv.Atoi(ExtractProperty(e.Text, "SEQUENCE"))
+	return n
+}
+
+// WithMethod returns a copy of p whose METHOD (RFC 5546 §1.4) is method,
+// turning the calendar into a scheduling message such as REQUEST or
+// CANCEL.
+func (p ParsedCalendar) WithMethod(method string) ParsedCalendar {
+	p.HeaderLines = setHeaderProperty(append([]string(nil), p.HeaderLines...), "METHOD", method)
+	return p
+}
+
+// Reissue returns c.New ready to send as a METHOD:REQUEST update: its
+// SEQUENCE is raised above that of c.Old when the change did not, since
+// recipients ignore updates that do not raise it.
+func (c EventChange) Reissue() Event {
+	if c.New.Sequence() > c.Old.Sequence() {
+		return c.New
+	}
+	return NewEvent(SetProperty(c.New.Text, "SEQUENCE", strconv.Itoa(c.Old.Sequence()+1)))
+}
+
+// Cancellation returns e as a METHOD:CANCEL message cancels it: with
+// STATUS:CANCELLED, a raised SEQUENCE and a DTSTAMP of now.
+func (e Event) Cancellation(now time.Time) Event {
+	text := SetProperty(e.Text, "STATUS", "CANCELLED")
+	text = SetProperty(text, "SEQUENCE", strconv.Itoa(e.Sequence()+1))
+	text = SetProperty(text, "DTSTAMP", now.UTC().Format("20060102T150405Z"))
+	return NewEvent(text)
+}
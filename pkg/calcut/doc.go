@@ -0,0 +1,33 @@
+// Package calcut parses iCalendar (.ics) files and splits them into
+// smaller calendars, either one event per file or in chunks below a byte
+// limit. It is the shared core of the calcut CLI and the WebAssembly
+// module behind the web app.
+//
+// A typical use parses a calendar and renders the planned chunks:
+//
+//	parsed, err := calcut.ParseBytes(data, calcut.DefaultParseLimits())
+//	if err != nil {
+//		return err
+//	}
+//	for _, r := range calcut.SplitBySize(parsed, calcut.SplitOptions{MaxBytes: 1 << 20, Contiguous: true}) {
+//		os.WriteFile(r.Filename, []byte(r.Content), 0644)
+//	}
+//
+// Nothing in this package modifies a ParsedCalendar or its slices after it
+// has been built; helpers such as SortByStart return new slices. A parsed
+// calendar can therefore be shared between goroutines, and all functions
+// are safe for concurrent use.
+//
+// The exported tables ComponentKinds, Palette, LinkProperties,
+// ConferenceProperties, NamePlaceholders and SizeBuckets are read by the
+// package's own functions and must not be modified: they are exported
+// for reading, such as to list the accepted values in a usage message.
+//
+// The one global its results depend on is the message locale of the
+// calcut programs: the errors this package returns keep their message as
+// a format and arguments and render it when Error is called, in the locale
+// the program has set (Korean unless set, as the CLI does from -lang). A
+// program that sets it does so once, before any work starts. The errors
+// also have a Localize(locale string) string method that renders them in
+// the given locale whatever is set.
+package calcut
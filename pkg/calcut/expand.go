@@ -0,0 +1,273 @@
+package calcut
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+)
+
+// DefaultMaxInstances caps the instances Expand makes of one series when
+// ExpandOptions.MaxInstances is not set.
+const DefaultMaxInstances = 1000
+
+// ExpandOptions controls Expand.
+type ExpandOptions struct {
+	// Window selects the instances to keep. Its Loc is used for floating
+	// and DATE values; an open end relies on MaxInstances to stop
+	// endless rules.
+	Window DateWindow
+	// MaxInstances caps the instances made of one series, counted from
+	// its DTSTART; DefaultMaxInstances when zero.
+	MaxInstances int
+	// UniqueUIDs gives every instance a UID of its own ("<UID>-<start>")
+	// instead of the series UID plus RECURRENCE-ID, for importers that
+	// treat repeated UIDs as updates of one event.
+	UniqueUIDs bool
+	// Renamed, when set, is called with the series UID and the new UID of
+	// every instance UniqueUIDs gives a UID of its own.
+	Renamed func(from, to string)
+}
+
+// recurrenceProperties define the instances of a series and are dropped
+// from expanded instances.
+var recurrenceProperties = []string{"RRULE", "RDATE", "EXDATE", "EXRULE"}
+
+// Expand replaces every recurring component (one with RRULE or RDATE) by
+// single instances within opts.Window, applying RDATE, EXDATE and EXRULE.
+// Instances overridden by a component with the same UID and a matching
+// RECURRENCE-ID are left to that component. Each instance keeps the
+// properties of its series with DTSTART moved, DTEND or DUE moved along,
+// and a RECURRENCE-ID naming its original start. Other components are
+// kept when they start inside the window. Order follows the input.
+func Expand(events []Event, opts ExpandOptions) ([]Event, error) {
+	loc := opts.Window.Loc
+	if loc == nil {
+		loc = time.Local
+	}
+	max := opts.MaxInstances
+	if max <= 0 {
+		max = DefaultMaxInstances
+	}
+
+	overridden := make(map[string][]time.Time)
+	for _, e := range events {
+		if t, _, err := e.dateProperty("RECURRENCE-ID", loc); err == nil {
+			overridden[e.UID] = append(overridden[e.UID], t)
+		}
+	}
+
+	var out []Event
+	for _, e := range events {
+		recurring, override := false, false
+		for _, p := range TopLevelProperties(e.Text) {
+			switch p.Name {
+			case "RRULE", "RDATE":
+				recurring = true
+			case "RECURRENCE-ID":
+				override = true
+			}
+		}
+		if !recurring || override {
+			if opts.Window.Contains(e) {
+				if override && opts.UniqueUIDs {
+					e = opts.renamed(e, uniqueInstance(e))
+				}
+				out = append(out, e)
+			}
+			continue
+		}
+		instances, err := e.instances(loc, opts.Window.To, max)
+		if err != nil {
+			return nil, i18n.Errorf("UID %s: %s", e.UID, err)
+		}
+		for _, inst := range instances {
+			if !inWindow(opts.Window, inst) || containsTime(overridden[e.UID], inst) {
+				continue
+			}
+			out = append(out, opts.renamed(e, e.instanceAt(inst, loc, opts.UniqueUIDs)))
+		}
+	}
+	return out, nil
+}
+
+// renamed reports the UID of instance to opts.Renamed when it differs
+// from that of its series e, and returns instance.
+func (opts ExpandOptions) renamed(e, instance Event) Event {
+	if opts.Renamed != nil && instance.UID != e.UID {
+		opts.Renamed(e.UID, instance.UID)
+	}
+	return instance
+}
+
+// instances lists the start times of a recurring component.
+func (e Event) instances(loc *time.Location, end time.Time, max int) ([]time.Time, error) {
+	start, allDay, err := e.dateProperty("DTSTART", loc)
+	if err != nil {
+		return nil, err
+	}
+	var times, excluded []time.Time
+	for _, p := range propertyLines(e.Text) {
+		name := PropertyName(p)
+		params, value := splitContentLine(p)
+		switch name {
+		case "RRULE", "EXRULE":
+			rule, err := ParseRRule(value, start.Location())
+			if err != nil {
+				return nil, err
+			}
+			occurrences := rule.Occurrences(start, end, max)
+			if name == "RRULE" {
+				times = append(times, occurrences...)
+			} else {
+				excluded = append(excluded, occurrences...)
+			}
+		case "RDATE", "EXDATE":
+			for _, v := range strings.Split(value, ",") {
+				// A PERIOD value starts at its first half.
+				v, _, _ = strings.Cut(v, "/")
+				t, _, err := ParseDateTime(v, params["TZID"], params["VALUE"] == "DATE", valueZone(v, params["TZID"], loc))
+				if err != nil {
+					return nil, err
+				}
+				if allDay {
+					t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, start.Location())
+				}
+				if name == "RDATE" {
+					times = append(times, t)
+				} else {
+					excluded = append(excluded, t)
+				}
+			}
+		}
+	}
+	if !containsTime(times, start) {
+		times = append(times, start)
+	}
+
+	slices.SortFunc(times, time.Time.Compare)
+	times = slices.CompactFunc(times, time.Time.Equal)
+	return slices.DeleteFunc(times, func(t time.Time) bool { return containsTime(excluded, t) }), nil
+}
+
+// instanceAt renders the instance of a series starting at t.
+func (e Event) instanceAt(t time.Time, loc *time.Location, uniqueUID bool) Event {
+	text := e.Text
+	for _, name := range recurrenceProperties {
+		text = RemoveProperty(text, name)
+	}
+	start, _, _ := e.dateProperty("DTSTART", loc)
+	startValue := ExtractProperty(e.Text, "DTSTART")
+	recurrenceID := formatLike(startValue, t)
+	for _, name := range []string{"DTEND", "DUE"} {
+		value := ExtractProperty(e.Text, name)
+		if value == "" {
+			continue
+		}
+		end, allDay, err := e.dateProperty(name, loc)
+		if err != nil {
+			continue
+		}
+		var moved time.Time
+		if allDay {
+			days := int(end.Sub(start).Hours()+12) / 24
+			moved = t.AddDate(0, 0, days)
+		} else {
+			moved = t.Add(end.Sub(start)).In(end.Location())
+		}
+		text = SetProperty(text, name, formatLike(value, moved))
+	}
+	text = SetProperty(text, "DTSTART", recurrenceID)
+	if uniqueUID {
+		text = SetProperty(text, "UID", e.UID+"-"+recurrenceID)
+	} else {
+		text = insertLine(text, "RECURRENCE-ID"+propertyParams(e.Text, "DTSTART")+":"+recurrenceID)
+	}
+	return NewEvent(text)
+}
+
+// uniqueInstance turns an override into a standalone event, as Expand
+// does with instances when UniqueUIDs is set.
+func uniqueInstance(e Event) Event {
+	id := ExtractProperty(e.Text, "RECURRENCE-ID")
+	text := SetProperty(RemoveProperty(e.Text, "RECURRENCE-ID"), "UID", e.UID+"-"+id)
+	return NewEvent(text)
+}
+
+// dateProperty parses a DATE or DATE-TIME property of e in the zone it
+// is written in: UTC, its TZID, or loc for floating values and dates.
+func (e Event) dateProperty(name string, loc *time.Location) (time.Time, bool, error) {
+	for _, line := range propertyLines(e.Text) {
+		if PropertyName(line) != name {
+			continue
+		}
+		params, value := splitContentLine(line)
+		return ParseDateTime(value, params["TZID"], params["VALUE"] == "DATE", valueZone(value, params["TZID"], loc))
+	}
+	return time.Time{}, false, i18n.Errorf("%s가 없습니다", name)
+}
+
+// valueZone returns the zone a DATE-TIME value is written in.
+func valueZone(value, tzid string, loc *time.Location) *time.Location {
+	if strings.HasSuffix(strings.TrimSpace(value), "Z") {
+		return time.UTC
+	}
+	if tzid != "" {
+		if z := loadTZID(tzid); z != nil {
+			return z
+		}
+	}
+	return loc
+}
+
+// formatLike formats t in the form of the original value: a DATE, a UTC
+// time, or a local time.
+func formatLike(original string, t time.Time) string {
+	switch {
+	case len(original) == 8:
+		return t.Format("20060102")
+	case strings.HasSuffix(original, "Z"):
+		return t.UTC().Format("20060102T150405Z")
+	}
+	return t.Format("20060102T150405")
+}
+
+// propertyLines returns the unfolded top-level content lines of text.
+func propertyLines(text string) []string {
+	var lines []string
+	forEachTopLevelLine(text, func(l logicalLine) {
+		lines = append(lines, l.text)
+	})
+	return lines
+}
+
+// propertyParams returns the parameters of the first top-level name
+// property as written, starting with ";", or "" if it has none.
+func propertyParams(text, name string) string {
+	for _, line := range propertyLines(text) {
+		if PropertyName(line) != name {
+			continue
+		}
+		inQuotes := false
+		for i := len(name); i < len(line); i++ {
+			switch line[i] {
+			case '"':
+				inQuotes = !inQuotes
+			case ':':
+				if !inQuotes {
+					return line[len(name):i]
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func inWindow(w DateWindow, t time.Time) bool {
+	return (w.From.IsZero() || !t.Before(w.From)) && (w.To.IsZero() || t.Before(w.To))
+}
+
+func containsTime(list []time.Time, t time.Time) bool {
+	return slices.ContainsFunc(list, t.Equal)
+}
@@ -0,0 +1,70 @@
+package calcut
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+)
+
+// FilenameSet hands out distinct file names to chunks, so that two files
+// never overwrite each other. Names are compared ignoring case, as the
+// file systems of Windows and macOS do.
+type FilenameSet struct {
+	// Strict makes Claim fail on a taken name instead of changing it.
+	Strict bool
+
+	taken map[string]int // lower-cased name -> 1-based number of its file
+	n     int
+}
+
+// Claim takes c's file name for it. A name already taken gets a suffix:
+// a short hash of the UID of c's first event, or a number when there is
+// no UID or that name is taken as well ("Meeting.ics" becomes
+// "Meeting_3f9a1c.ics" or "Meeting_2.ics"). With Strict set it returns an
+// error instead.
+func (s *FilenameSet) Claim(c *Chunk) error {
+	if s.taken == nil {
+		s.taken = make(map[string]int)
+	}
+	s.n++
+	key := strings.ToLower(c.Filename)
+	first, ok := s.taken[key]
+	if !ok {
+		s.taken[key] = s.n
+		return nil
+	}
+	if s.Strict {
+		return i18n.Errorf("파일명이 겹칩니다: %s (%d번째 파일과 같음)", c.Filename, first)
+	}
+
+	base, ext := c.Filename, ""
+	if i := strings.LastIndexByte(base, '.'); i > 0 {
+		base, ext = base[:i], base[i:]
+	}
+	name := ""
+	if len(c.Events) > 0 && c.Events[0].UID != "" {
+		sum := sha1.Sum([]byte(c.Events[0].UID))
+		name = base + "_" + hex.EncodeToString(sum[:3]) + ext
+	}
+	for i := 2; name == "" || s.taken[strings.ToLower(name)] != 0; i++ {
+		name = base + "_" + strconv.Itoa(i) + ext
+	}
+	c.Filename = name
+	s.taken[strings.ToLower(name)] = s.n
+	return nil
+}
+
+// UniqueFilenames claims the names of chunks in order with a FilenameSet,
+// renaming those that collide or, when strict, failing on the first.
+func UniqueFilenames(chunks []Chunk, strict bool) error {
+	s := FilenameSet{Strict: strict}
+	for i := range chunks {
+		if err := s.Claim(&chunks[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package calcut
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+)
+
+var unsafeChars = regexp.MustCompile(`[<>:"/\\|?*]`)
+var multiUnderscore = regexp.MustCompile(`_+`)
+
+// maxFilenameBytes is the longest file name most file systems take; ext4,
+// NTFS and APFS all count it in bytes or UTF-16 units of at most that.
+const maxFilenameBytes = 255
+
+// maxComponentBytes caps what SanitizeFilename returns, leaving room
+// within maxFilenameBytes for the prefix, numbering, collision suffix and
+// extension around it. A Korean syllable takes 3 bytes and an emoji 4.
+const maxComponentBytes = 150
+
+// reservedNames are the device names Windows will not create files as,
+// with any extension ("CON.ics" as well as "CON").
+var reservedNames = []string{
+	"CON", "PRN", "AUX", "NUL",
+	"COM1", "COM2", "COM3", "COM4", "COM5", "COM6", "COM7", "COM8", "COM9",
+	"LPT1", "LPT2", "LPT3", "LPT4", "LPT5", "LPT6", "LPT7", "LPT8", "LPT9",
+}
+
+// SanitizeFilename turns an arbitrary string such as an event summary into
+// a safe file name component. Line breaks and other control characters
+// count as spaces. The result is in NFC, so a summary typed on macOS
+// names the same file as on Windows, at most maxComponentBytes long, cut
+// between characters, and never a Windows device name or ending in a dot,
+// which Windows drops.
+func SanitizeFilename(name string) string {
+	s := strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, norm.NFC.String(name))
+	s = unsafeChars.ReplaceAllString(s, "")
+	s = strings.ReplaceAll(s, " ", "_")
+	s = multiUnderscore.ReplaceAllString(s, "_")
+	s = strings.Trim(truncateName(strings.Trim(s, "_"), maxComponentBytes), "_.")
+	if s == "" {
+		return "untitled"
+	}
+	if base, _, _ := strings.Cut(s, "."); isReservedName(base) {
+		s = base + "_" + s[len(base):]
+	}
+	return s
+}
+
+// truncateName cuts s to at most n bytes at a character boundary, without
+// leaving combining marks or a zero-width joiner of a cut emoji sequence
+// dangling at the end.
+func truncateName(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	s = s[:n]
+	for s != "" {
+		r, size := utf8.DecodeLastRuneInString(s)
+		if r != utf8.RuneError && !unicode.Is(unicode.M, r) && r != '\u200d' {
+			break
+		}
+		s = s[:len(s)-size]
+	}
+	return s
+}
+
+// isReservedName reports whether Windows reserves base, a name up to its
+// first dot, as a device, whatever its case.
+func isReservedName(base string) bool {
+	return slices.ContainsFunc(reservedNames, func(r string) bool { return strings.EqualFold(r, base) })
+}
+
+// limitFilename shortens the part of a file name before its extension so
+// the whole fits in maxFilenameBytes, keeping room for the suffix
+// FilenameSet adds on a collision.
+func limitFilename(name string) string {
+	base, ext := name, ""
+	if i := strings.LastIndexByte(name, '.'); i > 0 {
+		base, ext = name[:i], name[i:]
+	}
+	limit := maxFilenameBytes - len(ext) - len("_ffffff")
+	if len(base) <= limit {
+		return name
+	}
+	return strings.TrimRight(truncateName(base, limit), "_.") + ext
+}
+
+// sizeSuffixes is ordered so that two-letter suffixes are tried before
+// their one-letter forms.
+var sizeSuffixes = []struct {
+	suffix string
+	mult   int64
+}{
+	{"GB", 1024 * 1024 * 1024},
+	{"MB", 1024 * 1024},
+	{"KB", 1024},
+	{"G", 1024 * 1024 * 1024},
+	{"M", 1024 * 1024},
+	{"K", 1024},
+}
+
+// ParseSize parses sizes such as "512K", "1.5MB" or "2048" into bytes.
+func ParseSize(s string) (int64, error) {
+	s = strings.TrimSpace(strings.ToUpper(s))
+	for _, unit := range sizeSuffixes {
+		if strings.HasSuffix(s, unit.suffix) {
+			numStr := s[:len(s)-len(unit.suffix)]
+			num, err := strconv.ParseFloat(numStr, 64)
+			if err != nil {
+				return 0, i18n.Errorf("잘못된 크기: %s", s)
+			}
+			return int64(num * float64(unit.mult)), nil
+		}
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
+
+// FormatBytes renders a byte count for humans, e.g. "1.5 MB".
+func FormatBytes(b int64) string {
+	switch {
+	case b >= 1024*1024:
+		return fmt.Sprintf("%.1f MB", float64(b)/(1024*1024))
+	case b >= 1024:
+		return fmt.Sprintf("%.1f KB", float64(b)/1024)
+	default:
+		return fmt.Sprintf("%d bytes", b)
+	}
+}
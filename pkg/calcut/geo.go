@@ -0,0 +1,93 @@
+package calcut
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+)
+
+// Geo is a position in decimal degrees, as in GEO (RFC 5545 §3.8.1.6).
+type Geo struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// ParseGeo reads a GEO value ("37.5665;126.978"), a geo: URI as used by
+// X-APPLE-STRUCTURED-LOCATION ("geo:37.5665,126.978") or a plain
+// "37.5665,126.978".
+func ParseGeo(s string) (Geo, error) {
+	v := strings.TrimSpace(s)
+	if len(v) > 4 && strings.EqualFold(v[:4], "geo:") {
+		v = v[4:]
+		// Drop URI parameters such as ";u=35".
+		v, _, _ = strings.Cut(v, ";")
+	}
+	latText, lonText, ok := strings.Cut(v, ";")
+	if !ok {
+		latText, lonText, ok = strings.Cut(v, ",")
+	}
+	// A geo: URI may carry an altitude as a third coordinate.
+	lonText, _, _ = strings.Cut(lonText, ",")
+	lat, err1 := strconv.ParseFloat(strings.TrimSpace(latText), 64)
+	lon, err2 := strconv.ParseFloat(strings.TrimSpace(lonText), 64)
+	if !ok || err1 != nil || err2 != nil || math.Abs(lat) > 90 || math.Abs(lon) > 180 {
+		return Geo{}, i18n.Errorf("잘못된 좌표: %s (위도,경도)", s)
+	}
+	return Geo{Lat: lat, Lon: lon}, nil
+}
+
+// earthRadiusKm is the mean Earth radius used for distances.
+const earthRadiusKm = 6371.0
+
+// DistanceKm returns the great-circle distance between g and o.
+func (g Geo) DistanceKm(o Geo) float64 {
+	rad := math.Pi / 180
+	dLat := (o.Lat - g.Lat) * rad
+	dLon := (o.Lon - g.Lon) * rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(g.Lat*rad)*math.Cos(o.Lat*rad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// StructuredLocation is Apple's X-APPLE-STRUCTURED-LOCATION: a place name
+// (the X-TITLE parameter) with coordinates.
+type StructuredLocation struct {
+	Title string `json:"title,omitempty"`
+	Geo   Geo    `json:"geo"`
+}
+
+// StructuredLocation returns the X-APPLE-STRUCTURED-LOCATION of e, if it
+// has a readable one.
+func (e Event) StructuredLocation() (StructuredLocation, bool) {
+	var loc StructuredLocation
+	found := false
+	forEachTopLevelLine(e.Text, func(l logicalLine) {
+		if found || PropertyName(l.text) != "X-APPLE-STRUCTURED-LOCATION" {
+			return
+		}
+		params, value := splitContentLine(l.text)
+		geo, err := ParseGeo(value)
+		if err != nil {
+			return
+		}
+		loc = StructuredLocation{Title: params["X-TITLE"], Geo: geo}
+		found = true
+	})
+	return loc, found
+}
+
+// Geo returns the position of e: its GEO property, or else the
+// coordinates of its X-APPLE-STRUCTURED-LOCATION.
+func (e Event) Geo() (Geo, bool) {
+	for _, p := range TopLevelProperties(e.Text) {
+		if p.Name != "GEO" {
+			continue
+		}
+		if geo, err := ParseGeo(p.Value); err == nil {
+			return geo, true
+		}
+	}
+	loc, ok := e.StructuredLocation()
+	return loc.Geo, ok
+}
@@ -0,0 +1,75 @@
+package calcut
+
+import "strings"
+
+// Localized returns the value of the top-level property name in the first
+// of langs it is given in, for properties such as SUMMARY that calendars
+// repeat once per language with a LANGUAGE parameter (RFC 5545 §3.2.10):
+//
+//	SUMMARY;LANGUAGE=ko:주간 회의
+//	SUMMARY;LANGUAGE=en:Weekly meeting
+//
+// A language matches its own tag and the tags it is a prefix of, ignoring
+// case, so "en" picks "en-US". Without a match the variant without
+// LANGUAGE is returned, else the first one. The value is returned as
+// written, escapes included.
+func (e Event) Localized(name string, langs []string) string {
+	var first, plain string
+	hasFirst, hasPlain := false, false
+	best, bestRank := "", len(langs)
+	forEachTopLevelLine(e.Text, func(l logicalLine) {
+		if PropertyName(l.text) != name {
+			return
+		}
+		params, value := splitContentLine(l.text)
+		if !hasFirst {
+			first, hasFirst = value, true
+		}
+		lang, ok := params["LANGUAGE"]
+		if !ok {
+			if !hasPlain {
+				plain, hasPlain = value, true
+			}
+			return
+		}
+		if rank := languageRank(lang, langs); rank < bestRank {
+			best, bestRank = value, rank
+		}
+	})
+	switch {
+	case bestRank < len(langs):
+		return best
+	case hasPlain:
+		return plain
+	}
+	return first
+}
+
+// languageRank returns the index of the first of langs that tag matches,
+// len(langs) when none does.
+func languageRank(tag string, langs []string) int {
+	tag = strings.ToLower(tag)
+	for i, lang := range langs {
+		lang = strings.ToLower(lang)
+		if tag == lang || strings.HasPrefix(tag, lang+"-") {
+			return i
+		}
+	}
+	return len(langs)
+}
+
+// PreferLanguages returns events with each Summary taken from the
+// SUMMARY variant in the first of langs it is given in, as Localized
+// picks it, for file names and listings. Event texts keep every variant.
+// With no langs events are returned as they are.
+func PreferLanguages(events []Event, langs []string) []Event {
+	if len(langs) == 0 {
+		return events
+	}
+	out := make([]Event, len(events))
+	for i, e := range events {
+		e.Summary = e.Localized("SUMMARY", langs)
+		out[i] = e
+	}
+	return out
+}
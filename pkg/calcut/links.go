@@ -0,0 +1,92 @@
+package calcut
+
+import (
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// LinkProperties hold a single URI: URL itself, CONFERENCE (RFC 7986
+// §5.11) and the meeting links Google and Microsoft export.
+var LinkProperties = []string{
+	"URL",
+	"CONFERENCE",
+	"X-GOOGLE-CONFERENCE",
+	"X-MICROSOFT-SKYPETEAMSMEETINGURL",
+	"X-MICROSOFT-ONLINEMEETINGCONFLINK",
+	"X-MICROSOFT-ONLINEMEETINGEXTERNALLINK",
+}
+
+// ConferenceProperties are the vendor properties that carry a meeting
+// link, by the calendar system that understands them. Other systems drop
+// them on import.
+var ConferenceProperties = map[string][]string{
+	"google": {"X-GOOGLE-CONFERENCE"},
+	"microsoft": {
+		"X-MICROSOFT-SKYPETEAMSMEETINGURL",
+		"X-MICROSOFT-ONLINEMEETINGCONFLINK",
+		"X-MICROSOFT-ONLINEMEETINGEXTERNALLINK",
+	},
+}
+
+// linkTextProperties are free-text properties that often carry links,
+// such as the invitation text of a video call.
+var linkTextProperties = []string{"DESCRIPTION", "LOCATION", "COMMENT", "X-ALT-DESC"}
+
+// URLPattern matches http(s) links in free text. Trailing punctuation is
+// left out so a link at the end of a sentence stays usable.
+var URLPattern = regexp.MustCompile(`https?://[^\s<>"']*[^\s<>"'.,;:!?)\]]`)
+
+// conferenceHosts are the domains of common video call services.
+var conferenceHosts = []string{
+	"zoom.us", "zoom.com", "meet.google.com", "teams.microsoft.com", "teams.live.com",
+	"webex.com", "gotomeeting.com", "whereby.com", "meet.jit.si", "chime.aws",
+}
+
+// Link is a URL found in an event: the value of one of LinkProperties, or
+// a link inside a free-text property. Conference is set for links to a
+// known video call service and for CONFERENCE and the vendor meeting
+// properties.
+type Link struct {
+	Property   string `json:"property"`
+	URL        string `json:"url"`
+	Conference bool   `json:"conference"`
+}
+
+// Links lists the links of e in the order they appear. TEXT escapes are
+// decoded before free text is searched.
+func (e Event) Links() []Link {
+	var links []Link
+	forEachTopLevelLine(e.Text, func(l logicalLine) {
+		name := PropertyName(l.text)
+		_, value := splitContentLine(l.text)
+		switch {
+		case slices.Contains(LinkProperties, name):
+			if value != "" {
+				links = append(links, Link{Property: name, URL: value, Conference: name != "URL" || IsConferenceURL(value)})
+			}
+		case slices.Contains(linkTextProperties, name):
+			for _, u := range URLPattern.FindAllString(textUnescaper.Replace(value), -1) {
+				links = append(links, Link{Property: name, URL: u, Conference: IsConferenceURL(u)})
+			}
+		}
+	})
+	return links
+}
+
+// IsConferenceURL reports whether u points at a known video call service
+// (Zoom, Google Meet, Microsoft Teams, Webex and the like).
+func IsConferenceURL(u string) bool {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(parsed.Hostname())
+	for _, h := range conferenceHosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
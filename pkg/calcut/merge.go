@@ -0,0 +1,109 @@
+package calcut
+
+import (
+	"strconv"
+	"time"
+)
+
+// MergeOptions controls how Merge combines calendars.
+type MergeOptions struct {
+	// DedupeUID keeps only the first event for each UID and RECURRENCE-ID,
+	// so copies of an event found in several inputs are dropped while the
+	// overrides of a recurring series survive. Events without a UID are
+	// always kept.
+	DedupeUID bool
+	// KeepLatest makes DedupeUID keep the latest copy as Dedupe does
+	// instead of the first.
+	KeepLatest bool
+}
+
+// Merge combines calendars into one: the header of the first, every
+// VTIMEZONE once per TZID (the first definition wins), and the events of
+// all of them in input order. Timezones without a TZID are kept once per
+// distinct block.
+func Merge(cals []ParsedCalendar, opts MergeOptions) ParsedCalendar {
+	var merged ParsedCalendar
+	if len(cals) > 0 {
+		merged.HeaderLines = append([]string(nil), cals[0].HeaderLines...)
+		merged.LineEnding = cals[0].LineEnding
+		merged.TimezonesFirst = cals[0].TimezonesFirst
+	}
+
+	zones := make(map[string]bool)
+	type instance struct{ uid, recurrenceID string }
+	seen := make(map[instance]bool)
+	for _, cal := range cals {
+		for _, block := range cal.Timezones {
+			key := ExtractProperty(block, "TZID")
+			if key == "" {
+				key = "\x00" + block
+			}
+			if !zones[key] {
+				zones[key] = true
+				merged.Timezones = append(merged.Timezones, block)
+			}
+		}
+		for _, event := range cal.Events {
+			if opts.DedupeUID && !opts.KeepLatest && event.UID != "" {
+				key := instance{event.UID, ExtractProperty(event.Text, "RECURRENCE-ID")}
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+			}
+			merged.Events = append(merged.Events, event)
+		}
+	}
+	if opts.DedupeUID && opts.KeepLatest {
+		merged.Events = Dedupe(merged.Events)
+	}
+	return merged
+}
+
+// Dedupe drops repeated copies of events, such as those left by merging
+// the exports of several devices. Of the components sharing a UID and
+// RECURRENCE-ID it keeps the latest revision, the one with the highest
+// SEQUENCE, then the latest LAST-MODIFIED, then the latest DTSTAMP, in
+// the place of the first copy. Events without a UID are always kept.
+func Dedupe(events []Event) []Event {
+	type instance struct{ uid, recurrenceID string }
+	first := make(map[instance]int)
+	var out []Event
+	for _, event := range events {
+		if event.UID == "" {
+			out = append(out, event)
+			continue
+		}
+		key := instance{event.UID, ExtractProperty(event.Text, "RECURRENCE-ID")}
+		i, ok := first[key]
+		if !ok {
+			first[key] = len(out)
+			out = append(out, event)
+			continue
+		}
+		if newerRevision(event, out[i]) {
+			out[i] = event
+		}
+	}
+	return out
+}
+
+// newerRevision reports whether a is a later revision of an event than b.
+func newerRevision(a, b Event) bool {
+	seqA, _ := strconv.Atoi(ExtractProperty(a.Text, "SEQUENCE"))
+	seqB, _ := strconv.Atoi(ExtractProperty(b.Text, "SEQUENCE"))
+	if seqA != seqB {
+		return seqA > seqB
+	}
+	for _, name := range []string{"LAST-MODIFIED", "DTSTAMP"} {
+		ta, _, errA := ParseDateTime(ExtractProperty(a.Text, name), "", false, time.UTC)
+		tb, _, errB := ParseDateTime(ExtractProperty(b.Text, name), "", false, time.UTC)
+		switch {
+		case errA == nil && (errB != nil || ta.After(tb)):
+			return true
+		case errB == nil && (errA != nil || tb.After(ta)):
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package calcut
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+)
+
+// NamePlaceholders are the placeholders a NameTemplate knows.
+var NamePlaceholders = []string{"prefix", "index", "key", "uid", "summary", "date", "year", "month"}
+
+// NameTemplate names output files after their chunk, as in
+// "{date}_{summary}.ics". Placeholders in braces are replaced by
+//
+//	{prefix}   SplitOptions.Prefix
+//	{index}    the number of the file, from 1, as 001
+//	{key}      the key the file was planned by (period, category, ...)
+//	{uid}      the UID of its first event
+//	{summary}  the summary of its first event
+//	{date}     the earliest start date, as 2024-03-05
+//	{year}     the year of that date, as 2024
+//	{month}    its month, as 03
+//
+// and the rest is kept as written. Values are cleaned up as
+// SanitizeFilename does; one that is missing, such as the date of an
+// undated file, leaves its placeholder empty.
+type NameTemplate struct {
+	text string
+}
+
+// ParseNameTemplate checks a template for unknown placeholders, unmatched
+// braces and characters file names cannot hold. ".ics" is added when the
+// template does not end in it.
+func ParseNameTemplate(s string) (NameTemplate, error) {
+	rest := s
+	for rest != "" {
+		open := strings.IndexAny(rest, "{}")
+		if open < 0 {
+			break
+		}
+		if rest[open] == '}' {
+			return NameTemplate{}, i18n.Errorf("잘못된 파일명 템플릿: %s (짝이 맞지 않는 중괄호)", s)
+		}
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return NameTemplate{}, i18n.Errorf("잘못된 파일명 템플릿: %s (짝이 맞지 않는 중괄호)", s)
+		}
+		name := rest[open+1 : open+end]
+		if !slices.Contains(NamePlaceholders, name) {
+			return NameTemplate{}, i18n.Errorf("알 수 없는 파일명 자리표시자: {%s} (사용 가능: %s)", name, "{"+strings.Join(NamePlaceholders, "}, {")+"}")
+		}
+		rest = rest[open+end+1:]
+	}
+	if unsafeChars.MatchString(strings.NewReplacer("{", "", "}", "").Replace(s)) {
+		return NameTemplate{}, i18n.Errorf("잘못된 파일명 템플릿: %s (파일명에 쓸 수 없는 문자)", s)
+	}
+	if !strings.HasSuffix(strings.ToLower(s), ".ics") {
+		s += ".ics"
+	}
+	return NameTemplate{text: s}, nil
+}
+
+// IsZero reports whether t is the zero template, which leaves files
+// named as planned.
+func (t NameTemplate) IsZero() bool {
+	return t.text == ""
+}
+
+// Name returns the file name of the idx-th chunk c, planned with prefix.
+func (t NameTemplate) Name(prefix string, idx int, c Chunk) string {
+	var uid, summary string
+	if len(c.Events) > 0 {
+		uid, summary = c.Events[0].UID, UnescapeText(c.Events[0].Summary)
+	}
+	date, _ := DateRange(c.Events)
+	var year, month string
+	if date != "" {
+		year, month = date[:4], date[5:7]
+	}
+	value := func(v string) string {
+		if v == "" {
+			return ""
+		}
+		return SanitizeFilename(v)
+	}
+	name := strings.NewReplacer(
+		"{prefix}", value(prefix),
+		"{index}", fmt.Sprintf("%03d", idx),
+		"{key}", value(c.Key),
+		"{uid}", value(uid),
+		"{summary}", value(summary),
+		"{date}", date,
+		"{year}", year,
+		"{month}", month,
+	).Replace(t.text)
+	// Empty values must not leave doubled or dangling separators.
+	base, ext := name[:len(name)-len(".ics")], name[len(name)-len(".ics"):]
+	base = strings.Trim(multiUnderscore.ReplaceAllString(base, "_"), "_-. ")
+	if base == "" {
+		base = "untitled"
+	}
+	return limitFilename(base + ext)
+}
+
+// Rename names chunks with t, numbering them in order from 1. The zero
+// template leaves them as planned.
+func (t NameTemplate) Rename(chunks []Chunk, prefix string) {
+	if t.IsZero() {
+		return
+	}
+	for i := range chunks {
+		chunks[i].Filename = t.Name(prefix, i+1, chunks[i])
+	}
+}
@@ -0,0 +1,145 @@
+package calcut
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+)
+
+var (
+	monthPattern    = regexp.MustCompile(`^\d{4}-\d{2}$`)
+	yearPattern     = regexp.MustCompile(`^\d{4}$`)
+	relativePattern = regexp.MustCompile(`^(?:(\d+) (day|week|month|year)s? ago|in (\d+) (day|week|month|year)s?)$`)
+	namedPattern    = regexp.MustCompile(`^(this|last|next) (week|month|year)$`)
+)
+
+// ParseDatePeriod parses a date as people write it and returns the period
+// it names, from start (inclusive) to end (exclusive) in loc:
+//
+//   - a day, month or year: "2024-03-05", "2024-03", "2024"
+//   - an RFC 3339 time, naming just that instant: "2024-03-05T09:00:00+09:00"
+//   - "today", "yesterday", "tomorrow"
+//   - "this", "last" or "next" "week" (starting on Monday), "month" or "year"
+//   - a day counted from today: "90 days ago", "2 weeks ago", "in 3 months"
+//
+// Relative dates are taken from now. Case and surrounding spaces do not
+// matter.
+func ParseDatePeriod(s string, now time.Time, loc *time.Location) (start, end time.Time, err error) {
+	v := strings.Join(strings.Fields(strings.ToLower(s)), " ")
+	y, m, d := now.In(loc).Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, loc)
+	switch {
+	case monthPattern.MatchString(v):
+		if start, err = time.ParseInLocation("2006-01", v, loc); err == nil {
+			return start, start.AddDate(0, 1, 0), nil
+		}
+	case yearPattern.MatchString(v):
+		if start, err = time.ParseInLocation("2006", v, loc); err == nil {
+			return start, start.AddDate(1, 0, 0), nil
+		}
+	case v == "today":
+		return today, today.AddDate(0, 0, 1), nil
+	case v == "yesterday":
+		return today.AddDate(0, 0, -1), today, nil
+	case v == "tomorrow":
+		return today.AddDate(0, 0, 1), today.AddDate(0, 0, 2), nil
+	}
+	if day, err := time.ParseInLocation("2006-01-02", v, loc); err == nil {
+		return day, day.AddDate(0, 0, 1), nil
+	}
+	if t, err := time.Parse(time.RFC3339, strings.ToUpper(v)); err == nil {
+		return t, t, nil
+	}
+	if match := namedPattern.FindStringSubmatch(v); match != nil {
+		offset := map[string]int{"this": 0, "last": -1, "next": 1}[match[1]]
+		switch match[2] {
+		case "week":
+			monday := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+			start = monday.AddDate(0, 0, 7*offset)
+			return start, start.AddDate(0, 0, 7), nil
+		case "month":
+			start = time.Date(y, m+time.Month(offset), 1, 0, 0, 0, 0, loc)
+			return start, start.AddDate(0, 1, 0), nil
+		default:
+			start = time.Date(y+offset, 1, 1, 0, 0, 0, 0, loc)
+			return start, start.AddDate(1, 0, 0), nil
+		}
+	}
+	if match := relativePattern.FindStringSubmatch(v); match != nil {
+		count, unit, sign := match[1], match[2], -1
+		if count == "" {
+			count, unit, sign = match[3], match[4], 1
+		}
+		n, err := strconv.Atoi(count)
+		if err == nil {
+			n *= sign
+			switch unit {
+			case "day":
+				start = today.AddDate(0, 0, n)
+			case "week":
+				start = today.AddDate(0, 0, 7*n)
+			case "month":
+				start = today.AddDate(0, n, 0)
+			default:
+				start = today.AddDate(n, 0, 0)
+			}
+			return start, start.AddDate(0, 0, 1), nil
+		}
+	}
+	return time.Time{}, time.Time{}, i18n.Errorf("잘못된 날짜: %s (예: 2024-03-05, 2024-03, 2024, last year, 90 days ago)", s)
+}
+
+var spanPattern = regexp.MustCompile(`^(\d+)\s*(d|days?|w|weeks?|mo|months?|y|years?)$`)
+
+// Span is a length of calendar time such as six months. It is added with
+// time.AddDate, so months and years follow the calendar.
+type Span struct {
+	Years, Months, Days int
+}
+
+// ParseSpan parses a span such as "30d", "2w", "6mo" or "1y"; the units
+// may also be written out ("6 months").
+func ParseSpan(s string) (Span, error) {
+	match := spanPattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(s)))
+	if match == nil {
+		return Span{}, i18n.Errorf("잘못된 상대 기간: %s (예: 30d, 2w, 6mo, 1y)", s)
+	}
+	n, err := strconv.Atoi(match[1])
+	if err != nil {
+		return Span{}, i18n.Errorf("잘못된 상대 기간: %s (예: 30d, 2w, 6mo, 1y)", s)
+	}
+	switch match[2][0] {
+	case 'd':
+		return Span{Days: n}, nil
+	case 'w':
+		return Span{Days: 7 * n}, nil
+	case 'm':
+		return Span{Months: n}, nil
+	}
+	return Span{Years: n}, nil
+}
+
+// IsZero reports whether s is empty.
+func (s Span) IsZero() bool {
+	return s == Span{}
+}
+
+// Before returns t moved back by s.
+func (s Span) Before(t time.Time) time.Time {
+	return t.AddDate(-s.Years, -s.Months, -s.Days)
+}
+
+// After returns t moved forward by s.
+func (s Span) After(t time.Time) time.Time {
+	return t.AddDate(s.Years, s.Months, s.Days)
+}
+
+// RelativeDateWindow is the window from last before now to next after
+// now. A zero span leaves that side at now, so "the last six months" is
+// RelativeDateWindow(now, Span{Months: 6}, Span{}, loc).
+func RelativeDateWindow(now time.Time, last, next Span, loc *time.Location) DateWindow {
+	return DateWindow{From: last.Before(now), To: next.After(now), Loc: loc}
+}
@@ -0,0 +1,530 @@
+package calcut
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+)
+
+// Event is a single splittable component: a VEVENT, or a VTODO, VJOURNAL
+// or VFREEBUSY block, as named by Kind. Text holds the block verbatim; the
+// other fields are extracted from it and never diverge from it. Its lines
+// end in "\n" whatever the input used; the calendar's LineEnding is put
+// back on output.
+type Event struct {
+	Kind      string
+	Text      string
+	Summary   string
+	UID       string
+	DTStart   string
+	RelatedTo []string
+}
+
+// NewEvent builds an Event from the verbatim text of a component block.
+func NewEvent(text string) Event {
+	unfolded := Unfold(text)
+	event := Event{
+		Kind:      componentKind(text),
+		Text:      text,
+		Summary:   extractUnfolded(unfolded, "SUMMARY"),
+		UID:       extractUnfolded(unfolded, "UID"),
+		DTStart:   extractUnfolded(unfolded, "DTSTART"),
+		RelatedTo: extractAllUnfolded(unfolded, "RELATED-TO"),
+	}
+	// Old exports may write the summary quoted-printable or in another
+	// charset; files are named after the decoded text.
+	if strings.Contains(unfolded, "SUMMARY;") || !utf8.ValidString(event.Summary) {
+		if summary, ok := legacySummary(text); ok {
+			event.Summary = summary
+		}
+	}
+	return event
+}
+
+// Title returns the summary of e unescaped and on one line, as listings
+// show it.
+func (e Event) Title() string {
+	return strings.ReplaceAll(UnescapeText(e.Summary), "\n", " ")
+}
+
+// ComponentKinds are the top-level components a calendar is split into.
+// Anything else except VTIMEZONE is dropped.
+var ComponentKinds = []string{"VEVENT", "VTODO", "VJOURNAL", "VFREEBUSY"}
+
+// ParseComponentKinds parses a comma-separated list of ComponentKinds such
+// as "VEVENT,VTODO", ignoring case. An empty list selects every kind.
+func ParseComponentKinds(list string) ([]string, error) {
+	if strings.TrimSpace(list) == "" {
+		return nil, nil
+	}
+	var kinds []string
+	for _, name := range strings.Split(list, ",") {
+		kind := strings.ToUpper(strings.TrimSpace(name))
+		if !slices.Contains(ComponentKinds, kind) {
+			return nil, i18n.Errorf("알 수 없는 컴포넌트: %s (%s 중 하나)", name, strings.Join(ComponentKinds, ", "))
+		}
+		if !slices.Contains(kinds, kind) {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds, nil
+}
+
+// FilterKinds returns the events whose Kind is one of kinds, in order. With
+// no kinds every event is kept.
+func FilterKinds(events []Event, kinds []string) []Event {
+	if len(kinds) == 0 {
+		return events
+	}
+	var out []Event
+	for _, event := range events {
+		if slices.Contains(kinds, event.Kind) {
+			out = append(out, event)
+		}
+	}
+	return out
+}
+
+// componentKind returns the component name of a block from its BEGIN line.
+func componentKind(text string) string {
+	first, _, _ := strings.Cut(text, "\n")
+	_, kind, _ := strings.Cut(strings.TrimSpace(first), ":")
+	return strings.ToUpper(kind)
+}
+
+// ParsedCalendar is a calendar broken into the parts needed to rebuild
+// valid calendars from any subset of its events: the VCALENDAR header
+// lines, the VTIMEZONE blocks, and the events.
+type ParsedCalendar struct {
+	HeaderLines []string
+	Timezones   []string
+	Events      []Event
+
+	// LineEnding is what output lines end with, "\r\n" or "\n". The
+	// parsers set it to the line ending of the input; when empty, output
+	// uses CRLF as RFC 5545 requires.
+	LineEnding string
+
+	// TimezonesFirst writes the X- header properties that name one of the
+	// timezones, such as X-WR-TIMEZONE, after the VTIMEZONEs, for strict
+	// parsers (older Lotus Notes and Exchange) that want a timezone
+	// defined before its first use. RFC 5545 has all calendar properties
+	// first, which is the default.
+	TimezonesFirst bool
+
+	// Warnings are the structural problems the parser recovered from,
+	// such as a missing END:VCALENDAR; with ParseLimits.Strict they are
+	// errors instead.
+	Warnings []*ParseError
+}
+
+// ParseLimits bounds the resources the parser may spend on untrusted input
+// such as uploads. A zero field means no limit.
+type ParseLimits struct {
+	MaxBytes      int64 // total input size
+	MaxDepth      int   // BEGIN/END nesting below VCALENDAR (VEVENT=1, VALARM=2)
+	MaxComponents int   // top-level components (events, timezones, ...)
+	MaxLineLength int   // length of a single physical line
+
+	// Strict fails on the structural problems that are otherwise
+	// recovered from and listed in ParsedCalendar.Warnings: unbalanced
+	// BEGIN/END, content after END:VCALENDAR, or no VCALENDAR wrapper.
+	Strict bool
+}
+
+// DefaultParseLimits returns limits generous enough for any real calendar
+// export while keeping hostile input from exhausting memory.
+func DefaultParseLimits() ParseLimits {
+	return ParseLimits{
+		MaxBytes:      256 * 1024 * 1024,
+		MaxDepth:      8,
+		MaxComponents: 1000000,
+		MaxLineLength: 1024 * 1024,
+	}
+}
+
+// ParseError reports input rejected by the parser. Line is 1-based, or 0
+// when the error concerns the input as a whole.
+type ParseError struct {
+	Line int
+	Msg  string
+
+	msg *i18n.Error // Msg in translatable form
+}
+
+func newParseError(line int, format string, args ...any) *ParseError {
+	msg := &i18n.Error{Format: format, Args: args}
+	return &ParseError{Line: line, Msg: msg.Error(), msg: msg}
+}
+
+func (e *ParseError) Error() string {
+	return e.Localize(i18n.Locale())
+}
+
+// Localize renders the error in locale, "ko" or "en".
+func (e *ParseError) Localize(locale string) string {
+	msg := e.Msg
+	if e.msg != nil {
+		msg = e.msg.Localize(locale)
+	}
+	if e.Line == 0 {
+		return msg
+	}
+	return i18n.Sprintf(locale, "%d번째 줄: %s", e.Line, msg)
+}
+
+// ParseBytes parses an iCalendar document while enforcing limits. It is
+// the entry point for untrusted input: it never panics, runs in time linear
+// in the input size, and fails with a *ParseError instead of growing
+// without bound.
+func ParseBytes(data []byte, limits ParseLimits) (ParsedCalendar, error) {
+	return ParseBytesContext(context.Background(), data, limits)
+}
+
+// ParseBytesContext is like ParseBytes but gives up with ctx.Err() once
+// ctx is done.
+func ParseBytesContext(ctx context.Context, data []byte, limits ParseLimits) (ParsedCalendar, error) {
+	return ParseBytesProgress(ctx, data, limits, nil)
+}
+
+// ParseProgress is told how many events a parse has read so far and which
+// fraction of the input, from 0 to 1.
+type ParseProgress func(events int, fraction float64)
+
+// ParseBytesProgress is like ParseBytesContext and calls progress, unless
+// nil, every few thousand lines, where it also checks ctx.
+func ParseBytesProgress(ctx context.Context, data []byte, limits ParseLimits, progress ParseProgress) (ParsedCalendar, error) {
+	if limits.MaxBytes > 0 && int64(len(data)) > limits.MaxBytes {
+		return ParsedCalendar{}, newParseError(0, "입력이 너무 큽니다 (%d bytes, 최대 %d bytes)", len(data), limits.MaxBytes)
+	}
+	if err := SniffInput(data); err != nil {
+		return ParsedCalendar{}, err
+	}
+	cals, err := parse(ctx, string(data), limits, progress)
+	if err != nil {
+		return ParsedCalendar{}, err
+	}
+	return JoinCalendars(cals), nil
+}
+
+// ParseCalendarsContext is like ParseBytesContext but returns every
+// VCALENDAR of a document that holds several one after another separately,
+// in order. The warnings about the document go with the last one.
+func ParseCalendarsContext(ctx context.Context, data []byte, limits ParseLimits) ([]ParsedCalendar, error) {
+	if limits.MaxBytes > 0 && int64(len(data)) > limits.MaxBytes {
+		return nil, newParseError(0, "입력이 너무 큽니다 (%d bytes, 최대 %d bytes)", len(data), limits.MaxBytes)
+	}
+	if err := SniffInput(data); err != nil {
+		return nil, err
+	}
+	return parse(ctx, string(data), limits, nil)
+}
+
+// JoinCalendars makes one calendar of the VCALENDARs of a document: the
+// header lines of the first, and those of the others whose property the
+// header does not have yet; the timezones, each TZID once; and all events
+// and warnings, in order.
+func JoinCalendars(cals []ParsedCalendar) ParsedCalendar {
+	if len(cals) == 1 {
+		return cals[0]
+	}
+	joined := Merge(cals, MergeOptions{})
+	for i, cal := range cals {
+		if i > 0 {
+			joined.HeaderLines = joinHeader(joined.HeaderLines, cal.HeaderLines)
+		}
+		joined.Warnings = append(joined.Warnings, cal.Warnings...)
+	}
+	return joined
+}
+
+// joinHeader appends to header the lines of another calendar's header
+// whose property it does not have yet.
+func joinHeader(header, lines []string) []string {
+	for _, line := range lines {
+		name := PropertyName(Unfold(line))
+		if !slices.ContainsFunc(header, func(h string) bool { return PropertyName(Unfold(h)) == name }) {
+			header = append(header, line)
+		}
+	}
+	return header
+}
+
+// ParseICal parses a trusted iCalendar document without any limits.
+func ParseICal(content string) ParsedCalendar {
+	cals, _ := parse(context.Background(), content, ParseLimits{}, nil)
+	return JoinCalendars(cals)
+}
+
+// ctxCheckInterval is how many lines the parser handles between checks of
+// its context.
+const ctxCheckInterval = 4096
+
+// lineKind classifies a logical line by its place in the calendar's
+// component structure.
+type lineKind int
+
+const (
+	lineSkip       lineKind = iota // END:VCALENDAR or blank
+	lineCalendar                   // BEGIN:VCALENDAR
+	lineHeader                     // VCALENDAR property
+	lineBlockStart                 // BEGIN of a top-level component
+	lineBlock                      // inside a component
+	lineBlockEnd                   // END of a top-level component
+)
+
+// componentScanner follows BEGIN/END nesting one logical line at a time and
+// enforces the component limits. It is shared by the in-memory and the
+// streaming parser, which differ only in how they keep block text.
+type componentScanner struct {
+	limits     ParseLimits
+	components int
+	// RFC 5545: BEGIN/END can nest (e.g. VALARM inside VEVENT)
+	nesting int
+	// blockType names the current top-level component; it stays set after
+	// lineBlockEnd so the caller can see what just ended.
+	blockType string
+
+	// open names the components begun and not yet ended, outermost
+	// first, and blockLine is the line the top-level one began on.
+	open      []string
+	blockLine int
+	// calendar is set by BEGIN:VCALENDAR and ended by END:VCALENDAR;
+	// unwrapped and trailing note that content was found without any
+	// VCALENDAR or after one ended.
+	calendar, ended, unwrapped, trailing bool
+	warnings                             []*ParseError
+}
+
+// problem reports a structural problem of the input: an error with
+// ParseLimits.Strict, otherwise a warning the parser recovers from.
+func (s *componentScanner) problem(line int, format string, args ...any) error {
+	err := newParseError(line, format, args...)
+	if s.limits.Strict {
+		return err
+	}
+	s.warnings = append(s.warnings, err)
+	return nil
+}
+
+// outside checks a line found outside of the VCALENDAR wrapper.
+func (s *componentScanner) outside(lineNo int) error {
+	switch {
+	case s.ended && !s.trailing:
+		s.trailing = true
+		return s.problem(lineNo, "END:VCALENDAR 뒤에 내용이 있습니다")
+	case !s.ended && !s.unwrapped:
+		s.unwrapped = true
+		return s.problem(lineNo, "BEGIN:VCALENDAR로 감싸여 있지 않습니다")
+	}
+	return nil
+}
+
+func (s *componentScanner) next(stripped string, lineNo int) (lineKind, error) {
+	switch stripped {
+	case "BEGIN:VCALENDAR":
+		if s.nesting > 0 {
+			if err := s.problem(lineNo, "%s이(가) 다음 BEGIN:VCALENDAR 전에 닫히지 않았습니다 (%d번째 줄에서 시작)", s.open[0], s.blockLine); err != nil {
+				return 0, err
+			}
+			s.dropOpen()
+		}
+		// Several calendars one after another, as some exporters
+		// write them, are read one by one.
+		s.calendar, s.ended, s.trailing = true, false, false
+		return lineCalendar, nil
+	case "END:VCALENDAR":
+		if s.nesting > 0 {
+			if err := s.problem(lineNo, "%s이(가) END:VCALENDAR 전에 닫히지 않았습니다 (%d번째 줄에서 시작)", s.open[0], s.blockLine); err != nil {
+				return 0, err
+			}
+			s.dropOpen()
+		}
+		if !s.calendar {
+			if err := s.problem(lineNo, "BEGIN:VCALENDAR 없이 END:VCALENDAR가 있습니다"); err != nil {
+				return 0, err
+			}
+		}
+		s.calendar, s.ended = false, true
+		return lineSkip, nil
+	}
+
+	if s.nesting == 0 {
+		if stripped == "" {
+			return lineSkip, nil
+		}
+		if !s.calendar {
+			if err := s.outside(lineNo); err != nil {
+				return 0, err
+			}
+		}
+		if strings.HasPrefix(stripped, "BEGIN:") {
+			s.components++
+			if s.limits.MaxComponents > 0 && s.components > s.limits.MaxComponents {
+				return 0, newParseError(lineNo, "컴포넌트가 너무 많습니다 (최대 %d개)", s.limits.MaxComponents)
+			}
+			s.blockType = strings.SplitN(stripped, ":", 2)[1]
+			s.nesting = 1
+			s.open = append(s.open[:0], s.blockType)
+			s.blockLine = lineNo
+			return lineBlockStart, nil
+		}
+		if strings.HasPrefix(stripped, "END:") {
+			// Left in the header it would end every output calendar
+			// early, so it is dropped.
+			return lineSkip, s.problem(lineNo, "짝이 맞는 BEGIN이 없는 %s", stripped)
+		}
+		return lineHeader, nil
+	}
+
+	if name, ok := strings.CutPrefix(stripped, "BEGIN:"); ok {
+		s.nesting++
+		if s.limits.MaxDepth > 0 && s.nesting > s.limits.MaxDepth {
+			return 0, newParseError(lineNo, "컴포넌트 중첩이 너무 깊습니다 (최대 %d단계)", s.limits.MaxDepth)
+		}
+		s.open = append(s.open, name)
+	} else if name, ok := strings.CutPrefix(stripped, "END:"); ok {
+		s.nesting--
+		if begun := s.open[len(s.open)-1]; !strings.EqualFold(name, begun) {
+			if err := s.problem(lineNo, "짝이 맞지 않는 END:%s (열린 컴포넌트: %s)", name, begun); err != nil {
+				return 0, err
+			}
+		}
+		s.open = s.open[:len(s.open)-1]
+	}
+	if s.nesting == 0 {
+		return lineBlockEnd, nil
+	}
+	return lineBlock, nil
+}
+
+// dropOpen drops the component left open when its calendar ends, so
+// that it does not swallow the lines of the calendars after it. The
+// caller discards the block text at the next lineBlockStart.
+func (s *componentScanner) dropOpen() {
+	s.nesting = 0
+	s.open = s.open[:0]
+}
+
+// finish checks the end of the input on line lineNo, the last one: a
+// component left open is dropped, as it cannot be written back whole.
+func (s *componentScanner) finish(lineNo int) error {
+	if s.nesting > 0 {
+		if err := s.problem(s.blockLine, "닫히지 않은 컴포넌트: %s", s.open[0]); err != nil {
+			return err
+		}
+	}
+	switch {
+	case s.calendar:
+		return s.problem(lineNo, "END:VCALENDAR가 없습니다")
+	case !s.ended && !s.unwrapped:
+		return s.problem(0, "BEGIN:VCALENDAR가 없습니다")
+	}
+	return nil
+}
+
+func parse(ctx context.Context, content string, limits ParseLimits, progress ParseProgress) ([]ParsedCalendar, error) {
+	// cals[len(cals)-1] is the calendar being read; lines outside of any
+	// VCALENDAR go to it too.
+	cals := []ParsedCalendar{{}}
+
+	// Lines are kept ending in "\n" alone, so that nothing downstream
+	// finds stray carriage returns in values; the input's line ending is
+	// remembered for output.
+	lineEnding := ""
+	if i := strings.IndexByte(content, '\n'); i > 0 && content[i-1] == '\r' {
+		lineEnding = "\r\n"
+	} else if i >= 0 {
+		lineEnding = "\n"
+	}
+	if strings.Contains(content, "\r\n") {
+		content = strings.ReplaceAll(content, "\r\n", "\n")
+	}
+
+	scanner := componentScanner{limits: limits}
+	blockStart := 0
+	lineNo := 0
+	logical := 0
+
+	// Blocks are sliced out of content rather than rebuilt line by line,
+	// so memory stays proportional to the input and folded lines are
+	// written back exactly as they were read.
+	for pos := 0; pos < len(content); {
+		lineStart := pos
+		lineEnd := lineStart
+		// RFC 5545 §3.1: a line starting with a space or tab continues the
+		// previous one, so collect the whole logical line first.
+		for {
+			lineNo++
+			end := strings.IndexByte(content[pos:], '\n')
+			if end < 0 {
+				end = len(content)
+				pos = len(content)
+			} else {
+				end += pos
+				pos = end + 1
+			}
+			if limits.MaxLineLength > 0 && end-lineEnd > limits.MaxLineLength {
+				return nil, newParseError(lineNo, "줄이 너무 깁니다 (%d bytes, 최대 %d bytes)", end-lineEnd, limits.MaxLineLength)
+			}
+			lineEnd = end
+			if pos >= len(content) || (content[pos] != ' ' && content[pos] != '\t') {
+				break
+			}
+		}
+		line := content[lineStart:lineEnd]
+
+		logical++
+		if logical%ctxCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			if progress != nil {
+				events := 0
+				for _, cal := range cals {
+					events += len(cal.Events)
+				}
+				progress(events, float64(pos)/float64(len(content)))
+			}
+		}
+
+		kind, err := scanner.next(strings.TrimSpace(Unfold(line)), lineNo)
+		if err != nil {
+			return nil, err
+		}
+		cur := &cals[len(cals)-1]
+		switch kind {
+		case lineCalendar:
+			if len(cur.HeaderLines) > 0 || len(cur.Timezones) > 0 || len(cur.Events) > 0 {
+				cals = append(cals, ParsedCalendar{})
+			}
+		case lineHeader:
+			cur.HeaderLines = append(cur.HeaderLines, line)
+		case lineBlockStart:
+			blockStart = lineStart
+		case lineBlockEnd:
+			blockText := content[blockStart:lineEnd]
+			switch scanner.blockType {
+			case "VTIMEZONE":
+				cur.Timezones = append(cur.Timezones, blockText)
+			default:
+				if slices.Contains(ComponentKinds, scanner.blockType) {
+					cur.Events = append(cur.Events, NewEvent(blockText))
+				}
+			}
+		}
+	}
+
+	if err := scanner.finish(lineNo); err != nil {
+		return nil, err
+	}
+
+	for i := range cals {
+		cals[i].LineEnding = lineEnding
+	}
+	cals[len(cals)-1].Warnings = scanner.warnings
+	return cals, nil
+}
@@ -0,0 +1,56 @@
+package calcut
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fuzzBudget is how long ParseBytes, Render and Validate may each spend
+// on one fuzz input; the inputs are small, so anything near it means
+// work that grows faster than the input.
+const fuzzBudget = 2 * time.Second
+
+func FuzzParseBytes(f *testing.F) {
+	for _, seed := range []string{
+		// Nested components.
+		"BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//x//y//EN\r\n" +
+			"BEGIN:VTIMEZONE\r\nTZID:Asia/Seoul\r\nBEGIN:STANDARD\r\nDTSTART:19700101T000000\r\n" +
+			"TZOFFSETFROM:+0900\r\nTZOFFSETTO:+0900\r\nEND:STANDARD\r\nEND:VTIMEZONE\r\n" +
+			"BEGIN:VEVENT\r\nUID:a\r\nDTSTAMP:20240101T000000Z\r\nDTSTART;TZID=Asia/Seoul:20240301T090000\r\n" +
+			"SUMMARY:회의\r\nBEGIN:VALARM\r\nACTION:DISPLAY\r\nTRIGGER:-PT15M\r\nEND:VALARM\r\nEND:VEVENT\r\n" +
+			"END:VCALENDAR\r\n",
+		// Folded lines and a quoted-printable soft line break.
+		"BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VEVENT\r\nUID:b\r\nDTSTART:20240302\r\n" +
+			"DESCRIPTION:a long description\r\n  folded over\r\n\tseveral lines\r\n" +
+			"SUMMARY;ENCODING=QUOTED-PRINTABLE:caf=\r\n=C3=A9\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n",
+		// Several VCALENDARs, the first left open.
+		"BEGIN:VCALENDAR\nBEGIN:VEVENT\nUID:c\nDTSTART:20240303T100000Z\nEND:VEVENT\n" +
+			"BEGIN:VCALENDAR\nBEGIN:VEVENT\nUID:d\nRRULE:FREQ=DAILY;COUNT=3\nDTSTART:20240304T100000Z\nEND:VEVENT\nEND:VCALENDAR\n",
+		"",
+		"BEGIN:VEVENT\nEND:VCALENDAR\nEND:VEVENT\n",
+	} {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ctx, cancel := context.WithTimeout(context.Background(), fuzzBudget)
+		defer cancel()
+		parsed, err := ParseBytesContext(ctx, data, DefaultParseLimits())
+		if errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("ParseBytes took longer than %s", fuzzBudget)
+		}
+		if err == nil {
+			start := time.Now()
+			SplitBySize(parsed, SplitOptions{MaxBytes: 512})
+			if d := time.Since(start); d > fuzzBudget {
+				t.Fatalf("Render took %s", d)
+			}
+		}
+		start := time.Now()
+		Validate(data)
+		if d := time.Since(start); d > fuzzBudget {
+			t.Fatalf("Validate took %s", d)
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package calcut
+
+import "strings"
+
+// PartStat returns the participation status (RFC 5545 §3.2.12) of the
+// attendee with address addr, compared without case and with or without
+// "mailto:". An attendee without PARTSTAT has not answered yet
+// (NEEDS-ACTION), and an organizer who is not also listed as attendee
+// counts as ACCEPTED. ok is false when addr takes no part in e.
+func (e Event) PartStat(addr string) (status string, ok bool) {
+	want := calAddress(addr)
+	d := e.Details()
+	for _, a := range d.Attendees {
+		if calAddress(a.Value) != want {
+			continue
+		}
+		if status = strings.ToUpper(a.Params["PARTSTAT"]); status == "" {
+			status = "NEEDS-ACTION"
+		}
+		return status, true
+	}
+	if d.Organizer.Value != "" && calAddress(d.Organizer.Value) == want {
+		return "ACCEPTED", true
+	}
+	return "", false
+}
+
+// calAddress normalizes a CAL-ADDRESS for comparison.
+func calAddress(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	return strings.TrimPrefix(s, "mailto:")
+}
+
+// PartStatFilter selects events by how one person answered them. Events
+// the person takes no part in, such as their own appointments without
+// attendees, are always kept.
+type PartStatFilter struct {
+	Address string
+	// OnlyAccepted keeps only the events the person accepted.
+	OnlyAccepted bool
+	// DropDeclined drops the events the person declined.
+	DropDeclined bool
+}
+
+// Keep reports whether f lets e through. A filter without Address keeps
+// everything.
+func (f PartStatFilter) Keep(e Event) bool {
+	if f.Address == "" {
+		return true
+	}
+	status, ok := e.PartStat(f.Address)
+	switch {
+	case !ok:
+		return true
+	case f.OnlyAccepted:
+		return status == "ACCEPTED"
+	case f.DropDeclined:
+		return status != "DECLINED"
+	}
+	return true
+}
+
+// FilterPartStat returns the events f keeps, in input order. Each
+// component is judged on its own, so a declined occurrence of a series
+// goes while the rest of the series stays.
+func FilterPartStat(events []Event, f PartStatFilter) []Event {
+	if f.Address == "" {
+		return events
+	}
+	var out []Event
+	for _, e := range events {
+		if f.Keep(e) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// IsCancelled reports whether e has STATUS:CANCELLED.
+func (e Event) IsCancelled() bool {
+	return strings.EqualFold(e.Details().Status.Value, "CANCELLED")
+}
+
+// FilterCancelled drops cancelled events, in input order. A cancelled
+// series goes together with its overrides, while a cancelled override of
+// a live series is kept: dropping it would bring back the occurrence it
+// cancels.
+func FilterCancelled(events []Event) []Event {
+	cancelled := make(map[string]bool)
+	for _, e := range events {
+		if e.UID != "" && e.IsCancelled() && ExtractProperty(e.Text, "RECURRENCE-ID") == "" {
+			cancelled[e.UID] = true
+		}
+	}
+	var out []Event
+	for _, e := range events {
+		if cancelled[e.UID] || (e.UID == "" && e.IsCancelled()) {
+			continue
+		}
+		out = append(out, e)
+	}
+	return out
+}
@@ -0,0 +1,71 @@
+package calcut
+
+import "strings"
+
+// PeopleFilter keeps the events in which given people take part. Within
+// a list any one person is enough; when both lists are set an event has
+// to match each of them.
+type PeopleFilter struct {
+	// Attendees are matched against ATTENDEE.
+	Attendees []string
+	// Organizers are matched against ORGANIZER.
+	Organizers []string
+}
+
+// IsZero reports whether f has no people and so keeps every event.
+func (f PeopleFilter) IsZero() bool {
+	return len(f.Attendees) == 0 && len(f.Organizers) == 0
+}
+
+// Keep reports whether f lets e through.
+func (f PeopleFilter) Keep(e Event) bool {
+	d := e.Details()
+	if len(f.Attendees) > 0 && !matchesPerson(d.Attendees, f.Attendees) {
+		return false
+	}
+	if len(f.Organizers) > 0 && !matchesPerson([]PropertyValue{d.Organizer}, f.Organizers) {
+		return false
+	}
+	return true
+}
+
+// FilterPeople returns the events f keeps, in input order. Like
+// FilterPartStat it judges each component on its own, as an occurrence
+// may have a different guest list than its series.
+func FilterPeople(events []Event, f PeopleFilter) []Event {
+	if f.IsZero() {
+		return events
+	}
+	var out []Event
+	for _, e := range events {
+		if f.Keep(e) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// matchesPerson reports whether one of props names one of people. A
+// person is an address, compared as PartStat does against the value and
+// the EMAIL parameter (RFC 7986 §6.2), or, without "@", a display name
+// compared with CN, both without case.
+func matchesPerson(props []PropertyValue, people []string) bool {
+	for _, p := range props {
+		if p.Value == "" {
+			continue
+		}
+		for _, person := range people {
+			if !strings.Contains(person, "@") {
+				if p.Params["CN"] != "" && strings.EqualFold(strings.TrimSpace(p.Params["CN"]), strings.TrimSpace(person)) {
+					return true
+				}
+				continue
+			}
+			want := calAddress(person)
+			if calAddress(p.Value) == want || (p.Params["EMAIL"] != "" && calAddress(p.Params["EMAIL"]) == want) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,439 @@
+package calcut
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// unfolder removes the line break and the single space or tab that start
+// a continuation line (RFC 5545 §3.1).
+var unfolder = strings.NewReplacer("\r\n ", "", "\r\n\t", "", "\n ", "", "\n\t", "")
+
+// Unfold joins folded content lines back into logical lines.
+func Unfold(text string) string {
+	return unfolder.Replace(text)
+}
+
+// Fold breaks lines of text longer than 75 octets into continuation
+// lines starting with a space (RFC 5545 §3.1), never inside a UTF-8
+// sequence. Other lines, already folded ones included, and the line
+// endings are kept as they are.
+func Fold(text string) string {
+	if foldedLen(text) == len(text) {
+		return text
+	}
+	return encode(text, "")
+}
+
+// encode folds text like Fold and, unless eol is empty, ends every line
+// with eol instead of the "\n" text is kept with in memory.
+func encode(text, eol string) string {
+	var b strings.Builder
+	b.Grow(encodedLen(text, eol))
+	foldLines(text, eol, func(s string) { b.WriteString(s) })
+	return b.String()
+}
+
+// foldedLen returns len(Fold(text)) without building it.
+func foldedLen(text string) int {
+	return encodedLen(text, "")
+}
+
+// encodedLen returns len(encode(text, eol)) without building it.
+func encodedLen(text, eol string) int {
+	n := 0
+	foldLines(text, eol, func(s string) { n += len(s) })
+	return n
+}
+
+// foldLines passes the pieces of the folded text to write, in order, with
+// the line endings replaced by eol unless it is empty.
+func foldLines(text, eol string, write func(string)) {
+	for text != "" {
+		line := text
+		if i := strings.IndexByte(text, '\n'); i >= 0 {
+			line = text[:i+1]
+		}
+		text = text[len(line):]
+		body := strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+		end := line[len(body):]
+		if eol != "" {
+			// A carriage return left without its line feed goes too.
+			end = ""
+			if strings.HasSuffix(line, "\n") {
+				end = eol
+			}
+		}
+		if len(body) <= maxLineOctets {
+			write(body)
+			write(end)
+			continue
+		}
+		brk := "\n "
+		if eol != "" {
+			brk = eol + " "
+		} else if end == "\r\n" {
+			brk = "\r\n "
+		}
+		limit := maxLineOctets
+		for len(body) > limit {
+			cut := limit
+			for cut > 1 && !utf8.RuneStart(body[cut]) {
+				cut--
+			}
+			write(body[:cut])
+			write(brk)
+			body = body[cut:]
+			limit = maxLineOctets - 1 // the leading space counts
+		}
+		write(body)
+		write(end)
+	}
+}
+
+// UnescapeText decodes an RFC 5545 TEXT value (§3.3.11): "\n" and "\N"
+// become line breaks and "\,", "\;" and "\\" the characters escaped, for
+// values shown to people or used in file names.
+func UnescapeText(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`, "\r", "")
+
+// EscapeText encodes s as an RFC 5545 TEXT value, the reverse of
+// UnescapeText.
+func EscapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// ExtractProperty returns the value of the first line of block that sets
+// propName, or "" if there is none. Folded lines are unfolded first.
+func ExtractProperty(block, propName string) string {
+	return extractUnfolded(Unfold(block), propName)
+}
+
+// ExtractAllProperties returns the values of every line of block that sets
+// propName, in order. Folded lines are unfolded first.
+func ExtractAllProperties(block, propName string) []string {
+	return extractAllUnfolded(Unfold(block), propName)
+}
+
+func extractUnfolded(block, propName string) string {
+	for _, line := range strings.Split(block, "\n") {
+		// handles both "PROP:value" and "PROP;PARAM=x:value" (RFC 5545 §3.2)
+		if strings.HasPrefix(line, propName+":") || strings.HasPrefix(line, propName+";") {
+			idx := strings.Index(line, ":")
+			if idx >= 0 {
+				return strings.TrimSpace(line[idx+1:])
+			}
+		}
+	}
+	return ""
+}
+
+func extractAllUnfolded(block, propName string) []string {
+	var values []string
+	for _, line := range strings.Split(block, "\n") {
+		if strings.HasPrefix(line, propName+":") || strings.HasPrefix(line, propName+";") {
+			idx := strings.Index(line, ":")
+			if idx >= 0 {
+				values = append(values, strings.TrimSpace(line[idx+1:]))
+			}
+		}
+	}
+	return values
+}
+
+// Property is a single content line split into name and value. Parameters
+// are not kept.
+type Property struct {
+	Name  string
+	Value string
+}
+
+// TopLevelProperties lists the properties of a component, skipping those
+// of nested components such as VALARM.
+func TopLevelProperties(text string) []Property {
+	var props []Property
+	forEachTopLevelLine(text, func(l logicalLine) {
+		idx := strings.Index(l.text, ":")
+		if idx < 0 {
+			return
+		}
+		props = append(props, Property{Name: PropertyName(l.text), Value: strings.TrimSpace(l.text[idx+1:])})
+	})
+	return props
+}
+
+// logicalLine is a content line that may span several physical lines of
+// a block because of folding.
+type logicalLine struct {
+	first, last int    // physical line indexes, inclusive
+	text        string // unfolded and trimmed
+}
+
+// logicalLines groups the physical lines of text into logical lines. The
+// soft line breaks of quoted-printable values join lines too.
+func logicalLines(lines []string) []logicalLine {
+	var out []logicalLine
+	var cur lineBuilder
+	soft := false
+	flush := func() {
+		if len(out) > 0 {
+			out[len(out)-1].text = strings.TrimSpace(cur.String())
+		}
+	}
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		if i > 0 && len(out) > 0 && soft {
+			out[len(out)-1].last = i
+			cur.trimSoftBreak()
+			cur.add(line)
+			soft = strings.HasSuffix(line, "=")
+			continue
+		}
+		if i > 0 && len(out) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			out[len(out)-1].last = i
+			cur.add(line[1:])
+			soft = cur.softBreak()
+			continue
+		}
+		flush()
+		cur.reset()
+		cur.add(line)
+		soft = cur.softBreak()
+		out = append(out, logicalLine{first: i, last: i})
+	}
+	flush()
+	return out
+}
+
+// lineBuilder collects the pieces of a logical line and joins them once,
+// so that a value folded over thousands of lines, such as an inline
+// ATTACH, is not copied again with every continuation.
+type lineBuilder struct {
+	pieces []string // non-empty
+	// colon is set once the pieces hold the colon ending the name and
+	// parameters, and qp once those contain QUOTED-PRINTABLE; tail is
+	// the upper-cased end of the name and parameters until then, for a
+	// match split across pieces.
+	colon, qp bool
+	tail      string
+}
+
+func (b *lineBuilder) reset() {
+	*b = lineBuilder{pieces: b.pieces[:0]}
+}
+
+func (b *lineBuilder) add(s string) {
+	if s == "" {
+		return
+	}
+	b.pieces = append(b.pieces, s)
+	if b.colon {
+		return
+	}
+	head, _, found := strings.Cut(s, ":")
+	upper := b.tail + strings.ToUpper(head)
+	b.qp = b.qp || strings.Contains(upper, "QUOTED-PRINTABLE")
+	b.tail = upper[max(0, len(upper)-len("QUOTED-PRINTABLE")+1):]
+	b.colon = found
+}
+
+// softBreak reports whether the line so far ends in a quoted-printable
+// soft line break, as isSoftBreak does for a whole line.
+func (b *lineBuilder) softBreak() bool {
+	return b.qp && len(b.pieces) > 0 && strings.HasSuffix(b.pieces[len(b.pieces)-1], "=")
+}
+
+// trimSoftBreak drops the "=" of a soft line break.
+func (b *lineBuilder) trimSoftBreak() {
+	if n := len(b.pieces); n > 0 {
+		if last := strings.TrimSuffix(b.pieces[n-1], "="); last != "" {
+			b.pieces[n-1] = last
+		} else {
+			b.pieces = b.pieces[:n-1]
+		}
+	}
+}
+
+func (b *lineBuilder) String() string {
+	if len(b.pieces) == 1 {
+		return b.pieces[0]
+	}
+	return strings.Join(b.pieces, "")
+}
+
+// forEachTopLevelLine calls fn for every content line of the outermost
+// component in text, excluding its own BEGIN/END lines. Folded lines are
+// reported once, unfolded, with their physical line range.
+func forEachTopLevelLine(text string, fn func(l logicalLine)) {
+	forEachLine(text, func(l logicalLine, depth int) {
+		if depth == 1 {
+			fn(l)
+		}
+	})
+}
+
+// forEachLine is like forEachTopLevelLine but visits the content lines of
+// nested components too, with their depth (1 for the outermost).
+func forEachLine(text string, fn func(l logicalLine, depth int)) {
+	depth := 0
+	for _, l := range logicalLines(strings.Split(text, "\n")) {
+		switch {
+		case strings.HasPrefix(l.text, "BEGIN:"):
+			depth++
+		case strings.HasPrefix(l.text, "END:"):
+			depth--
+		case l.text != "":
+			fn(l, depth)
+		}
+	}
+}
+
+// PropertyName returns the upper-cased name of a content line, without
+// parameters.
+func PropertyName(line string) string {
+	end := strings.IndexAny(line, ":;")
+	if end < 0 {
+		return ""
+	}
+	return strings.ToUpper(line[:end])
+}
+
+// SetProperty replaces the value of the first top-level occurrence of name,
+// keeping its parameters, or adds the property before the first nested
+// component (or the closing END line) if it is missing. A folded property
+// is replaced as a whole.
+func SetProperty(text, name, value string) string {
+	lines := strings.Split(text, "\n")
+	var target *logicalLine
+	forEachTopLevelLine(text, func(l logicalLine) {
+		if target == nil && PropertyName(l.text) == name {
+			target = &l
+		}
+	})
+
+	eol := ""
+	if strings.HasSuffix(lines[0], "\r") {
+		eol = "\r"
+	}
+
+	if target != nil {
+		line := target.text[:strings.Index(target.text, ":")+1] + value + eol
+		lines = append(lines[:target.first], append([]string{line}, lines[target.last+1:]...)...)
+		return strings.Join(lines, "\n")
+	}
+
+	return insertLine(text, name+":"+value)
+}
+
+// insertLine adds a content line to a component before its first nested
+// component, or before its closing END line.
+func insertLine(text, line string) string {
+	lines := strings.Split(text, "\n")
+	eol := ""
+	if strings.HasSuffix(lines[0], "\r") {
+		eol = "\r"
+	}
+	insert := len(lines) - 1
+	for i := 1; i < len(lines); i++ {
+		if strings.HasPrefix(strings.TrimSpace(lines[i]), "BEGIN:") {
+			insert = i
+			break
+		}
+	}
+	lines = append(lines[:insert], append([]string{line + eol}, lines[insert:]...)...)
+	return strings.Join(lines, "\n")
+}
+
+// RemoveProperty drops every top-level occurrence of name, including the
+// continuation lines of folded occurrences.
+func RemoveProperty(text, name string) string {
+	return removeProperty(text, name, false)
+}
+
+// StripProperty is like RemoveProperty but also drops name from nested
+// components such as VALARM.
+func StripProperty(text, name string) string {
+	return removeProperty(text, name, true)
+}
+
+func removeProperty(text, name string, nested bool) string {
+	lines := strings.Split(text, "\n")
+	drop := make(map[int]bool)
+	forEachLine(text, func(l logicalLine, depth int) {
+		if (depth == 1 || nested) && PropertyName(l.text) == name {
+			for i := l.first; i <= l.last; i++ {
+				drop[i] = true
+			}
+		}
+	})
+	if len(drop) == 0 {
+		return text
+	}
+	kept := lines[:0]
+	for i, line := range lines {
+		if !drop[i] {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// MapProperties passes the name and value of every top-level property, and
+// with nested those of nested components too, to fn, and replaces the
+// value with the one fn returns when ok, keeping the parameters. A folded
+// property is replaced as a whole.
+func MapProperties(text string, nested bool, fn func(name, value string) (string, bool)) string {
+	lines := strings.Split(text, "\n")
+	eol := ""
+	if strings.HasSuffix(lines[0], "\r") {
+		eol = "\r"
+	}
+	replaced := make(map[int]string)
+	drop := make(map[int]bool)
+	forEachLine(text, func(l logicalLine, depth int) {
+		idx := strings.Index(l.text, ":")
+		if (depth != 1 && !nested) || idx < 0 {
+			return
+		}
+		value, ok := fn(PropertyName(l.text), strings.TrimSpace(l.text[idx+1:]))
+		if !ok {
+			return
+		}
+		replaced[l.first] = l.text[:idx+1] + value + eol
+		for i := l.first + 1; i <= l.last; i++ {
+			drop[i] = true
+		}
+	})
+	if len(replaced) == 0 {
+		return text
+	}
+	out := lines[:0]
+	for i, line := range lines {
+		if r, ok := replaced[i]; ok {
+			out = append(out, r)
+		} else if !drop[i] {
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n")
+}
@@ -0,0 +1,225 @@
+package calcut
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// structuralProperties are the properties Anonymize keeps the values of:
+// the component structure, dates, recurrence, time zones and states,
+// which bugs depend on and which say nothing of what an event is about.
+var structuralProperties = []string{
+	"BEGIN", "END", "VERSION", "PRODID", "CALSCALE", "METHOD",
+	"DTSTART", "DTEND", "DUE", "DURATION", "DTSTAMP", "CREATED", "LAST-MODIFIED", "COMPLETED",
+	"RECURRENCE-ID", "EXDATE", "RDATE", "RRULE", "EXRULE",
+	"ACTION", "TRIGGER", "REPEAT", "STATUS", "TRANSP", "CLASS", "SEQUENCE", "PRIORITY", "PERCENT-COMPLETE",
+	"TZID", "TZOFFSETFROM", "TZOFFSETTO", "TZNAME", "X-WR-TIMEZONE", "FREEBUSY",
+}
+
+// structuralParameters are the parameters Anonymize keeps the values of.
+var structuralParameters = []string{
+	"VALUE", "TZID", "ENCODING", "FMTTYPE", "CHARSET", "LANGUAGE", "RANGE", "RELATED", "RELTYPE",
+	"ROLE", "PARTSTAT", "RSVP", "CUTYPE", "FBTYPE",
+}
+
+// uriScheme is the scheme of a value such as "mailto:" or "https:", kept
+// so that addresses are still read as addresses.
+var uriScheme = regexp.MustCompile(`^(?i)(mailto|https?|tel|urn|cid|data|webcal):`)
+
+// Anonymize returns data with its content scrubbed and its shape kept,
+// for attaching to a bug report: every line keeps its length in bytes,
+// its line ending and where it is folded, so sizes, folding, components
+// and malformed lines come out as they went in. Property and parameter
+// names are kept, and the values of structuralProperties and
+// structuralParameters; in every other value letters and digits are
+// replaced by random ones, and other characters by random ones of the
+// same length in UTF-8, leaving spaces, punctuation, escapes and URI
+// schemes in place. Bytes that are not UTF-8 become 0xA1. The same value
+// is always replaced the same way, and different values differently
+// where their length allows, so UIDs still match their overrides and
+// RELATED-TO and no two events come to share one. The replacements are
+// drawn from salt, so that a fresh salt keeps the replacement of a short
+// value from being guessed.
+func Anonymize(data, salt []byte) []byte {
+	a := anonymizer{salt: salt, replaced: make(map[string]string), used: make(map[string]bool)}
+	var out bytes.Buffer
+	out.Grow(len(data))
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	for i := 0; i < len(lines); {
+		// A logical line and its continuation lines.
+		j := i + 1
+		for j < len(lines) && len(lines[j]) > 0 && (lines[j][0] == ' ' || lines[j][0] == '\t') {
+			j++
+		}
+		bodies := make([][]byte, j-i)
+		var logical []byte
+		for k, line := range lines[i:j] {
+			body := bytes.TrimSuffix(bytes.TrimSuffix(line, []byte("\n")), []byte("\r"))
+			if k > 0 {
+				body = body[1:]
+			}
+			bodies[k] = body
+			logical = append(logical, body...)
+		}
+		scrubbed := a.line(string(logical))
+		for k, line := range lines[i:j] {
+			lead, n := min(k, 1), len(bodies[k])
+			out.Write(line[:lead])
+			out.WriteString(scrubbed[:n])
+			out.Write(line[lead+n:])
+			scrubbed = scrubbed[n:]
+		}
+		i = j
+	}
+	return out.Bytes()
+}
+
+// anonymizer holds the replacements Anonymize has made.
+type anonymizer struct {
+	salt     []byte
+	replaced map[string]string // by original value
+	used     map[string]bool
+}
+
+// line scrubs one unfolded content line, returning a string of the same
+// length. A line without a colon is scrubbed whole.
+func (a anonymizer) line(line string) string {
+	if !strings.Contains(line, ":") {
+		return a.value(line)
+	}
+	var b strings.Builder
+	i := strings.IndexAny(line, ";:")
+	name := strings.ToUpper(strings.TrimPrefix(line[:i], "\ufeff"))
+	b.WriteString(line[:i])
+	for line[i] == ';' {
+		// A parameter: name, "=", and values separated by commas, quoted
+		// or not.
+		b.WriteByte(';')
+		i++
+		eq := strings.IndexAny(line[i:], "=;:")
+		if eq < 0 {
+			b.WriteString(a.value(line[i:]))
+			return b.String()
+		}
+		if line[i+eq] != '=' {
+			// A parameter without a value.
+			b.WriteString(line[i : i+eq])
+			i += eq
+			continue
+		}
+		param := strings.ToUpper(line[i : i+eq])
+		b.WriteString(line[i : i+eq+1])
+		i += eq + 1
+		keep := slices.Contains(structuralParameters, param)
+		for {
+			end := i
+			if end < len(line) && line[end] == '"' {
+				if q := strings.IndexByte(line[end+1:], '"'); q >= 0 {
+					end += q + 2
+				} else {
+					end = len(line)
+				}
+			} else if k := strings.IndexAny(line[end:], ",;:"); k >= 0 {
+				end += k
+			} else {
+				end = len(line)
+			}
+			if keep {
+				b.WriteString(line[i:end])
+			} else {
+				b.WriteString(a.value(line[i:end]))
+			}
+			i = end
+			if i >= len(line) || line[i] != ',' {
+				break
+			}
+			b.WriteByte(',')
+			i++
+		}
+		if i >= len(line) {
+			return b.String()
+		}
+	}
+	b.WriteString(line[i : i+1])
+	value := line[i+1:]
+	if slices.Contains(structuralProperties, name) {
+		b.WriteString(value)
+	} else {
+		b.WriteString(a.value(value))
+	}
+	return b.String()
+}
+
+// maxRedraws bounds how often value draws again for a replacement that
+// another value already has; a value too short to differ keeps the last.
+const maxRedraws = 20
+
+// value returns the replacement of s, as Anonymize describes. Values
+// longer than a UID usually is are drawn once, without being remembered:
+// they cannot come out the same by chance.
+func (a anonymizer) value(s string) string {
+	if len(s) > 64 {
+		return scrub(s, a.salt, 0)
+	}
+	if r, ok := a.replaced[s]; ok {
+		return r
+	}
+	var r string
+	for attempt := range maxRedraws {
+		if r = scrub(s, a.salt, attempt); !a.used[r] {
+			break
+		}
+	}
+	a.replaced[s] = r
+	a.used[r] = true
+	return r
+}
+
+// scrub replaces the content of s with characters drawn from a sequence
+// seeded by salt, s and attempt.
+func scrub(s string, salt []byte, attempt int) string {
+	if s == "" {
+		return s
+	}
+	sum := sha256.Sum256(fmt.Appendf(slices.Clip(salt), "%d:%s", attempt, s))
+	r := rand.New(rand.NewPCG(binary.LittleEndian.Uint64(sum[:8]), binary.LittleEndian.Uint64(sum[8:16])))
+	var b strings.Builder
+	b.Grow(len(s))
+	scheme := uriScheme.FindString(s)
+	b.WriteString(scheme)
+	for i := len(scheme); i < len(s); {
+		c, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case c == utf8.RuneError && size == 1:
+			b.WriteByte(0xA1)
+		case c == '\\' && i+1 < len(s):
+			// An escape such as "\n" or "\,", which TEXT values structure
+			// with.
+			b.WriteString(s[i : i+2])
+			size = 2
+		case c >= 'a' && c <= 'z':
+			b.WriteByte(byte('a' + r.IntN(26)))
+		case c >= 'A' && c <= 'Z':
+			b.WriteByte(byte('A' + r.IntN(26)))
+		case c >= '0' && c <= '9':
+			b.WriteByte(byte('0' + r.IntN(10)))
+		case size == 2:
+			b.WriteRune(rune(0xC0 + r.IntN(0x17))) // À-Ö
+		case size == 3:
+			b.WriteRune(rune(0xAC00 + r.IntN(0xD7A4-0xAC00))) // 가-힣
+		case size == 4:
+			b.WriteRune(rune(0x1F600 + r.IntN(0x50))) // emoticons
+		default:
+			b.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	return b.String()
+}
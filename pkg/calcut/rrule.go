@@ -0,0 +1,443 @@
+package calcut
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+)
+
+// RRule is a parsed recurrence rule (RFC 5545 §3.3.10). BYWEEKNO is not
+// supported.
+type RRule struct {
+	Freq     string
+	Interval int
+	Count    int
+	// Until is the last possible start, inclusive; zero when open.
+	Until time.Time
+
+	ByMonth    []int
+	ByYearDay  []int
+	ByMonthDay []int
+	ByDay      []WeekdayNum
+	ByHour     []int
+	ByMinute   []int
+	BySecond   []int
+	BySetPos   []int
+	WeekStart  time.Weekday
+}
+
+// WeekdayNum is a BYDAY item such as "MO", "2TU" or "-1FR". N is zero
+// for every such weekday of the period.
+type WeekdayNum struct {
+	N   int
+	Day time.Weekday
+}
+
+var rruleFreqs = []string{"YEARLY", "MONTHLY", "WEEKLY", "DAILY", "HOURLY", "MINUTELY", "SECONDLY"}
+
+var weekdayNames = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// maxEmptyPeriods bounds how many periods in a row may yield no instance
+// before a rule is given up on, so rules that never match (such as
+// BYMONTH=2;BYMONTHDAY=30) end.
+const maxEmptyPeriods = 10000
+
+// ParseRRule parses an RRULE or EXRULE value. UNTIL is read in loc when
+// it is floating or a DATE.
+func ParseRRule(value string, loc *time.Location) (RRule, error) {
+	r := RRule{Interval: 1, WeekStart: time.Monday}
+	for _, part := range strings.Split(strings.TrimSpace(value), ";") {
+		if part == "" {
+			continue
+		}
+		name, val, ok := strings.Cut(part, "=")
+		if !ok {
+			return RRule{}, i18n.Errorf("잘못된 RRULE: %s", value)
+		}
+		name = strings.ToUpper(name)
+		val = strings.ToUpper(val)
+		var err error
+		switch name {
+		case "FREQ":
+			if !slices.Contains(rruleFreqs, val) {
+				return RRule{}, i18n.Errorf("지원하지 않는 RRULE FREQ: %s", val)
+			}
+			r.Freq = val
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(val)
+			if err == nil && r.Interval < 1 {
+				err = strconv.ErrRange
+			}
+		case "COUNT":
+			r.Count, err = strconv.Atoi(val)
+			if err == nil && r.Count < 1 {
+				err = strconv.ErrRange
+			}
+		case "UNTIL":
+			r.Until, _, err = ParseDateTime(val, "", false, loc)
+			if err == nil && len(val) == 8 {
+				// A DATE includes the whole day.
+				r.Until = r.Until.AddDate(0, 0, 1).Add(-time.Second)
+			}
+		case "BYMONTH":
+			r.ByMonth, err = parseIntList(val, 1, 12, false)
+		case "BYWEEKNO":
+			return RRule{}, i18n.Errorf("지원하지 않는 RRULE 규칙: %s", name)
+		case "BYYEARDAY":
+			r.ByYearDay, err = parseIntList(val, 1, 366, true)
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = parseIntList(val, 1, 31, true)
+		case "BYHOUR":
+			r.ByHour, err = parseIntList(val, 0, 23, false)
+		case "BYMINUTE":
+			r.ByMinute, err = parseIntList(val, 0, 59, false)
+		case "BYSECOND":
+			r.BySecond, err = parseIntList(val, 0, 60, false)
+		case "BYSETPOS":
+			r.BySetPos, err = parseIntList(val, 1, 366, true)
+		case "BYDAY":
+			r.ByDay, err = parseWeekdayList(val)
+		case "WKST":
+			day, ok := weekdayNames[val]
+			if !ok {
+				err = strconv.ErrSyntax
+			}
+			r.WeekStart = day
+		default:
+			// X-names and rule parts this package does not know are
+			// ignored, as RFC 5545 asks of unknown extensions.
+		}
+		if err != nil {
+			return RRule{}, i18n.Errorf("잘못된 RRULE %s 값: %s", name, val)
+		}
+	}
+	if r.Freq == "" {
+		return RRule{}, i18n.Errorf("RRULE에 FREQ가 없습니다: %s", value)
+	}
+	return r, nil
+}
+
+// parseIntList parses a comma-separated list of integers in [min, max],
+// or in [-max, -min] too when negative is set.
+func parseIntList(s string, min, max int, negative bool) ([]int, error) {
+	var out []int
+	for _, item := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(strings.TrimPrefix(item, "+"))
+		if err != nil {
+			return nil, err
+		}
+		abs := n
+		if negative && n < 0 {
+			abs = -n
+		}
+		if abs < min || abs > max {
+			return nil, strconv.ErrRange
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+func parseWeekdayList(s string) ([]WeekdayNum, error) {
+	var out []WeekdayNum
+	for _, item := range strings.Split(s, ",") {
+		if len(item) < 2 {
+			return nil, strconv.ErrSyntax
+		}
+		day, ok := weekdayNames[item[len(item)-2:]]
+		if !ok {
+			return nil, strconv.ErrSyntax
+		}
+		w := WeekdayNum{Day: day}
+		if num := item[:len(item)-2]; num != "" {
+			n, err := strconv.Atoi(strings.TrimPrefix(num, "+"))
+			if err != nil || n == 0 || n > 53 || n < -53 {
+				return nil, strconv.ErrSyntax
+			}
+			w.N = n
+		}
+		out = append(out, w)
+	}
+	return out, nil
+}
+
+// Occurrences returns the start times of the series that begins at
+// dtstart, in order. dtstart itself is always the first one. Times are
+// computed on the wall clock of dtstart's location, so a series keeps its
+// local time across daylight saving changes. Generation stops at end
+// (exclusive, when non-zero) and after max instances (when positive).
+func (r RRule) Occurrences(dtstart, end time.Time, max int) []time.Time {
+	out := []time.Time{dtstart}
+	done := func() bool {
+		return (r.Count > 0 && len(out) >= r.Count) || (max > 0 && len(out) >= max)
+	}
+	if done() {
+		return out
+	}
+	empty := 0
+	for period := 0; empty < maxEmptyPeriods; period++ {
+		start := r.periodStart(dtstart, period*r.Interval)
+		if (!end.IsZero() && !start.Before(end)) || (!r.Until.IsZero() && start.After(r.Until)) {
+			break
+		}
+		candidates := r.expandPeriod(dtstart, start)
+		if len(candidates) == 0 {
+			empty++
+			continue
+		}
+		empty = 0
+		for _, t := range candidates {
+			if !t.After(dtstart) {
+				continue
+			}
+			if (!end.IsZero() && !t.Before(end)) || (!r.Until.IsZero() && t.After(r.Until)) {
+				return out
+			}
+			out = append(out, t)
+			if done() {
+				return out
+			}
+		}
+	}
+	return out
+}
+
+// periodStart returns the beginning of the n-th FREQ period after the one
+// containing dtstart.
+func (r RRule) periodStart(dtstart time.Time, n int) time.Time {
+	y, m, d := dtstart.Date()
+	h, mi, s := dtstart.Clock()
+	loc := dtstart.Location()
+	switch r.Freq {
+	case "YEARLY":
+		return time.Date(y+n, 1, 1, 0, 0, 0, 0, loc)
+	case "MONTHLY":
+		return time.Date(y, m+time.Month(n), 1, 0, 0, 0, 0, loc)
+	case "WEEKLY":
+		back := (int(dtstart.Weekday()) - int(r.WeekStart) + 7) % 7
+		return time.Date(y, m, d-back+7*n, 0, 0, 0, 0, loc)
+	case "DAILY":
+		return time.Date(y, m, d+n, 0, 0, 0, 0, loc)
+	case "HOURLY":
+		return time.Date(y, m, d, h, 0, 0, 0, loc).Add(time.Duration(n) * time.Hour)
+	case "MINUTELY":
+		return time.Date(y, m, d, h, mi, 0, 0, loc).Add(time.Duration(n) * time.Minute)
+	}
+	return time.Date(y, m, d, h, mi, s, 0, loc).Add(time.Duration(n) * time.Second)
+}
+
+// expandPeriod lists the instances of the period starting at start, in
+// order, with BYSETPOS applied.
+func (r RRule) expandPeriod(dtstart, start time.Time) []time.Time {
+	var days []time.Time
+	y, m, _ := start.Date()
+	loc := start.Location()
+	switch r.Freq {
+	case "YEARLY":
+		switch {
+		case len(r.ByYearDay) > 0:
+			n := daysIn(y, 0)
+			for _, yd := range r.ByYearDay {
+				if yd < 0 {
+					yd += n + 1
+				}
+				if yd >= 1 && yd <= n {
+					days = append(days, time.Date(y, 1, yd, 0, 0, 0, 0, loc))
+				}
+			}
+			days = r.limitDays(days, true, true, true)
+		case len(r.ByDay) > 0 && len(r.ByMonth) == 0 && len(r.ByMonthDay) == 0:
+			days = weekdaysIn(time.Date(y, 1, 1, 0, 0, 0, 0, loc), time.Date(y+1, 1, 1, 0, 0, 0, 0, loc), r.ByDay)
+		default:
+			months := r.ByMonth
+			if len(months) == 0 {
+				if len(r.ByMonthDay) > 0 || len(r.ByDay) > 0 {
+					months = []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+				} else {
+					months = []int{int(dtstart.Month())}
+				}
+			}
+			for _, month := range slices.Sorted(slices.Values(months)) {
+				days = append(days, r.monthDays(dtstart, y, time.Month(month), loc)...)
+			}
+		}
+	case "MONTHLY":
+		if len(r.ByMonth) > 0 && !slices.Contains(r.ByMonth, int(m)) {
+			return nil
+		}
+		days = r.monthDays(dtstart, y, m, loc)
+	case "WEEKLY":
+		weekdays := []WeekdayNum{{Day: dtstart.Weekday()}}
+		if len(r.ByDay) > 0 {
+			weekdays = r.ByDay
+		}
+		for i := range 7 {
+			day := start.AddDate(0, 0, i)
+			if slices.ContainsFunc(weekdays, func(w WeekdayNum) bool { return w.Day == day.Weekday() }) {
+				days = append(days, day)
+			}
+		}
+		days = r.limitDays(days, true, false, false)
+	case "DAILY":
+		days = r.limitDays([]time.Time{start}, true, true, true)
+	default:
+		// Sub-daily rules: start is the instance itself, limited by every
+		// BY part.
+		if len(r.limitDays([]time.Time{start}, true, true, true)) == 0 ||
+			!inList(r.ByHour, start.Hour()) || !inList(r.ByMinute, start.Minute()) {
+			return nil
+		}
+		var times []time.Time
+		switch r.Freq {
+		case "HOURLY":
+			times = r.withTimes(start, []int{start.Hour()}, orDefault(r.ByMinute, dtstart.Minute()), orDefault(r.BySecond, dtstart.Second()))
+		case "MINUTELY":
+			times = r.withTimes(start, []int{start.Hour()}, []int{start.Minute()}, orDefault(r.BySecond, dtstart.Second()))
+		default:
+			if inList(r.BySecond, start.Second()) {
+				times = []time.Time{start}
+			}
+		}
+		return r.setPos(times)
+	}
+
+	var times []time.Time
+	for _, day := range days {
+		times = append(times, r.withTimes(day, orDefault(r.ByHour, dtstart.Hour()), orDefault(r.ByMinute, dtstart.Minute()), orDefault(r.BySecond, dtstart.Second()))...)
+	}
+	slices.SortFunc(times, time.Time.Compare)
+	times = slices.CompactFunc(times, time.Time.Equal)
+	return r.setPos(times)
+}
+
+// monthDays lists the days of a month selected by BYMONTHDAY and BYDAY,
+// or dtstart's day of the month when neither is given.
+func (r RRule) monthDays(dtstart time.Time, y int, m time.Month, loc *time.Location) []time.Time {
+	first := time.Date(y, m, 1, 0, 0, 0, 0, loc)
+	n := daysIn(y, m)
+	var days []time.Time
+	switch {
+	case len(r.ByMonthDay) > 0:
+		for _, md := range r.ByMonthDay {
+			if md < 0 {
+				md += n + 1
+			}
+			if md >= 1 && md <= n {
+				days = append(days, first.AddDate(0, 0, md-1))
+			}
+		}
+		days = r.limitDays(days, false, false, true)
+	case len(r.ByDay) > 0:
+		days = weekdaysIn(first, first.AddDate(0, 1, 0), r.ByDay)
+	default:
+		// A month without the day (e.g. the 31st) has no instance.
+		if d := dtstart.Day(); d <= n {
+			days = append(days, first.AddDate(0, 0, d-1))
+		}
+	}
+	slices.SortFunc(days, time.Time.Compare)
+	return slices.CompactFunc(days, time.Time.Equal)
+}
+
+// limitDays keeps the days allowed by BYMONTH, BYMONTHDAY and BYDAY, as
+// far as each is asked for.
+func (r RRule) limitDays(days []time.Time, month, monthDay, weekday bool) []time.Time {
+	return slices.DeleteFunc(days, func(day time.Time) bool {
+		if month && !inList(r.ByMonth, int(day.Month())) {
+			return true
+		}
+		if monthDay && len(r.ByMonthDay) > 0 {
+			n := daysIn(day.Year(), day.Month())
+			if !slices.ContainsFunc(r.ByMonthDay, func(md int) bool { return md == day.Day() || md+n+1 == day.Day() }) {
+				return true
+			}
+		}
+		if weekday && len(r.ByDay) > 0 && !slices.ContainsFunc(r.ByDay, func(w WeekdayNum) bool { return w.Day == day.Weekday() }) {
+			return true
+		}
+		return false
+	})
+}
+
+func (r RRule) withTimes(day time.Time, hours, minutes, seconds []int) []time.Time {
+	y, m, d := day.Date()
+	var out []time.Time
+	for _, h := range hours {
+		for _, mi := range minutes {
+			for _, s := range seconds {
+				out = append(out, time.Date(y, m, d, h, mi, s, 0, day.Location()))
+			}
+		}
+	}
+	slices.SortFunc(out, time.Time.Compare)
+	return out
+}
+
+// setPos applies BYSETPOS to the sorted instances of one period.
+func (r RRule) setPos(times []time.Time) []time.Time {
+	if len(r.BySetPos) == 0 {
+		return times
+	}
+	var out []time.Time
+	for _, pos := range r.BySetPos {
+		i := pos - 1
+		if pos < 0 {
+			i = len(times) + pos
+		}
+		if i >= 0 && i < len(times) {
+			out = append(out, times[i])
+		}
+	}
+	slices.SortFunc(out, time.Time.Compare)
+	return slices.CompactFunc(out, time.Time.Equal)
+}
+
+// weekdaysIn lists the days in [from, to) matching days, where a numbered
+// item picks the n-th (or n-th last) such weekday of the range.
+func weekdaysIn(from, to time.Time, days []WeekdayNum) []time.Time {
+	var out []time.Time
+	for _, w := range days {
+		var matches []time.Time
+		for d := from; d.Before(to); d = d.AddDate(0, 0, 1) {
+			if d.Weekday() == w.Day {
+				matches = append(matches, d)
+			}
+		}
+		switch {
+		case w.N == 0:
+			out = append(out, matches...)
+		case w.N > 0 && w.N <= len(matches):
+			out = append(out, matches[w.N-1])
+		case w.N < 0 && -w.N <= len(matches):
+			out = append(out, matches[len(matches)+w.N])
+		}
+	}
+	slices.SortFunc(out, time.Time.Compare)
+	return slices.CompactFunc(out, time.Time.Equal)
+}
+
+// daysIn returns the number of days of month m of year y, or of the whole
+// year when m is 0.
+func daysIn(y int, m time.Month) int {
+	if m == 0 {
+		return time.Date(y, 12, 31, 0, 0, 0, 0, time.UTC).YearDay()
+	}
+	return time.Date(y, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func inList(list []int, n int) bool {
+	return len(list) == 0 || slices.Contains(list, n)
+}
+
+func orDefault(list []int, n int) []int {
+	if len(list) == 0 {
+		return []int{n}
+	}
+	return list
+}
@@ -0,0 +1,54 @@
+package calcut
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// searchProperties are the free-text properties SearchTokens reads.
+var searchProperties = []string{"SUMMARY", "LOCATION", "DESCRIPTION", "CATEGORIES", "COMMENT"}
+
+// textUnescaper decodes the TEXT escapes of RFC 5545 §3.3.11 well enough
+// to search the text; line breaks become spaces.
+var textUnescaper = strings.NewReplacer(`\,`, ",", `\;`, ";", `\n`, " ", `\N`, " ", `\\`, `\`)
+
+// SearchTokens returns the distinct lower-cased words of the summary,
+// location, description, categories and comments of e, in the order they
+// first appear, for a client-side search index. Words are runs of letters
+// and digits; single ASCII letters and digits are left out, and so are
+// words longer than maxTokenLen bytes, which are encoded data rather than
+// anything one would search for.
+func (e Event) SearchTokens() []string {
+	var tokens []string
+	seen := make(map[string]bool)
+	forEachTopLevelLine(e.Text, func(l logicalLine) {
+		if !slices.Contains(searchProperties, PropertyName(l.text)) {
+			return
+		}
+		_, value := splitContentLine(l.text)
+		for _, t := range Tokenize(textUnescaper.Replace(value)) {
+			if !seen[t] {
+				seen[t] = true
+				tokens = append(tokens, t)
+			}
+		}
+	})
+	return tokens
+}
+
+const maxTokenLen = 64
+
+// Tokenize splits text into lower-cased words as SearchTokens does,
+// keeping repeats.
+func Tokenize(text string) []string {
+	var tokens []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len(w) > 1 && len(w) <= maxTokenLen {
+			tokens = append(tokens, w)
+		}
+	}
+	return tokens
+}
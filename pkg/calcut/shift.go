@@ -0,0 +1,126 @@
+package calcut
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+)
+
+// shiftedProperties hold the dates and times of an event that move with
+// it. DTSTAMP, CREATED and LAST-MODIFIED record when the event was
+// edited, not when it happens, and stay.
+var shiftedProperties = []string{"DTSTART", "DTEND", "DUE", "RECURRENCE-ID", "EXDATE", "RDATE"}
+
+// ParseShift parses an offset to move events by: a length as
+// ParseEventDuration takes it, with an optional sign, such as "2h",
+// "-1d" or "-P1W".
+func ParseShift(s string) (time.Duration, error) {
+	v := strings.TrimSpace(s)
+	sign := time.Duration(1)
+	if rest, ok := strings.CutPrefix(v, "-"); ok {
+		sign, v = -1, rest
+	} else {
+		v = strings.TrimPrefix(v, "+")
+	}
+	d, err := ParseEventDuration(v)
+	if err != nil || strings.HasPrefix(v, "-") {
+		return 0, i18n.Errorf("잘못된 이동 값: %s (예: 2h, -1d, P1W)", s)
+	}
+	return sign * d, nil
+}
+
+// ShiftEvent moves event by d: its DTSTART, DTEND, DUE, RECURRENCE-ID,
+// EXDATE and RDATE values and the UNTIL of its RRULE and EXRULE, and
+// reports whether anything moved. Local and floating times move on the
+// wall clock, and dates by the whole days in d. A VALARM TRIGGER
+// relative to the start or end, a DURATION such as "-PT15M", already
+// follows the event and is kept as it is; an absolute one (VALUE=DATE-TIME)
+// is moved with the event so the reminder still fires as long before it.
+func ShiftEvent(event Event, d time.Duration) (Event, bool) {
+	if d == 0 {
+		return event, false
+	}
+	text := MapProperties(event.Text, true, func(name, value string) (string, bool) {
+		var out string
+		switch {
+		case name == "RRULE" || name == "EXRULE":
+			out = shiftUntil(value, d)
+		case name == "TRIGGER" && !isDurationValue(value):
+			out = shiftValue(value, d)
+		case slices.Contains(shiftedProperties, name):
+			out = shiftList(value, d)
+		default:
+			return "", false
+		}
+		return out, out != value
+	})
+	if text == event.Text {
+		return event, false
+	}
+	return NewEvent(text), true
+}
+
+// shiftList moves each value of a comma-separated list, and both ends of
+// an RDATE period ("start/end"), leaving the durations of periods as they
+// are.
+func shiftList(value string, d time.Duration) string {
+	parts := strings.Split(value, ",")
+	for i, part := range parts {
+		start, end, isPeriod := strings.Cut(part, "/")
+		start = shiftValue(start, d)
+		if isPeriod {
+			if !isDurationValue(end) {
+				end = shiftValue(end, d)
+			}
+			start += "/" + end
+		}
+		parts[i] = start
+	}
+	return strings.Join(parts, ",")
+}
+
+// shiftUntil moves the UNTIL of a recurrence rule, so the series keeps
+// its count of occurrences.
+func shiftUntil(rule string, d time.Duration) string {
+	parts := strings.Split(rule, ";")
+	for i, part := range parts {
+		if name, value, ok := strings.Cut(part, "="); ok && strings.EqualFold(name, "UNTIL") {
+			parts[i] = name + "=" + shiftValue(value, d)
+		}
+	}
+	return strings.Join(parts, ";")
+}
+
+// shiftValue moves one DATE or DATE-TIME value, keeping its form. Values
+// it cannot read are returned unchanged.
+func shiftValue(value string, d time.Duration) string {
+	v := strings.TrimSpace(value)
+	switch {
+	case len(v) == 8:
+		t, err := time.Parse("20060102", v)
+		if err != nil {
+			return value
+		}
+		return t.AddDate(0, 0, int(d/(24*time.Hour))).Format("20060102")
+	case strings.HasSuffix(v, "Z"):
+		t, err := time.Parse("20060102T150405Z", v)
+		if err != nil {
+			return value
+		}
+		return t.Add(d).Format("20060102T150405Z")
+	}
+	t, err := time.Parse("20060102T150405", v)
+	if err != nil {
+		return value
+	}
+	return t.Add(d).Format("20060102T150405")
+}
+
+// isDurationValue reports whether value is a DURATION rather than a DATE
+// or DATE-TIME.
+func isDurationValue(value string) bool {
+	v := strings.TrimLeft(strings.TrimSpace(value), "+-")
+	return strings.HasPrefix(strings.ToUpper(v), "P")
+}
@@ -0,0 +1,46 @@
+package calcut
+
+import "bytes"
+
+// sniffLength is how much of the input SniffInput needs.
+const sniffLength = 512
+
+// SniffInput recognizes from its first bytes an input that is not
+// iCalendar at all: the HTML of a login or error page served in place of
+// a calendar, other XML, JSON from an API, or a ZIP or gzip archive. It
+// returns a *ParseError saying what the input looks like, so that such
+// input fails clearly instead of yielding no events, or nil.
+func SniffInput(head []byte) error {
+	if msg := sniff(head); msg != "" {
+		return newParseError(0, msg)
+	}
+	return nil
+}
+
+// sniff returns the message of SniffInput, or "".
+func sniff(head []byte) string {
+	if len(head) > sniffLength {
+		head = head[:sniffLength]
+	}
+	switch {
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")):
+		return "입력이 ZIP 압축 파일입니다 — 압축을 풀어 안의 .ics 파일을 주세요"
+	case bytes.HasPrefix(head, []byte("\x1f\x8b")):
+		return "입력이 gzip 압축 파일입니다 — 압축을 풀어 주세요"
+	}
+	text := bytes.TrimLeft(bytes.TrimPrefix(head, []byte("\xef\xbb\xbf")), " \t\r\n")
+	if len(text) == 0 {
+		return ""
+	}
+	switch text[0] {
+	case '<':
+		lower := bytes.ToLower(text)
+		if bytes.Contains(lower, []byte("<html")) || bytes.Contains(lower, []byte("<!doctype html")) {
+			return "입력이 HTML 문서로 보입니다 — 캘린더 주소에 로그인이 필요했나요?"
+		}
+		return "입력이 XML 문서로 보입니다 — iCalendar(.ics) 형식만 읽을 수 있습니다"
+	case '{', '[':
+		return "입력이 JSON으로 보입니다 — iCalendar(.ics) 내보내기 주소가 맞나요?"
+	}
+	return ""
+}
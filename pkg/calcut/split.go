@@ -0,0 +1,709 @@
+package calcut
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SplitOptions controls how events are distributed over output files.
+type SplitOptions struct {
+	// Prefix is prepended to every generated filename.
+	Prefix string
+	// MaxBytes caps the size of each file and MaxEvents the number of
+	// events in it; a file is closed as soon as either limit is reached.
+	// With both zero every event group gets a file of its own.
+	MaxBytes  int64
+	MaxEvents int
+	// Contiguous keeps every size-based chunk a consecutive run of events
+	// in input order. Without it events fill the first chunk with room.
+	Contiguous bool
+	// Pack places the largest event groups first, each in the first chunk
+	// with room (first-fit decreasing), which usually needs the fewest
+	// chunks when event sizes vary widely. It overrides Contiguous; the
+	// events of each chunk stay in input order, and chunks are ordered by
+	// their first event.
+	Pack bool
+	// Related keeps events linked through RELATED-TO in the same file.
+	Related bool
+	// Reserve is added to the per-file overhead when planning by size, for
+	// callers that add header lines of their own to each file.
+	Reserve int64
+	// KeepAllTimezones puts every VTIMEZONE of the input in every file.
+	// By default a file only carries the zones its events reference.
+	KeepAllTimezones bool
+}
+
+// Chunk is one planned output file.
+type Chunk struct {
+	Filename string
+	// Key is the key the chunk was planned by with PlanByKey.
+	Key    string
+	Events []Event
+	// Timezones are the VTIMEZONE blocks the file embeds.
+	Timezones []string
+	// Size is the projected size of the rendered file, excluding any
+	// SplitOptions.Reserve.
+	Size int64
+	// Oversized is set when a single event group alone exceeds MaxBytes.
+	Oversized bool
+
+	zones zoneSet
+}
+
+// SplitResult is a rendered output file.
+type SplitResult struct {
+	Filename string
+	Content  string
+	Events   int
+	Size     int
+}
+
+// GroupEvents partitions events into groups that must be written to the
+// same output file. Events sharing a UID always share a group: a recurring
+// series and its RECURRENCE-ID overrides only make sense together. With
+// related set, events linked through RELATED-TO (in either direction) do
+// too. Groups are ordered by their first member and keep members in input
+// order.
+func GroupEvents(events []Event, related bool) [][]Event {
+	parent := make([]int, len(events))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	union := func(a, b int) {
+		ra, rb := find(a), find(b)
+		if ra == rb {
+			return
+		}
+		if ra < rb {
+			parent[rb] = ra
+		} else {
+			parent[ra] = rb
+		}
+	}
+
+	byUID := make(map[string]int)
+	for i, event := range events {
+		if event.UID == "" {
+			continue
+		}
+		if j, ok := byUID[event.UID]; ok {
+			union(i, j)
+		} else {
+			byUID[event.UID] = i
+		}
+	}
+
+	if related {
+		for i, event := range events {
+			for _, uid := range event.RelatedTo {
+				if j, ok := byUID[uid]; ok {
+					union(i, j)
+				}
+			}
+		}
+	}
+
+	var groups [][]Event
+	index := make(map[int]int)
+	for i, event := range events {
+		root := find(i)
+		g, ok := index[root]
+		if !ok {
+			g = len(groups)
+			index[root] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], event)
+	}
+	return groups
+}
+
+// eventsSize is the number of bytes events take in a file whose lines end
+// with eol.
+func eventsSize(events []Event, eol string) int64 {
+	var n int64
+	for _, event := range events {
+		n += int64(encodedLen(event.Text, eol) + len(eol))
+	}
+	return n
+}
+
+// Plan groups the events of parsed and distributes them over files
+// according to opts.
+func Plan(parsed ParsedCalendar, opts SplitOptions) []Chunk {
+	groups := GroupEvents(parsed.Events, opts.Related)
+	if opts.MaxBytes > 0 || opts.MaxEvents > 0 {
+		return PlanBySize(parsed, groups, opts)
+	}
+	return PlanPerEvent(parsed, groups, opts)
+}
+
+// PlanBySize packs event groups into chunks of at most opts.MaxBytes and
+// opts.MaxEvents (where set); a group is never split across chunks, so a
+// group larger than either limit gets a chunk of its own. When opts.Contiguous is set every
+// chunk holds a consecutive run of groups in input order, so a date-sorted
+// input yields chunks covering disjoint date ranges. Otherwise each group
+// goes into the first chunk that still has room.
+func PlanBySize(parsed ParsedCalendar, groups [][]Event, opts SplitOptions) []Chunk {
+	if opts.Pack {
+		return packBySize(parsed, groups, opts)
+	}
+	if opts.Contiguous {
+		c := NewSizeChunker(parsed, opts)
+		var chunks []Chunk
+		for _, group := range groups {
+			chunks = append(chunks, c.Add(group)...)
+		}
+		return append(chunks, c.Flush()...)
+	}
+
+	limits := newChunkLimits(parsed, opts)
+	var chunks []Chunk
+
+	for _, group := range groups {
+		refs := limits.zones.refs(group)
+		eventBytes := eventsSize(group, limits.zones.eol)
+
+		if bytes, zones := limits.freshCost(refs, eventBytes); limits.oversized(bytes) {
+			chunk := limits.newChunk()
+			limits.add(&chunk, group, bytes, zones)
+			chunk.Oversized = true
+			chunks = append(chunks, chunk)
+			continue
+		}
+
+		target := -1
+		for i, c := range chunks {
+			if c.Oversized {
+				continue
+			}
+			if bytes, _ := limits.cost(c, refs, eventBytes); limits.fits(c, bytes, len(group)) {
+				target = i
+				break
+			}
+		}
+		if target < 0 {
+			chunks = append(chunks, limits.newChunk())
+			target = len(chunks) - 1
+		}
+
+		bytes, zones := limits.cost(chunks[target], refs, eventBytes)
+		limits.add(&chunks[target], group, bytes, zones)
+	}
+
+	for i := range chunks {
+		chunks[i].Filename = sizeChunkName(opts.Prefix, i+1)
+	}
+	return chunks
+}
+
+// packBySize is PlanBySize with Pack set. Groups are placed largest
+// first as without Contiguous, then each chunk is rebuilt with its groups
+// in input order, which costs the same bytes.
+func packBySize(parsed ParsedCalendar, groups [][]Event, opts SplitOptions) []Chunk {
+	limits := newChunkLimits(parsed, opts)
+	refs := make([][]int, len(groups))
+	sizes := make([]int64, len(groups))
+	costs := make([]int64, len(groups))
+	order := make([]int, len(groups))
+	for i, group := range groups {
+		refs[i] = limits.zones.refs(group)
+		sizes[i] = eventsSize(group, limits.zones.eol)
+		costs[i], _ = limits.freshCost(refs[i], sizes[i])
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int { return cmp.Compare(costs[b], costs[a]) })
+
+	var bins []Chunk
+	var members [][]int
+	for _, i := range order {
+		target := -1
+		oversized := limits.oversized(costs[i])
+		for b, c := range bins {
+			if oversized || c.Oversized {
+				continue
+			}
+			if bytes, _ := limits.cost(c, refs[i], sizes[i]); limits.fits(c, bytes, len(groups[i])) {
+				target = b
+				break
+			}
+		}
+		if target < 0 {
+			bins = append(bins, limits.newChunk())
+			members = append(members, nil)
+			target = len(bins) - 1
+			bins[target].Oversized = oversized
+		}
+		bytes, zones := limits.cost(bins[target], refs[i], sizes[i])
+		limits.add(&bins[target], groups[i], bytes, zones)
+		members[target] = append(members[target], i)
+	}
+
+	chunks, order := limits.rebuild(groups, refs, sizes, members, opts.Prefix)
+	for n, b := range order {
+		chunks[n].Oversized = bins[b].Oversized
+	}
+	return chunks
+}
+
+// PlanParts distributes event groups over exactly n chunks (fewer only if
+// there are fewer groups) of about equal size: in bytes, or with byEvents
+// in events. Groups are placed largest first, each in the chunk holding
+// the least so far; the events of each chunk stay in input order, and
+// chunks are ordered by their first event. MaxBytes and MaxEvents are not
+// applied.
+func PlanParts(parsed ParsedCalendar, groups [][]Event, n int, byEvents bool, opts SplitOptions) []Chunk {
+	n = min(n, len(groups))
+	if n <= 0 {
+		return nil
+	}
+	limits := newChunkLimits(parsed, SplitOptions{KeepAllTimezones: opts.KeepAllTimezones})
+	refs := make([][]int, len(groups))
+	sizes := make([]int64, len(groups))
+	weights := make([]int64, len(groups))
+	order := make([]int, len(groups))
+	for i, group := range groups {
+		refs[i] = limits.zones.refs(group)
+		sizes[i] = eventsSize(group, limits.zones.eol)
+		weights[i], _ = limits.freshCost(refs[i], sizes[i])
+		if byEvents {
+			weights[i] = int64(len(group))
+		}
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int { return cmp.Compare(weights[b], weights[a]) })
+
+	loads := make([]int64, n)
+	members := make([][]int, n)
+	for _, i := range order {
+		target := 0
+		for b := range loads {
+			if loads[b] < loads[target] {
+				target = b
+			}
+		}
+		loads[target] += weights[i]
+		members[target] = append(members[target], i)
+	}
+	chunks, _ := limits.rebuild(groups, refs, sizes, members, opts.Prefix)
+	return chunks
+}
+
+// rebuild makes a chunk of each bin, members[b] holding the indices of
+// the groups placed in it, with the groups in input order. Chunks are
+// ordered by their first group and named as PlanBySize names them;
+// order[k] is the bin chunk k was made of.
+func (l chunkLimits) rebuild(groups [][]Event, refs [][]int, sizes []int64, members [][]int, prefix string) (chunks []Chunk, order []int) {
+	order = make([]int, len(members))
+	for b, m := range members {
+		slices.Sort(m)
+		order[b] = b
+	}
+	slices.SortFunc(order, func(a, b int) int { return cmp.Compare(members[a][0], members[b][0]) })
+	chunks = make([]Chunk, len(members))
+	for k, b := range order {
+		chunk := l.newChunk()
+		for _, i := range members[b] {
+			bytes, zones := l.cost(chunk, refs[i], sizes[i])
+			l.add(&chunk, groups[i], bytes, zones)
+		}
+		chunk.Filename = sizeChunkName(prefix, k+1)
+		chunks[k] = chunk
+	}
+	return chunks, order
+}
+
+// chunkLimits decides what fits in a chunk and prices event groups: a
+// group costs its own bytes plus those of the VTIMEZONEs it brings in.
+type chunkLimits struct {
+	zones     zoneTable
+	base      zoneSet
+	baseZones []string
+	skelSize  int64 // header and base zones
+	sized     bool  // MaxBytes is set
+	budget    int64
+	maxEvents int
+}
+
+func newChunkLimits(parsed ParsedCalendar, opts SplitOptions) chunkLimits {
+	zones := newZoneTable(parsed, opts.KeepAllTimezones)
+	base := zones.base()
+	l := chunkLimits{
+		zones:     zones,
+		base:      base,
+		baseZones: zones.blocksOf(base),
+		maxEvents: opts.MaxEvents,
+	}
+	l.skelSize = int64(len(buildICS(parsed.HeaderLines, l.baseZones, nil, parsed.lineEnding())))
+	if opts.MaxBytes > 0 {
+		l.sized = true
+		l.budget = opts.MaxBytes - opts.Reserve
+	}
+	return l
+}
+
+// newChunk returns an empty chunk carrying the base zones.
+func (l chunkLimits) newChunk() Chunk {
+	set := make(zoneSet, len(l.base))
+	for i := range l.base {
+		set[i] = true
+	}
+	return Chunk{Timezones: l.baseZones, Size: l.skelSize, zones: set}
+}
+
+// cost returns the bytes a group of eventBytes referencing the zones refs
+// adds to c, and the zones c has to take in for it.
+func (l chunkLimits) cost(c Chunk, refs []int, eventBytes int64) (int64, []int) {
+	var zones []int
+	for _, i := range refs {
+		if !c.zones[i] {
+			zones = append(zones, i)
+		}
+	}
+	return eventBytes + l.zones.size(zones), zones
+}
+
+// freshCost is cost for an empty chunk.
+func (l chunkLimits) freshCost(refs []int, eventBytes int64) (int64, []int) {
+	return l.cost(Chunk{zones: l.base}, refs, eventBytes)
+}
+
+// add appends group to c at the price cost returned.
+func (l chunkLimits) add(c *Chunk, group []Event, bytes int64, zones []int) {
+	c.Events = append(c.Events, group...)
+	c.Size += bytes
+	if len(zones) > 0 {
+		for _, i := range zones {
+			c.zones[i] = true
+		}
+		c.Timezones = l.zones.blocksOf(c.zones)
+	}
+}
+
+// oversized reports whether a group costing bytes exceeds MaxBytes even
+// in a chunk of its own.
+func (l chunkLimits) oversized(bytes int64) bool {
+	return l.sized && l.skelSize+bytes > l.budget
+}
+
+// fits reports whether a group of n events costing bytes can join c.
+func (l chunkLimits) fits(c Chunk, bytes int64, n int) bool {
+	if l.sized && c.Size+bytes > l.budget {
+		return false
+	}
+	return l.maxEvents <= 0 || len(c.Events)+n <= l.maxEvents
+}
+
+func sizeChunkName(prefix string, idx int) string {
+	if prefix == "" {
+		prefix = "part"
+	}
+	// The number must survive, so a long prefix is what gets cut.
+	prefix = truncateName(prefix, maxFilenameBytes-len("_0000.ics_ffffff"))
+	return fmt.Sprintf("%s_%03d.ics", prefix, idx)
+}
+
+// SizeChunker is the incremental form of PlanBySize with Contiguous set:
+// groups are added one at a time and each chunk is handed back as soon as
+// it is complete, so only the chunk being filled is held in memory.
+type SizeChunker struct {
+	// DropText leaves the Text of the events out of the chunks, for
+	// callers that keep it elsewhere, such as in a file, until the chunk
+	// is written. The chunks then hold no more than a few fields per
+	// event.
+	DropText bool
+
+	limits  chunkLimits
+	prefix  string
+	emitted int
+	cur     *Chunk
+}
+
+// NewSizeChunker plans chunks for the skeleton of parsed; its events are
+// ignored.
+func NewSizeChunker(parsed ParsedCalendar, opts SplitOptions) *SizeChunker {
+	return &SizeChunker{
+		limits: newChunkLimits(parsed, opts),
+		prefix: opts.Prefix,
+	}
+}
+
+// Add appends group and returns the chunks completed by doing so, if any.
+func (c *SizeChunker) Add(group []Event) []Chunk {
+	refs := c.limits.zones.refs(group)
+	eventBytes := eventsSize(group, c.limits.zones.eol)
+
+	if bytes, zones := c.limits.freshCost(refs, eventBytes); c.limits.oversized(bytes) {
+		done := c.Flush()
+		chunk := c.limits.newChunk()
+		c.limits.add(&chunk, group, bytes, zones)
+		chunk.Oversized = true
+		c.dropText(chunk.Events)
+		return append(done, c.emit(chunk))
+	}
+
+	var done []Chunk
+	if c.cur != nil {
+		if bytes, _ := c.limits.cost(*c.cur, refs, eventBytes); !c.limits.fits(*c.cur, bytes, len(group)) {
+			done = c.Flush()
+		}
+	}
+	if c.cur == nil {
+		chunk := c.limits.newChunk()
+		c.cur = &chunk
+	}
+	bytes, zones := c.limits.cost(*c.cur, refs, eventBytes)
+	c.limits.add(c.cur, group, bytes, zones)
+	c.dropText(c.cur.Events[len(c.cur.Events)-len(group):])
+	return done
+}
+
+// dropText clears the Text of events just added, when DropText is set.
+// The fields kept are copied, as they point into the text.
+func (c *SizeChunker) dropText(events []Event) {
+	if !c.DropText {
+		return
+	}
+	for i := range events {
+		e := &events[i]
+		e.Text = ""
+		e.Summary, e.UID, e.DTStart = strings.Clone(e.Summary), strings.Clone(e.UID), strings.Clone(e.DTStart)
+		if len(e.RelatedTo) > 0 {
+			related := make([]string, len(e.RelatedTo))
+			for j, r := range e.RelatedTo {
+				related[j] = strings.Clone(r)
+			}
+			e.RelatedTo = related
+		}
+	}
+}
+
+// Flush returns the chunk being filled, if any.
+func (c *SizeChunker) Flush() []Chunk {
+	if c.cur == nil {
+		return nil
+	}
+	chunk := *c.cur
+	c.cur = nil
+	return []Chunk{c.emit(chunk)}
+}
+
+func (c *SizeChunker) emit(chunk Chunk) Chunk {
+	c.emitted++
+	chunk.Filename = sizeChunkName(c.prefix, c.emitted)
+	return chunk
+}
+
+// PlanPerEvent puts every event group in a file of its own, named after
+// the group's first event.
+func PlanPerEvent(parsed ParsedCalendar, groups [][]Event, opts SplitOptions) []Chunk {
+	limits := newChunkLimits(parsed, opts)
+	chunks := make([]Chunk, len(groups))
+	for i, group := range groups {
+		chunk := limits.newChunk()
+		bytes, zones := limits.freshCost(limits.zones.refs(group), eventsSize(group, limits.zones.eol))
+		limits.add(&chunk, group, bytes, zones)
+		chunk.Filename = PerEventFilename(opts.Prefix, i+1, group[0])
+		chunks[i] = chunk
+	}
+	return chunks
+}
+
+// PerEventFilename names the idx-th file of a per-event split after its
+// event's summary.
+func PerEventFilename(prefix string, idx int, event Event) string {
+	summaryPart := "event"
+	if event.Summary != "" {
+		summaryPart = SanitizeFilename(UnescapeText(event.Summary))
+	}
+	if prefix != "" {
+		prefix = truncateName(prefix, maxFilenameBytes-len("_0000_.ics_ffffff")-len(summaryPart))
+		return fmt.Sprintf("%s_%03d_%s.ics", prefix, idx, summaryPart)
+	}
+	return fmt.Sprintf("%03d_%s.ics", idx, summaryPart)
+}
+
+// PlanByKey writes one file per distinct key, in order of first
+// appearance; keys[i] is the key of groups[i]. With opts.MaxBytes or
+// opts.MaxEvents set, the events of each key are further split by size
+// into files numbered per key, such as 2024-03_part_001.ics and
+// 2024-03_part_002.ics.
+func PlanByKey(parsed ParsedCalendar, groups [][]Event, keys []string, opts SplitOptions) []Chunk {
+	var order []string
+	byKey := make(map[string][][]Event)
+	for i, group := range groups {
+		key := keys[i]
+		if _, ok := byKey[key]; !ok {
+			order = append(order, key)
+		}
+		byKey[key] = append(byKey[key], group)
+	}
+
+	limits := newChunkLimits(parsed, opts)
+	var chunks []Chunk
+	for _, key := range order {
+		name := SanitizeFilename(key)
+		if opts.Prefix != "" {
+			name = opts.Prefix + "_" + name
+		}
+		if limits.sized || limits.maxEvents > 0 {
+			sub := opts
+			sub.Prefix = name + "_part"
+			parts := PlanBySize(parsed, byKey[key], sub)
+			for i := range parts {
+				parts[i].Key = key
+			}
+			chunks = append(chunks, parts...)
+			continue
+		}
+		chunk := limits.newChunk()
+		chunk.Filename = limitFilename(name + ".ics")
+		chunk.Key = key
+		for _, group := range byKey[key] {
+			bytes, zones := limits.cost(chunk, limits.zones.refs(group), eventsSize(group, limits.zones.eol))
+			limits.add(&chunk, group, bytes, zones)
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// UndatedBucket collects the events of PlanByPeriod whose DTSTART is
+// missing or unreadable.
+const UndatedBucket = "undated"
+
+// PlanByPeriod groups by the calendar period, as PeriodKey names it, in
+// which the first event of each group starts in loc, and then chunks each
+// period as PlanByKey does: one file per period, or with opts.MaxBytes or
+// opts.MaxEvents set, parts of at most that size, such as per-month files
+// each capped at 1 MB. Periods are in chronological order, undated last.
+func PlanByPeriod(parsed ParsedCalendar, groups [][]Event, by string, loc *time.Location, opts SplitOptions) ([]Chunk, error) {
+	if _, err := PeriodKey(time.Time{}, by); err != nil {
+		return nil, err
+	}
+	keys := make([]string, len(groups))
+	for i, group := range groups {
+		start, _, err := group[0].Start(loc)
+		if err != nil {
+			keys[i] = UndatedBucket
+			continue
+		}
+		keys[i], _ = PeriodKey(start, by)
+	}
+	chunks := PlanByKey(parsed, groups, keys, opts)
+	SortChunksByKey(chunks, UndatedBucket)
+	return chunks, nil
+}
+
+// SortChunksByKey orders chunks planned by key by file name, with those of
+// the catch-all key last (none if last is empty). The parts of a key split
+// by size stay in order as their names are numbered.
+func SortChunksByKey(chunks []Chunk, last string) {
+	sort.SliceStable(chunks, func(i, j int) bool {
+		if (chunks[i].Key == last) != (chunks[j].Key == last) {
+			return chunks[j].Key == last
+		}
+		return chunks[i].Filename < chunks[j].Filename
+	})
+}
+
+// Render builds the file contents of planned chunks.
+func Render(parsed ParsedCalendar, chunks []Chunk) []SplitResult {
+	results := make([]SplitResult, len(chunks))
+	for i, c := range chunks {
+		content := parsed.BuildChunk(c)
+		results[i] = SplitResult{
+			Filename: c.Filename,
+			Content:  content,
+			Events:   len(c.Events),
+			Size:     len(content),
+		}
+	}
+	return results
+}
+
+// SplitBySize splits parsed into files of at most opts.MaxBytes and
+// opts.MaxEvents.
+func SplitBySize(parsed ParsedCalendar, opts SplitOptions) []SplitResult {
+	return Render(parsed, PlanBySize(parsed, GroupEvents(parsed.Events, opts.Related), opts))
+}
+
+// SplitPerEvent writes every event (group) to a file of its own.
+func SplitPerEvent(parsed ParsedCalendar, opts SplitOptions) []SplitResult {
+	return Render(parsed, PlanPerEvent(parsed, GroupEvents(parsed.Events, opts.Related), opts))
+}
+
+// SortByStart returns a copy of events ordered by when they start, as
+// Event.Start reads DTSTART in loc, so that times in different zones and
+// all-day dates are ordered by the instant they denote. Events whose
+// DTSTART is missing or unreadable sort first; ties are ordered by the
+// DTSTART text, then keep their input order.
+func SortByStart(events []Event, loc *time.Location) []Event {
+	type keyed struct {
+		event Event
+		start time.Time
+		ok    bool
+	}
+	keys := make([]keyed, len(events))
+	for i, event := range events {
+		start, _, err := event.Start(loc)
+		keys[i] = keyed{event, start, err == nil}
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.ok != b.ok {
+			return !a.ok
+		}
+		if !a.start.Equal(b.start) {
+			return a.start.Before(b.start)
+		}
+		return a.event.DTStart < b.event.DTStart
+	})
+	sorted := make([]Event, len(keys))
+	for i, k := range keys {
+		sorted[i] = k.event
+	}
+	return sorted
+}
+
+// DateRange returns the earliest and latest DTSTART dates of events as
+// YYYY-MM-DD, ignoring events without a usable DTSTART.
+func DateRange(events []Event) (from, to string) {
+	for _, event := range events {
+		d := dateOf(event.DTStart)
+		if d == "" {
+			continue
+		}
+		if from == "" || d < from {
+			from = d
+		}
+		if to == "" || d > to {
+			to = d
+		}
+	}
+	return from, to
+}
+
+func dateOf(dtstart string) string {
+	if len(dtstart) < 8 {
+		return ""
+	}
+	d := dtstart[:8]
+	for _, c := range d {
+		if c < '0' || c > '9' {
+			return ""
+		}
+	}
+	return d[:4] + "-" + d[4:6] + "-" + d[6:8]
+}
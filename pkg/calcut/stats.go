@@ -0,0 +1,115 @@
+package calcut
+
+import (
+	"cmp"
+	"slices"
+)
+
+// SizeBuckets are the upper bounds, in bytes, of the event size histogram
+// of CalendarStats. Events larger than the last bound go in a final
+// bucket without one.
+var SizeBuckets = []int64{1 << 10, 4 << 10, 16 << 10, 64 << 10}
+
+// CalendarStats summarizes a calendar, for a report before splitting it.
+type CalendarStats struct {
+	Events int `json:"events"`
+	// UIDs counts distinct UIDs, so a series and its overrides count once.
+	UIDs int `json:"uids"`
+	// Components counts the events by kind (VEVENT, VTODO, ...).
+	Components map[string]int `json:"components"`
+	// From and To are the earliest and latest DTSTART dates as
+	// YYYY-MM-DD.
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+	// Recurring counts components with RRULE or RDATE, Overrides those
+	// with RECURRENCE-ID.
+	Recurring int `json:"recurring"`
+	Overrides int `json:"overrides"`
+	// Sizes is the event size histogram over SizeBuckets; the last entry
+	// has no UpTo.
+	Sizes []SizeBucket `json:"sizes"`
+	// Largest lists the largest events, largest first.
+	Largest []EventSize `json:"largest"`
+	// TZIDs are the TZID parameters events use, in order of first use,
+	// and Timezones the number of VTIMEZONE blocks the calendar defines.
+	TZIDs     []string `json:"tzids"`
+	Timezones int      `json:"timezones"`
+	ProdID    string   `json:"prodid,omitempty"`
+}
+
+// SizeBucket counts the events of at most UpTo bytes that do not fit a
+// smaller bucket. UpTo is zero for the last bucket.
+type SizeBucket struct {
+	UpTo   int64 `json:"upTo,omitempty"`
+	Events int   `json:"events"`
+}
+
+// EventSize is the size of one event as written in the calendar, with its
+// summary unescaped.
+type EventSize struct {
+	UID     string `json:"uid"`
+	Summary string `json:"summary"`
+	Size    int64  `json:"size"`
+}
+
+// Stats summarizes p, listing its top largest events.
+func (p ParsedCalendar) Stats(top int) CalendarStats {
+	s := CalendarStats{
+		Events:     len(p.Events),
+		Components: make(map[string]int),
+		Sizes:      make([]SizeBucket, len(SizeBuckets)+1),
+		Largest:    []EventSize{},
+		TZIDs:      []string{},
+		Timezones:  len(p.Timezones),
+	}
+	for i, upTo := range SizeBuckets {
+		s.Sizes[i].UpTo = upTo
+	}
+	for _, line := range p.HeaderLines {
+		if PropertyName(line) == "PRODID" {
+			_, s.ProdID = splitContentLine(line)
+		}
+	}
+	s.From, s.To = DateRange(p.Events)
+
+	uids := make(map[string]bool)
+	sizes := make([]EventSize, 0, len(p.Events))
+	for _, e := range p.Events {
+		s.Components[e.Kind]++
+		if e.UID != "" {
+			uids[e.UID] = true
+		}
+		recurring, override := false, false
+		for _, prop := range TopLevelProperties(e.Text) {
+			switch prop.Name {
+			case "RRULE", "RDATE":
+				recurring = true
+			case "RECURRENCE-ID":
+				override = true
+			}
+		}
+		if recurring {
+			s.Recurring++
+		}
+		if override {
+			s.Overrides++
+		}
+		for _, id := range e.TZIDs() {
+			if !slices.Contains(s.TZIDs, id) {
+				s.TZIDs = append(s.TZIDs, id)
+			}
+		}
+		size := int64(len(e.Text)) + 1
+		bucket, _ := slices.BinarySearch(SizeBuckets, size)
+		s.Sizes[bucket].Events++
+		sizes = append(sizes, EventSize{UID: e.UID, Summary: UnescapeText(e.Summary), Size: size})
+	}
+	s.UIDs = len(uids)
+
+	slices.SortStableFunc(sizes, func(a, b EventSize) int { return cmp.Compare(b.Size, a.Size) })
+	if top = max(top, 0); len(sizes) > top {
+		sizes = sizes[:top]
+	}
+	s.Largest = append(s.Largest, sizes...)
+	return s
+}
@@ -0,0 +1,231 @@
+package calcut
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"slices"
+	"strings"
+)
+
+// Stream parses an iCalendar document incrementally, handing out one event
+// at a time so memory stays bounded by the largest component rather than
+// the whole input. Blocks are kept exactly as ParseBytes keeps them, byte
+// for byte but for the line endings.
+//
+// Header lines, timezones and warnings are collected as they are read;
+// Calendar returns those seen so far. Exports put them before the first event, but
+// ones that only appear later are not visible to events read before them.
+type Stream struct {
+	sc      *bufio.Scanner
+	limits  ParseLimits
+	scanner componentScanner
+
+	next    string // physical line read ahead to detect continuations
+	hasNext bool
+	eof     bool
+	lineNo  int
+
+	block      strings.Builder
+	header     []string
+	timezones  []string
+	lineEnding string
+	calendars  int // VCALENDARs begun so far
+	err        error
+}
+
+// ParseICalStream parses a trusted iCalendar document from r without any
+// limits.
+func ParseICalStream(r io.Reader) *Stream {
+	return NewStream(r, ParseLimits{})
+}
+
+// NewStream parses an iCalendar document from r while enforcing limits.
+// MaxComponents and MaxDepth apply as in ParseBytes; MaxBytes is checked
+// as the input is read. Input SniffInput rejects fails on the first Next.
+func NewStream(r io.Reader, limits ParseLimits) *Stream {
+	if limits.MaxBytes > 0 {
+		r = &sizeLimitedReader{r: r, max: limits.MaxBytes}
+	}
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(sniffLength)
+	sc := bufio.NewScanner(br)
+	maxLine := math.MaxInt - 1
+	if limits.MaxLineLength > 0 {
+		maxLine = limits.MaxLineLength
+	}
+	// +1 for the newline that has to fit in the buffer to end the line.
+	sc.Buffer(nil, maxLine+1)
+	sc.Split(scanRawLines)
+	return &Stream{
+		sc:      sc,
+		limits:  limits,
+		scanner: componentScanner{limits: limits},
+		err:     SniffInput(head),
+	}
+}
+
+// Next returns the next event. At the end of the input it returns io.EOF;
+// any other error is final and returned again by later calls.
+func (s *Stream) Next() (Event, error) {
+	if s.err != nil {
+		return Event{}, s.err
+	}
+	for {
+		line, ok := s.readLogical()
+		if !ok {
+			s.err = s.scanErr()
+			if s.err == io.EOF {
+				if err := s.scanner.finish(s.lineNo); err != nil {
+					s.err = err
+				}
+			}
+			return Event{}, s.err
+		}
+		kind, err := s.scanner.next(strings.TrimSpace(Unfold(line)), s.lineNo)
+		if err != nil {
+			s.err = err
+			return Event{}, err
+		}
+		switch kind {
+		case lineCalendar:
+			s.calendars++
+		case lineHeader:
+			if s.calendars > 1 {
+				// A later calendar of the document only adds what the
+				// header lacks, as JoinCalendars does.
+				s.header = joinHeader(s.header, []string{line})
+			} else {
+				s.header = append(s.header, line)
+			}
+		case lineBlockStart:
+			s.block.Reset()
+			s.block.WriteString(line)
+		case lineBlock, lineBlockEnd:
+			s.block.WriteByte('\n')
+			s.block.WriteString(line)
+		}
+		if kind != lineBlockEnd {
+			continue
+		}
+		switch s.scanner.blockType {
+		case "VTIMEZONE":
+			block := s.block.String()
+			id := ExtractProperty(block, "TZID")
+			if s.calendars > 1 && id != "" && slices.ContainsFunc(s.timezones, func(tz string) bool { return ExtractProperty(tz, "TZID") == id }) {
+				continue
+			}
+			s.timezones = append(s.timezones, block)
+		default:
+			if slices.Contains(ComponentKinds, s.scanner.blockType) {
+				return NewEvent(s.block.String()), nil
+			}
+		}
+	}
+}
+
+// Calendar returns the header lines and timezones read so far, without
+// events.
+func (s *Stream) Calendar() ParsedCalendar {
+	return ParsedCalendar{
+		HeaderLines: append([]string(nil), s.header...),
+		Timezones:   append([]string(nil), s.timezones...),
+		LineEnding:  s.lineEnding,
+		Warnings:    append([]*ParseError(nil), s.scanner.warnings...),
+	}
+}
+
+// readLogical returns the next logical line with its continuation lines
+// still folded in, as ParseBytes slices it from its input.
+func (s *Stream) readLogical() (string, bool) {
+	if !s.peek() {
+		return "", false
+	}
+	line := s.consume()
+	if !s.peek() || !isContinuation(s.next) {
+		return line, true
+	}
+	var b strings.Builder
+	b.WriteString(line)
+	for s.peek() && isContinuation(s.next) {
+		b.WriteByte('\n')
+		b.WriteString(s.consume())
+	}
+	return b.String(), true
+}
+
+func (s *Stream) peek() bool {
+	if s.hasNext || s.eof {
+		return s.hasNext
+	}
+	// After an error the scanner would hand out the unterminated rest of
+	// its buffer as a final line, so it is never asked again.
+	if !s.sc.Scan() {
+		s.eof = true
+		return false
+	}
+	s.next = s.sc.Text()
+	s.hasNext = true
+	return true
+}
+
+func (s *Stream) consume() string {
+	s.hasNext = false
+	s.lineNo++
+	line, cr := strings.CutSuffix(s.next, "\r")
+	if s.lineNo == 1 {
+		s.lineEnding = "\n"
+		if cr {
+			s.lineEnding = "\r\n"
+		}
+	}
+	return line
+}
+
+func (s *Stream) scanErr() error {
+	err := s.sc.Err()
+	switch {
+	case err == nil:
+		return io.EOF
+	case errors.Is(err, bufio.ErrTooLong):
+		return newParseError(s.lineNo+1, "줄이 너무 깁니다 (최대 %d bytes)", s.limits.MaxLineLength)
+	}
+	return err
+}
+
+func isContinuation(line string) bool {
+	return line != "" && (line[0] == ' ' || line[0] == '\t')
+}
+
+// scanRawLines is bufio.ScanLines without dropping the carriage return, so
+// that the stream can tell CRLF input from LF input.
+func scanRawLines(data []byte, atEOF bool) (int, []byte, error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// sizeLimitedReader fails once more than max bytes have been read.
+type sizeLimitedReader struct {
+	r   io.Reader
+	n   int64
+	max int64
+}
+
+func (l *sizeLimitedReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.n += int64(n)
+	if l.n > l.max {
+		return n, newParseError(0, "입력이 너무 큽니다 (최대 %d bytes)", l.max)
+	}
+	return n, err
+}
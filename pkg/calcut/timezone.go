@@ -0,0 +1,124 @@
+package calcut
+
+import (
+	"slices"
+	"strings"
+)
+
+// tzidProperties are the event properties whose TZID parameter names a
+// VTIMEZONE of the calendar (RFC 5545 §3.2.19).
+var tzidProperties = []string{"DTSTART", "DTEND", "DUE", "RECURRENCE-ID", "EXDATE", "RDATE"}
+
+// TZIDs returns the distinct TZID parameters of e's date-time properties,
+// in order of appearance.
+func (e Event) TZIDs() []string {
+	if !strings.Contains(e.Text, "TZID=") {
+		return nil
+	}
+	var ids []string
+	forEachTopLevelLine(e.Text, func(l logicalLine) {
+		if !slices.Contains(tzidProperties, PropertyName(l.text)) {
+			return
+		}
+		params, _ := splitContentLine(l.text)
+		if id := params["TZID"]; id != "" && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	})
+	return ids
+}
+
+// TimezonesFor returns the VTIMEZONE blocks of p that events reference, in
+// their original order. Blocks without a TZID cannot be matched and are
+// always included.
+func (p ParsedCalendar) TimezonesFor(events []Event) []string {
+	t := newZoneTable(p, false)
+	set := t.base()
+	for _, i := range t.refs(events) {
+		set[i] = true
+	}
+	return t.blocksOf(set)
+}
+
+// zoneTable indexes the VTIMEZONE blocks of a calendar by TZID so that
+// planners can give each chunk only the zones its events reference.
+type zoneTable struct {
+	blocks  []string
+	named   []bool
+	byID    map[string]int
+	keepAll bool
+	eol     string
+}
+
+// zoneSet holds the zones of a chunk as indexes into zoneTable.blocks.
+type zoneSet map[int]bool
+
+func newZoneTable(parsed ParsedCalendar, keepAll bool) zoneTable {
+	t := zoneTable{
+		blocks:  parsed.Timezones,
+		named:   make([]bool, len(parsed.Timezones)),
+		byID:    make(map[string]int),
+		keepAll: keepAll,
+		eol:     parsed.lineEnding(),
+	}
+	for i, block := range parsed.Timezones {
+		id := ExtractProperty(block, "TZID")
+		if id == "" {
+			continue
+		}
+		t.named[i] = true
+		if _, dup := t.byID[id]; !dup {
+			t.byID[id] = i
+		}
+	}
+	return t
+}
+
+// base returns the zones every chunk carries: all of them with keepAll,
+// otherwise the ones without a TZID.
+func (t zoneTable) base() zoneSet {
+	set := make(zoneSet)
+	for i := range t.blocks {
+		if t.keepAll || !t.named[i] {
+			set[i] = true
+		}
+	}
+	return set
+}
+
+// refs returns the zones events reference, by index. TZIDs with no
+// VTIMEZONE in the calendar are ignored, and with keepAll there is nothing
+// to look up.
+func (t zoneTable) refs(events []Event) []int {
+	if t.keepAll || len(t.byID) == 0 {
+		return nil
+	}
+	var out []int
+	for _, event := range events {
+		for _, id := range event.TZIDs() {
+			if i, ok := t.byID[id]; ok && !slices.Contains(out, i) {
+				out = append(out, i)
+			}
+		}
+	}
+	return out
+}
+
+// size is the number of bytes the given zones add to a rendered file.
+func (t zoneTable) size(zones []int) int64 {
+	var n int64
+	for _, i := range zones {
+		n += int64(encodedLen(t.blocks[i], t.eol) + len(t.eol))
+	}
+	return n
+}
+
+func (t zoneTable) blocksOf(set zoneSet) []string {
+	var blocks []string
+	for i, block := range t.blocks {
+		if set[i] {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
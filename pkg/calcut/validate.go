@@ -0,0 +1,284 @@
+package calcut
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+)
+
+// Issue severities. Errors break RFC 5545 requirements that importers rely
+// on; warnings are common in real exports and usually tolerated.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Issue is a problem found by Validate. Line is the 1-based physical line
+// it was found on, or 0 when it concerns the calendar as a whole. Code is
+// a stable identifier for scripts; Message is meant for people.
+type Issue struct {
+	Line     int    `json:"line"`
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	UID      string `json:"uid,omitempty"`
+
+	msg *i18n.Error
+}
+
+// Localize returns the message of i in locale.
+func (i Issue) Localize(locale string) string {
+	if i.msg == nil {
+		return i.Message
+	}
+	return i.msg.Localize(locale)
+}
+
+// maxLineOctets is the line length RFC 5545 §3.1 asks writers to fold at.
+const maxLineOctets = 75
+
+// textProperties hold a single TEXT value (RFC 5545 §3.3.11), in which ',',
+// ';' and '\' must be escaped.
+var textProperties = []string{"SUMMARY", "DESCRIPTION", "LOCATION", "COMMENT", "CONTACT"}
+
+// Validate checks the structure of an iCalendar file before it is split:
+// unbalanced BEGIN/END, components without UID, DTSTAMP or (for VEVENT)
+// DTSTART, duplicate UIDs, TZIDs without a VTIMEZONE, unescaped TEXT values,
+// legacy encodings and lines longer than 75 octets. Issues are returned in
+// line order. Input that SniffInput finds is not iCalendar at all gets a
+// single not-icalendar error instead.
+//
+// Validate applies no limits; use ValidateLimits for untrusted input.
+func Validate(data []byte) []Issue {
+	return ValidateLimits(data, ParseLimits{})
+}
+
+// ValidateLimits is like Validate but first rejects input longer than
+// limits.MaxBytes, or with a physical line longer than
+// limits.MaxLineLength, with a single too-large error, as ParseBytes
+// would. The other limits bound what the parser builds and do not apply.
+func ValidateLimits(data []byte, limits ParseLimits) []Issue {
+	v := validator{seen: make(map[string]int), zones: make(map[string]bool)}
+	if limits.MaxBytes > 0 && int64(len(data)) > limits.MaxBytes {
+		v.add(0, SeverityError, "too-large", "", "입력이 너무 큽니다 (%d bytes, 최대 %d bytes)", len(data), limits.MaxBytes)
+		return v.issues
+	}
+	if msg := sniff(data); msg != "" {
+		v.add(0, SeverityError, "not-icalendar", "", msg)
+		return v.issues
+	}
+	lines := strings.Split(string(data), "\n")
+	if limits.MaxLineLength > 0 {
+		for i, line := range lines {
+			if n := len(strings.TrimRight(line, "\r")); n > limits.MaxLineLength {
+				v.add(i+1, SeverityError, "too-large", "", "줄이 너무 깁니다 (%d bytes, 최대 %d bytes)", n, limits.MaxLineLength)
+				return v.issues
+			}
+		}
+	}
+	for _, l := range logicalLines(lines) {
+		for i := l.first; i <= l.last; i++ {
+			if n := len(strings.TrimRight(lines[i], "\r")); n > maxLineOctets {
+				v.add(i+1, SeverityWarning, "line-length", "", "줄이 75바이트를 넘습니다 (%d bytes, 접지 않음)", n)
+			}
+		}
+		v.line(l.first+1, l.text)
+	}
+	return v.finish()
+}
+
+// validator carries the state of Validate across lines.
+type validator struct {
+	issues  []Issue
+	started bool
+	stack   []string
+
+	// comp collects the top-level properties of the component being read.
+	comp      *componentInfo
+	hasMethod bool
+	noStart   []componentInfo
+
+	seen  map[string]int // UID and RECURRENCE-ID to first line
+	zones map[string]bool
+	refs  []tzidRef
+}
+
+type componentInfo struct {
+	kind  string
+	line  int
+	props map[string]string
+}
+
+type tzidRef struct {
+	line int
+	id   string
+}
+
+func (v *validator) add(line int, severity, code, uid, format string, args ...any) {
+	msg := &i18n.Error{Format: format, Args: args}
+	v.issues = append(v.issues, Issue{Line: line, Severity: severity, Code: code, Message: msg.Error(), UID: uid, msg: msg})
+}
+
+func (v *validator) line(lineNo int, text string) {
+	if text == "" {
+		return
+	}
+	if !v.started {
+		v.started = true
+		if text != "BEGIN:VCALENDAR" {
+			v.add(lineNo, SeverityError, "structure", "", "BEGIN:VCALENDAR로 시작하지 않습니다")
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(text, "BEGIN:"):
+		name := strings.ToUpper(text[len("BEGIN:"):])
+		v.stack = append(v.stack, name)
+		if len(v.stack) == 2 && slices.Contains(ComponentKinds, name) {
+			v.comp = &componentInfo{kind: name, line: lineNo, props: make(map[string]string)}
+		}
+		return
+	case strings.HasPrefix(text, "END:"):
+		name := strings.ToUpper(text[len("END:"):])
+		if len(v.stack) == 0 {
+			v.add(lineNo, SeverityError, "structure", "", "BEGIN 없는 END:%s", name)
+			return
+		}
+		if open := v.stack[len(v.stack)-1]; open != name {
+			v.add(lineNo, SeverityError, "structure", "", "짝이 맞지 않는 END:%s (열린 컴포넌트: %s)", name, open)
+			if !slices.Contains(v.stack, name) {
+				return
+			}
+			// Close the components left open in between too.
+			for v.stack[len(v.stack)-1] != name {
+				if len(v.stack) == 2 && v.comp != nil {
+					v.endComponent()
+				}
+				v.stack = v.stack[:len(v.stack)-1]
+			}
+		}
+		if len(v.stack) == 2 && v.comp != nil {
+			v.endComponent()
+		}
+		v.stack = v.stack[:len(v.stack)-1]
+		return
+	}
+
+	name := PropertyName(text)
+	params, value := splitContentLine(text)
+	legacy := isLegacyLine(text)
+	if legacy {
+		v.checkLegacy(lineNo, name, text)
+	}
+	if id := params["TZID"]; id != "" && name != "TZID" {
+		v.refs = append(v.refs, tzidRef{lineNo, id})
+	}
+	switch {
+	case len(v.stack) == 1 && name == "METHOD":
+		v.hasMethod = true
+	case len(v.stack) == 2 && name == "TZID" && v.stack[1] == "VTIMEZONE":
+		v.zones[value] = true
+	case len(v.stack) == 2 && v.comp != nil:
+		if _, ok := v.comp.props[name]; !ok {
+			v.comp.props[name] = value
+		}
+		if slices.Contains(textProperties, name) && !legacy {
+			v.checkText(lineNo, name, value)
+		}
+	}
+}
+
+// endComponent checks the required properties of the component that just
+// ended and records its UID.
+func (v *validator) endComponent() {
+	c := *v.comp
+	v.comp = nil
+	uid := c.props["UID"]
+	for _, prop := range []string{"UID", "DTSTAMP"} {
+		if _, ok := c.props[prop]; !ok {
+			v.add(c.line, SeverityError, "missing-"+strings.ToLower(prop), uid, "%s에 %s가 없습니다", c.kind, prop)
+		}
+	}
+	if _, ok := c.props["DTSTART"]; !ok && c.kind == "VEVENT" {
+		// Only required when the calendar has no METHOD, which may still
+		// follow.
+		v.noStart = append(v.noStart, c)
+	}
+	if uid == "" {
+		return
+	}
+	key := uid + "\x00" + c.props["RECURRENCE-ID"]
+	if first, dup := v.seen[key]; dup {
+		v.add(c.line, SeverityError, "duplicate-uid", uid, "중복된 UID: %s (%d번째 줄과 같음)", uid, first)
+		return
+	}
+	v.seen[key] = c.line
+}
+
+// checkText reports unescaped separators and unknown escapes in a TEXT
+// value.
+func (v *validator) checkText(lineNo int, name, value string) {
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			if i+1 < len(value) && strings.IndexByte(`\;,nN`, value[i+1]) >= 0 {
+				i++
+				continue
+			}
+			v.add(lineNo, SeverityWarning, "unescaped-text", v.comp.props["UID"], "%s 값에 잘못된 이스케이프가 있습니다: %s", name, value[i:min(i+2, len(value))])
+			return
+		case ';', ',':
+			v.add(lineNo, SeverityWarning, "unescaped-text", v.comp.props["UID"], "%s 값에 이스케이프되지 않은 문자가 있습니다: %s", name, string(value[i]))
+			return
+		}
+	}
+}
+
+// checkLegacy reports a value written quoted-printable or in a declared
+// charset other than UTF-8, which RFC 5545 importers may show garbled, and
+// as an error one in bytes that are not UTF-8 with no charset declared.
+func (v *validator) checkLegacy(lineNo int, name, text string) {
+	uid := ""
+	if v.comp != nil {
+		uid = v.comp.props["UID"]
+	}
+	params, _ := splitContentLine(text)
+	decoded, err := DecodeLegacyValue(text)
+	switch {
+	case err != nil:
+		v.add(lineNo, SeverityError, "legacy-encoding", uid, "%s 값을 읽을 수 없습니다: %s", name, err)
+	case params["CHARSET"] == "" && !utf8.ValidString(text):
+		v.add(lineNo, SeverityError, "legacy-encoding", uid, "%s 값이 UTF-8이 아닙니다 (%s로 읽힘)", name, decoded.Encoding)
+	case decoded.Encoding != "":
+		v.add(lineNo, SeverityWarning, "legacy-encoding", uid, "%s 값이 옛 vCalendar 방식으로 인코딩되어 있습니다 (%s)", name, decoded.Encoding)
+	}
+}
+
+func (v *validator) finish() []Issue {
+	for i := len(v.stack) - 1; i >= 0; i-- {
+		v.add(0, SeverityError, "structure", "", "닫히지 않은 컴포넌트: %s", v.stack[i])
+	}
+	if !v.hasMethod {
+		for _, c := range v.noStart {
+			v.add(c.line, SeverityError, "missing-dtstart", c.props["UID"], "%s에 %s가 없습니다", c.kind, "DTSTART")
+		}
+	}
+	for _, ref := range v.refs {
+		if !v.zones[ref.id] {
+			v.add(ref.line, SeverityError, "unknown-tzid", "", "정의되지 않은 TZID: %s", ref.id)
+		}
+	}
+	slices.SortStableFunc(v.issues, func(a, b Issue) int {
+		// Whole-calendar issues (line 0) go last.
+		if (a.Line == 0) != (b.Line == 0) {
+			if a.Line == 0 {
+				return 1
+			}
+			return -1
+		}
+		return a.Line - b.Line
+	})
+	return v.issues
+}
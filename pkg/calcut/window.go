@@ -0,0 +1,261 @@
+package calcut
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+)
+
+// DateWindow selects events by when they start. From is inclusive and To
+// exclusive; a zero bound is open. Floating and DATE values are taken in
+// Loc, time.Local if nil.
+type DateWindow struct {
+	From, To time.Time
+	Loc      *time.Location
+}
+
+// ParseDateWindow builds the window covering the days from through to,
+// both YYYY-MM-DD in loc and inclusive. Either may be empty.
+func ParseDateWindow(from, to string, loc *time.Location) (DateWindow, error) {
+	return parseDateWindow(from, to, loc, func(s string) (time.Time, time.Time, error) {
+		day, err := time.ParseInLocation("2006-01-02", s, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, i18n.Errorf("잘못된 날짜: %s (YYYY-MM-DD)", s)
+		}
+		return day, day.AddDate(0, 0, 1), nil
+	})
+}
+
+// ParseNaturalDateWindow is ParseDateWindow accepting whatever
+// ParseDatePeriod does, such as "2024-03" or "90 days ago": the window
+// starts with the period from names and ends with the period to names.
+func ParseNaturalDateWindow(from, to string, now time.Time, loc *time.Location) (DateWindow, error) {
+	return parseDateWindow(from, to, loc, func(s string) (time.Time, time.Time, error) {
+		return ParseDatePeriod(s, now, loc)
+	})
+}
+
+// parseDateWindow builds a window from the periods period finds for from
+// and to.
+func parseDateWindow(from, to string, loc *time.Location, period func(string) (time.Time, time.Time, error)) (DateWindow, error) {
+	w := DateWindow{Loc: loc}
+	var err error
+	if from != "" {
+		if w.From, _, err = period(from); err != nil {
+			return DateWindow{}, err
+		}
+	}
+	if to != "" {
+		if _, w.To, err = period(to); err != nil {
+			return DateWindow{}, err
+		}
+	}
+	if !w.From.IsZero() && !w.To.IsZero() && !w.From.Before(w.To) {
+		return DateWindow{}, i18n.Errorf("시작 날짜(%s)가 끝 날짜(%s)보다 늦습니다", from, to)
+	}
+	return w, nil
+}
+
+// IsZero reports whether w has no bounds and so selects every event.
+func (w DateWindow) IsZero() bool {
+	return w.From.IsZero() && w.To.IsZero()
+}
+
+// Contains reports whether e starts inside w. An event without a readable
+// DTSTART is only inside a window without bounds.
+func (w DateWindow) Contains(e Event) bool {
+	if w.IsZero() {
+		return true
+	}
+	loc := w.Loc
+	if loc == nil {
+		loc = time.Local
+	}
+	start, _, err := e.Start(loc)
+	if err != nil {
+		return false
+	}
+	return (w.From.IsZero() || !start.Before(w.From)) && (w.To.IsZero() || start.Before(w.To))
+}
+
+// FilterWindow returns the events inside w, in input order. Events sharing
+// a UID are kept or dropped together, so a series stays whole as long as
+// one of its instances starts inside the window.
+func FilterWindow(events []Event, w DateWindow) []Event {
+	if w.IsZero() {
+		return events
+	}
+	return filterSeries(events, w.Contains)
+}
+
+// filterSeries returns the events for which inside holds, together with
+// every other event sharing a UID with one of them.
+func filterSeries(events []Event, contains func(Event) bool) []Event {
+	inside := make([]bool, len(events))
+	uids := make(map[string]bool)
+	for i, event := range events {
+		inside[i] = contains(event)
+		if inside[i] && event.UID != "" {
+			uids[event.UID] = true
+		}
+	}
+	var out []Event
+	for i, event := range events {
+		if inside[i] || (event.UID != "" && uids[event.UID]) {
+			out = append(out, event)
+		}
+	}
+	return out
+}
+
+// HoursWindow selects events by the time of day they take place, such as
+// working hours. From and To are offsets from midnight in Loc; a To not
+// after From wraps past midnight (e.g. 22:00-06:00).
+type HoursWindow struct {
+	From, To time.Duration
+	Loc      *time.Location
+}
+
+// ParseHoursWindow parses a range such as "09:00-18:00".
+func ParseHoursWindow(s string, loc *time.Location) (HoursWindow, error) {
+	fromText, toText, ok := strings.Cut(strings.TrimSpace(s), "-")
+	from, err1 := parseClock(fromText)
+	to, err2 := parseClock(toText)
+	if !ok || err1 != nil || err2 != nil || from == to {
+		return HoursWindow{}, i18n.Errorf("잘못된 시간 범위: %s (예: 09:00-18:00)", s)
+	}
+	return HoursWindow{From: from, To: to, Loc: loc}, nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		if strings.TrimSpace(s) == "24:00" {
+			return 24 * time.Hour, nil
+		}
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// IsZero reports whether w has no range and so selects every event.
+func (w HoursWindow) IsZero() bool {
+	return w.From == 0 && w.To == 0
+}
+
+// Contains reports whether e overlaps w on any day it spans. An event
+// without duration counts when it starts inside w; all-day events span
+// whole days and so always overlap. Events without a readable DTSTART are
+// only inside a zero window.
+func (w HoursWindow) Contains(e Event) bool {
+	if w.IsZero() {
+		return true
+	}
+	loc := w.Loc
+	if loc == nil {
+		loc = time.Local
+	}
+	start, _, err := e.Start(loc)
+	if err != nil {
+		return false
+	}
+	end, err := e.End(loc)
+	if err != nil || end.Before(start) {
+		end = start
+	}
+	// Walk the days the event touches, starting the day before so that a
+	// range wrapping past midnight is seen from its first evening.
+	y, m, d := start.Date()
+	for day := time.Date(y, m, d-1, 0, 0, 0, 0, loc); !day.After(end); day = day.AddDate(0, 0, 1) {
+		from := day.Add(w.From)
+		to := day.Add(w.To)
+		if w.To <= w.From {
+			to = day.AddDate(0, 0, 1).Add(w.To)
+		}
+		if end.Equal(start) {
+			if !start.Before(from) && start.Before(to) {
+				return true
+			}
+		} else if start.Before(to) && end.After(from) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterHours returns the events inside w, in input order, keeping series
+// whole as FilterWindow does.
+func FilterHours(events []Event, w HoursWindow) []Event {
+	if w.IsZero() {
+		return events
+	}
+	return filterSeries(events, w.Contains)
+}
+
+// DurationFilter selects events by how long they last, from DTSTART to
+// the end Event.End finds. A zero bound is not checked. Events without a
+// readable DTSTART have no length to judge and are kept.
+type DurationFilter struct {
+	Min, Max time.Duration
+	Loc      *time.Location
+}
+
+// ParseEventDuration parses a length such as "15m", "8h", "1h30m" or
+// "2d", or a DURATION value such as "PT15M".
+func ParseEventDuration(s string) (time.Duration, error) {
+	v := strings.TrimSpace(s)
+	if strings.HasPrefix(strings.ToUpper(v), "P") {
+		return ParseDuration(v)
+	}
+	if days, ok := strings.CutSuffix(v, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return 0, i18n.Errorf("잘못된 길이: %s (예: 15m, 8h, 2d)", s)
+	}
+	return d, nil
+}
+
+// IsZero reports whether f has no bounds and so keeps every event.
+func (f DurationFilter) IsZero() bool {
+	return f.Min == 0 && f.Max == 0
+}
+
+// Keep reports whether f lets e through.
+func (f DurationFilter) Keep(e Event) bool {
+	loc := f.Loc
+	if loc == nil {
+		loc = time.Local
+	}
+	start, _, err := e.Start(loc)
+	if err != nil {
+		return true
+	}
+	end, err := e.End(loc)
+	if err != nil {
+		return true
+	}
+	d := end.Sub(start)
+	return (f.Min == 0 || d >= f.Min) && (f.Max == 0 || d <= f.Max)
+}
+
+// FilterDuration returns the events f keeps, in input order. Each
+// component is judged on its own, as an occurrence may be moved to a
+// different length than its series.
+func FilterDuration(events []Event, f DurationFilter) []Event {
+	if f.IsZero() {
+		return events
+	}
+	var out []Event
+	for _, e := range events {
+		if f.Keep(e) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
@@ -0,0 +1,34 @@
+package calcut
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"time"
+)
+
+// AddZipEntry writes content to zw as a compressed file named name.
+func AddZipEntry(zw *zip.Writer, name, content string, modified time.Time) error {
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, content)
+	return err
+}
+
+// ZipResults packs split results into a zip archive, one entry per file in
+// order.
+func ZipResults(results []SplitResult, modified time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, r := range results {
+		if err := AddZipEntry(zw, r.Filename, r.Content, modified); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,210 @@
+//go:build wasip1
+
+// Command wasi builds calcut as a WASI reactor module for runtimes without
+// a browser global object (Node, Deno, wasmtime, serverless hosts). The
+// functions are exported as plain wasm symbols; strings cross the boundary
+// as UTF-8 bytes in the module's memory:
+//
+//	ptr := calcut_alloc(len)       // copy the input to memory[ptr:ptr+len]
+//	res := calcut_split(inPtr, inLen, optsPtr, optsLen)
+//	// JSON result at memory[res>>32 : res>>32 + res&0xffffffff]
+//	calcut_free(ptr); calcut_free(res >> 32)
+//
+// Only two functions of the calcut global of the browser build are
+// exported, with options and results as JSON:
+//
+//	calcut_split(inPtr, inLen, optsPtr, optsLen)     // calcut.split
+//	calcut_get_info(inPtr, inLen, optsPtr, optsLen)  // calcut.getInfo
+//
+// An options length of 0 takes the defaults. calcut_split accepts mode,
+// maxSize, maxEvents, by, prefix, components, from, to and locale; the
+// browser-only colors, files and zip options and the signal, shouldCancel
+// and onProgress callbacks are ignored, so its results never carry a file,
+// a zip or cancelled. calcut_get_info accepts locale. There is no merge,
+// validate, splitMany, splitAsync or zip: use the CLI, or the WASI build of
+// it, for those.
+//
+// Build with:
+//
+//	GOOS=wasip1 GOARCH=wasm go build -buildmode=c-shared -o calcut.wasm ./wasi
+package main
+
+import (
+	"encoding/json"
+	"time"
+	"unsafe"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+	"github.com/sedurm85/calcut/pkg/calcut"
+)
+
+// buffers keeps the memory handed to the host reachable until it is freed.
+var buffers = make(map[uint32][]byte)
+
+// splitOptions mirrors the options object of calcut.split.
+type splitOptions struct {
+	MaxSize    string `json:"maxSize"`
+	MaxEvents  int    `json:"maxEvents"`
+	Prefix     string `json:"prefix"`
+	Mode       string `json:"mode"`
+	By         string `json:"by"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+	Components string `json:"components"`
+	Locale     string `json:"locale"`
+}
+
+type splitFile struct {
+	Filename string `json:"filename"`
+	Content  string `json:"content"`
+	Events   int    `json:"events"`
+	Size     int    `json:"size"`
+}
+
+type splitResult struct {
+	Success     bool        `json:"success,omitempty"`
+	Error       string      `json:"error,omitempty"`
+	TotalEvents int         `json:"totalEvents,omitempty"`
+	Events      *int        `json:"events,omitempty"`
+	Files       []splitFile `json:"files,omitempty"`
+}
+
+// infoOptions mirrors the options object of calcut.getInfo.
+type infoOptions struct {
+	Locale string `json:"locale"`
+}
+
+type infoResult struct {
+	Error  string `json:"error,omitempty"`
+	Events int    `json:"events"`
+	Size   int    `json:"size"`
+}
+
+//go:wasmexport calcut_alloc
+func alloc(size uint32) uint32 {
+	if size == 0 {
+		size = 1
+	}
+	buf := make([]byte, size)
+	ptr := uint32(uintptr(unsafe.Pointer(&buf[0])))
+	buffers[ptr] = buf
+	return ptr
+}
+
+//go:wasmexport calcut_free
+func free(ptr uint32) {
+	delete(buffers, ptr)
+}
+
+//go:wasmexport calcut_split
+func split(inPtr, inLen, optsPtr, optsLen uint32) uint64 {
+	var options splitOptions
+	if optsLen > 0 {
+		if err := json.Unmarshal(bytesAt(optsPtr, optsLen), &options); err != nil {
+			return result(splitResult{Error: i18n.Sprintf(options.Locale, "잘못된 옵션입니다: %s", err)})
+		}
+	}
+	return result(splitIcal(bytesAt(inPtr, inLen), options))
+}
+
+//go:wasmexport calcut_get_info
+func getInfo(inPtr, inLen, optsPtr, optsLen uint32) uint64 {
+	var options infoOptions
+	if optsLen > 0 {
+		if err := json.Unmarshal(bytesAt(optsPtr, optsLen), &options); err != nil {
+			return result(infoResult{Error: i18n.Sprintf(options.Locale, "잘못된 옵션입니다: %s", err)})
+		}
+	}
+	content := bytesAt(inPtr, inLen)
+	parsed, err := calcut.ParseBytes(content, calcut.DefaultParseLimits())
+	if err != nil {
+		return result(infoResult{Error: i18n.Message(options.Locale, err)})
+	}
+	return result(infoResult{Events: len(parsed.Events), Size: len(content)})
+}
+
+func splitIcal(content []byte, options splitOptions) splitResult {
+	locale := options.Locale
+	parsed, err := calcut.ParseBytes(content, calcut.DefaultParseLimits())
+	if err != nil {
+		return splitResult{Error: i18n.Message(locale, err)}
+	}
+
+	kinds, err := calcut.ParseComponentKinds(options.Components)
+	if err != nil {
+		return splitResult{Error: i18n.Message(locale, err)}
+	}
+	window, err := calcut.ParseDateWindow(options.From, options.To, time.Local)
+	if err != nil {
+		return splitResult{Error: i18n.Message(locale, err)}
+	}
+	parsed.Events = calcut.FilterWindow(calcut.FilterKinds(parsed.Events, kinds), window)
+
+	if len(parsed.Events) == 0 {
+		none := 0
+		return splitResult{Error: i18n.Sprintf(locale, "이벤트가 없습니다"), Events: &none}
+	}
+
+	opts := calcut.SplitOptions{
+		Prefix:     options.Prefix,
+		Contiguous: true,
+		Related:    true,
+		MaxEvents:  options.MaxEvents,
+	}
+
+	var results []calcut.SplitResult
+
+	if options.Mode == "size" && (options.MaxSize != "" || opts.MaxEvents > 0) {
+		if options.MaxSize != "" {
+			maxBytes, err := calcut.ParseSize(options.MaxSize)
+			if err != nil || maxBytes <= 0 {
+				return splitResult{Error: i18n.Sprintf(locale, "잘못된 크기 형식입니다")}
+			}
+			opts.MaxBytes = maxBytes
+		}
+	} else {
+		opts.MaxEvents = 0
+	}
+	switch {
+	case options.By != "":
+		chunks, err := calcut.PlanByPeriod(parsed, calcut.GroupEvents(parsed.Events, opts.Related), options.By, time.Local, opts)
+		if err != nil {
+			return splitResult{Error: i18n.Message(locale, err)}
+		}
+		results = calcut.Render(parsed, chunks)
+	case opts.MaxBytes > 0 || opts.MaxEvents > 0:
+		results = calcut.SplitBySize(parsed, opts)
+	default:
+		results = calcut.SplitPerEvent(parsed, opts)
+	}
+
+	files := make([]splitFile, len(results))
+	for i, r := range results {
+		files[i] = splitFile{Filename: r.Filename, Content: r.Content, Events: r.Events, Size: r.Size}
+	}
+	return splitResult{Success: true, TotalEvents: len(parsed.Events), Files: files}
+}
+
+// bytesAt returns the first n bytes of a buffer from calcut_alloc, or nil
+// if ptr was not allocated or is too short.
+func bytesAt(ptr, n uint32) []byte {
+	buf := buffers[ptr]
+	if uint32(len(buf)) < n {
+		return nil
+	}
+	return buf[:n]
+}
+
+// result encodes v as JSON into a new buffer and packs its address and
+// length into one value: ptr<<32 | len.
+func result(v any) uint64 {
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(splitResult{Error: err.Error()})
+	}
+	ptr := alloc(uint32(len(data)))
+	copy(buffers[ptr], data)
+	return uint64(ptr)<<32 | uint64(len(data))
+}
+
+func main() {}
@@ -0,0 +1,153 @@
+// Command dtscheck fails when the option and result keys wasm/main.go reads
+// and builds drift from the properties wasm/npm/index.d.ts declares, so the
+// hand-written type definitions cannot silently fall behind the module.
+//
+// Run it from the repository root with:
+//
+//	go generate ./wasm/dtscheck
+package main
+
+//go:generate go run . -src ../main.go -dts ../npm/index.d.ts
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+)
+
+// domKeys are properties of browser objects, such as the aborted flag of
+// an AbortSignal option or the type given to the File constructor, which
+// index.d.ts leaves to the DOM types.
+var domKeys = []string{"aborted", "type"}
+
+func main() {
+	src := flag.String("src", "wasm/main.go", "Go source of the WASM module")
+	dts := flag.String("dts", "wasm/npm/index.d.ts", "type definitions to check")
+	flag.Parse()
+
+	goKeys, err := sourceKeys(*src)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "dtscheck: %s\n", err)
+		os.Exit(1)
+	}
+	declared, err := declaredKeys(*dts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "dtscheck: %s\n", err)
+		os.Exit(1)
+	}
+
+	drift := false
+	for _, key := range slices.Sorted(maps.Keys(goKeys)) {
+		if !declared[key] && !slices.Contains(domKeys, key) {
+			fmt.Fprintf(os.Stderr, "dtscheck: %s uses %q, which %s does not declare\n", *src, key, *dts)
+			drift = true
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(declared)) {
+		if !goKeys[key] {
+			fmt.Fprintf(os.Stderr, "dtscheck: %s declares %q, which %s never reads or sets\n", *dts, key, *src)
+			drift = true
+		}
+	}
+	if drift {
+		os.Exit(1)
+	}
+}
+
+// sourceKeys returns the keys the module reads from JS values, with
+// Get("key") or stringOption(v, "key"), and the keys of the maps it hands
+// back, written as map literals or set with m["key"].
+func sourceKeys(path string) (map[string]bool, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]bool)
+	add := func(e ast.Expr) {
+		if lit, ok := e.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+			if s, err := strconv.Unquote(lit.Value); err == nil {
+				keys[s] = true
+			}
+		}
+	}
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.CompositeLit:
+			if m, ok := n.Type.(*ast.MapType); ok && isIdent(m.Key, "string") {
+				for _, elt := range n.Elts {
+					if kv, ok := elt.(*ast.KeyValueExpr); ok {
+						add(kv.Key)
+					}
+				}
+			}
+		case *ast.IndexExpr:
+			add(n.Index)
+		case *ast.CallExpr:
+			switch fun := n.Fun.(type) {
+			case *ast.SelectorExpr:
+				// Globals such as js.Global().Get("Uint8Array") are the
+				// page's, not the API's.
+				if fun.Sel.Name == "Get" && len(n.Args) == 1 && !isGlobal(fun.X) {
+					add(n.Args[0])
+				}
+			case *ast.Ident:
+				if fun.Name == "stringOption" && len(n.Args) == 2 {
+					add(n.Args[1])
+				}
+			}
+		}
+		return true
+	})
+	return keys, nil
+}
+
+func isIdent(e ast.Expr, name string) bool {
+	id, ok := e.(*ast.Ident)
+	return ok && id.Name == name
+}
+
+// isGlobal reports whether e is js.Global().
+func isGlobal(e ast.Expr) bool {
+	call, ok := e.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	return ok && sel.Sel.Name == "Global" && isIdent(sel.X, "js")
+}
+
+// property matches a property or method of an interface in index.d.ts.
+var property = regexp.MustCompile(`^\s+(?:readonly\s+)?([A-Za-z_]\w*)\??\s*[:(]`)
+
+// inlineProperty matches the properties of an inline object type such as
+// "SplitResult & { name: string }".
+var inlineProperty = regexp.MustCompile(`&\s*\{\s*([A-Za-z_]\w*)\??:`)
+
+// declaredKeys returns the property names index.d.ts declares.
+func declaredKeys(path string) (map[string]bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	keys := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m := property.FindStringSubmatch(line); m != nil {
+			keys[m[1]] = true
+		}
+		for _, m := range inlineProperty.FindAllStringSubmatch(line, -1) {
+			keys[m[1]] = true
+		}
+	}
+	return keys, scanner.Err()
+}
@@ -3,330 +3,461 @@
 package main
 
 import (
-	"regexp"
-	"strconv"
-	"strings"
+	"context"
 	"syscall/js"
+	"time"
+
+	"github.com/sedurm85/calcut/internal/i18n"
+	"github.com/sedurm85/calcut/pkg/calcut"
 )
 
-type Event struct {
-	Text    string
-	Summary string
+func splitIcalJS(this js.Value, args []js.Value) interface{} {
+	ctx, stop := cancelContext(args, false)
+	defer stop()
+	return js.ValueOf(splitIcal(ctx, args))
 }
 
-type ParsedCalendar struct {
-	HeaderLines []string
-	Timezones   []string
-	Events      []Event
+// splitAsyncJS is split returning a Promise. The work runs in a goroutine
+// that regularly hands control back to the page, so that it stays
+// responsive and can abort the split through options.signal.
+func splitAsyncJS(this js.Value, args []js.Value) interface{} {
+	executor := js.FuncOf(func(this js.Value, p []js.Value) interface{} {
+		resolve := p[0]
+		go func() {
+			ctx, stop := cancelContext(args, true)
+			defer stop()
+			resolve.Invoke(js.ValueOf(splitIcal(ctx, args)))
+		}()
+		return nil
+	})
+	defer executor.Release()
+	return js.Global().Get("Promise").New(executor)
 }
 
-func parseIcal(content string) ParsedCalendar {
-	lines := strings.Split(content, "\n")
+// splitManyJS splits several calendars with the same options, as if split
+// were called for each, and returns the results grouped per input.
+func splitManyJS(this js.Value, args []js.Value) interface{} {
+	locale := localeOption(args, 1)
+	if len(args) < 2 || args[0].Type() != js.TypeObject {
+		return js.ValueOf(map[string]interface{}{
+			"error": i18n.Sprintf(locale, "인자가 부족합니다 (inputs, options)"),
+		})
+	}
+	ctx, stop := cancelContext(args, false)
+	defer stop()
+
+	inputs := args[0]
+	results := make([]interface{}, inputs.Length())
+	failed := 0
+	for i := range results {
+		input := inputs.Index(i)
+		var name, content js.Value
+		if input.Type() == js.TypeObject {
+			name, content = input.Get("name"), input.Get("content")
+		}
+		if name.Type() != js.TypeString || content.Type() != js.TypeString {
+			// Split as it is, an entry without them would read as the
+			// text "<undefined>".
+			result := map[string]interface{}{
+				"error": i18n.Sprintf(locale, "%d번째 입력에 문자열 name과 content가 필요합니다", i+1),
+				"name":  "",
+			}
+			if name.Type() == js.TypeString {
+				result["name"] = name.String()
+			}
+			results[i] = result
+			failed++
+			continue
+		}
+		result := splitIcal(ctx, []js.Value{content, args[1]})
+		if result["cancelled"] == true {
+			return js.ValueOf(result)
+		}
+		if result["success"] != true {
+			failed++
+		}
+		result["name"] = name.String()
+		results[i] = result
+	}
+	return js.ValueOf(map[string]interface{}{
+		"success": failed == 0,
+		"failed":  failed,
+		"results": results,
+	})
+}
 
-	var headerLines []string
-	var timezones []string
-	var events []Event
+func splitIcal(ctx context.Context, args []js.Value) map[string]interface{} {
+	locale := localeOption(args, 1)
+	if len(args) < 2 {
+		return map[string]interface{}{
+			"error": i18n.Sprintf(locale, "인자가 부족합니다 (content, options)"),
+		}
+	}
 
-	var currentBlock []string
-	blockType := ""
-	nesting := 0
+	content := args[0].String()
+	options := args[1]
 
-	for _, line := range lines {
-		stripped := strings.TrimSpace(line)
+	maxSize := stringOption(options, "maxSize")
+	prefix := stringOption(options, "prefix")
+	mode := stringOption(options, "mode")
+	by := stringOption(options, "by")
 
-		if stripped == "BEGIN:VCALENDAR" || stripped == "END:VCALENDAR" {
-			continue
+	progress := newProgress(options)
+	parsed, err := calcut.ParseBytesProgress(ctx, []byte(content), calcut.DefaultParseLimits(), progress.parsing)
+	if ctx.Err() != nil {
+		return cancelled(locale)
+	}
+	if err != nil {
+		return map[string]interface{}{
+			"error": i18n.Message(locale, err),
 		}
+	}
 
-		if strings.HasPrefix(stripped, "BEGIN:") && blockType == "" {
-			blockType = strings.SplitN(stripped, ":", 2)[1]
-			currentBlock = []string{line}
-			nesting = 1
-			continue
+	kinds, err := calcut.ParseComponentKinds(stringOption(options, "components"))
+	if err != nil {
+		return map[string]interface{}{
+			"error": i18n.Message(locale, err),
+		}
+	}
+	window, err := calcut.ParseDateWindow(stringOption(options, "from"), stringOption(options, "to"), time.Local)
+	if err != nil {
+		return map[string]interface{}{
+			"error": i18n.Message(locale, err),
 		}
+	}
+	parsed.Events = calcut.FilterWindow(calcut.FilterKinds(parsed.Events, kinds), window)
 
-		if blockType != "" {
-			currentBlock = append(currentBlock, line)
+	if len(parsed.Events) == 0 {
+		return map[string]interface{}{
+			"error":  i18n.Sprintf(locale, "이벤트가 없습니다"),
+			"events": 0,
+		}
+	}
 
-			if strings.HasPrefix(stripped, "BEGIN:") {
-				nesting++
-			} else if strings.HasPrefix(stripped, "END:") {
-				nesting--
-			}
+	opts := calcut.SplitOptions{
+		Prefix:     prefix,
+		Contiguous: true,
+		Related:    true,
+	}
 
-			if nesting == 0 {
-				blockText := strings.Join(currentBlock, "\n")
-
-				switch blockType {
-				case "VTIMEZONE":
-					timezones = append(timezones, blockText)
-				case "VEVENT":
-					events = append(events, Event{
-						Text:    blockText,
-						Summary: extractProperty(blockText, "SUMMARY"),
-					})
-				}
+	if v := options.Get("maxEvents"); v.Type() == js.TypeNumber {
+		opts.MaxEvents = v.Int()
+	}
+	colors, err := calcut.ParseColors(stringOption(options, "colors"))
+	if err != nil {
+		return map[string]interface{}{
+			"error": i18n.Message(locale, err),
+		}
+	}
+	for _, c := range colors {
+		opts.Reserve = max(opts.Reserve, c.HeaderSize())
+	}
 
-				blockType = ""
-				currentBlock = nil
+	groups := calcut.GroupEvents(parsed.Events, opts.Related)
+	var chunks []calcut.Chunk
+
+	if mode == "size" && (maxSize != "" || opts.MaxEvents > 0) {
+		if maxSize != "" {
+			maxBytes, err := calcut.ParseSize(maxSize)
+			if err != nil || maxBytes <= 0 {
+				return map[string]interface{}{
+					"error": i18n.Sprintf(locale, "잘못된 크기 형식입니다"),
+				}
 			}
-			continue
+			opts.MaxBytes = maxBytes
 		}
-
-		if stripped != "" {
-			headerLines = append(headerLines, line)
+	} else {
+		opts.MaxEvents = 0
+	}
+	switch {
+	case by != "":
+		// Each period is capped by the limits of mode "size" in turn.
+		if chunks, err = calcut.PlanByPeriod(parsed, groups, by, time.Local, opts); err != nil {
+			return map[string]interface{}{
+				"error": i18n.Message(locale, err),
+			}
 		}
+	case opts.MaxBytes > 0 || opts.MaxEvents > 0:
+		chunks = calcut.PlanBySize(parsed, groups, opts)
+	default:
+		chunks = calcut.PlanPerEvent(parsed, groups, opts)
 	}
 
-	return ParsedCalendar{
-		HeaderLines: headerLines,
-		Timezones:   timezones,
-		Events:      events,
+	asFiles := options.Get("files").Truthy()
+	results := make([]calcut.SplitResult, len(chunks))
+	jsResults := make([]interface{}, len(chunks))
+	for i, chunk := range chunks {
+		if ctx.Err() != nil {
+			return cancelled(locale)
+		}
+		calendar := parsed
+		if len(colors) > 0 {
+			calendar = parsed.WithColor(colors[i%len(colors)])
+		}
+		content := calendar.BuildChunk(chunk)
+		results[i] = calcut.SplitResult{Filename: chunk.Filename, Content: content, Events: len(chunk.Events), Size: len(content)}
+		result := map[string]interface{}{
+			"filename": chunk.Filename,
+			"content":  content,
+			"events":   len(chunk.Events),
+			"size":     len(content),
+		}
+		if asFiles {
+			result["file"] = newFile(chunk.Filename, content)
+		}
+		jsResults[i] = result
+		progress.writing(len(parsed.Events), i+1, len(chunks))
 	}
-}
+	progress.report(len(parsed.Events), len(chunks), 100, true)
 
-func extractProperty(block, propName string) string {
-	for _, line := range strings.Split(block, "\n") {
-		if strings.HasPrefix(line, propName+":") || strings.HasPrefix(line, propName+";") {
-			idx := strings.Index(line, ":")
-			if idx >= 0 {
-				return strings.TrimSpace(line[idx+1:])
+	result := map[string]interface{}{
+		"success":     true,
+		"totalEvents": len(parsed.Events),
+		"files":       jsResults,
+	}
+	if options.Get("zip").Truthy() {
+		archive, err := calcut.ZipResults(results, time.Now())
+		if err != nil {
+			return map[string]interface{}{
+				"error": i18n.Message(locale, err),
 			}
 		}
+		data := js.Global().Get("Uint8Array").New(len(archive))
+		js.CopyBytesToJS(data, archive)
+		result["zip"] = data
 	}
-	return ""
+	return result
 }
 
-var unsafeChars = regexp.MustCompile(`[<>:"/\\|?*]`)
-var multiUnderscore = regexp.MustCompile(`_+`)
-
-func sanitizeFilename(name string) string {
-	s := unsafeChars.ReplaceAllString(name, "")
-	s = strings.ReplaceAll(s, " ", "_")
-	s = multiUnderscore.ReplaceAllString(s, "_")
-	s = strings.Trim(s, "_")
-	if s == "" {
-		return "untitled"
+func cancelled(locale string) map[string]interface{} {
+	return map[string]interface{}{
+		"error":     i18n.Sprintf(locale, "분할이 취소되었습니다"),
+		"cancelled": true,
 	}
-	return s
 }
 
-func buildICS(headerLines, timezones []string, eventTexts []string) string {
-	var b strings.Builder
-	b.WriteString("BEGIN:VCALENDAR\n")
-	for _, h := range headerLines {
-		b.WriteString(h)
-		b.WriteByte('\n')
-	}
-	for _, tz := range timezones {
-		b.WriteString(tz)
-		b.WriteByte('\n')
+// yieldInterval is how long splitAsync works before letting the page run.
+const yieldInterval = 50 * time.Millisecond
+
+// progress calls the onProgress option of a split with how far it has
+// got, as {parsedEvents, writtenFiles, percent}: at most every
+// yieldInterval while it works, where splitAsync lets the page run, and
+// once at the end. Parsing is the first half of percent, building the
+// files the second. A nil progress reports nothing.
+type progress struct {
+	fn   js.Value
+	last time.Time
+}
+
+func newProgress(options js.Value) *progress {
+	fn := options.Get("onProgress")
+	if fn.Type() != js.TypeFunction {
+		return nil
 	}
-	for _, ev := range eventTexts {
-		b.WriteString(ev)
-		b.WriteByte('\n')
+	return &progress{fn: fn, last: time.Now()}
+}
+
+func (p *progress) report(events, files int, percent float64, final bool) {
+	if p == nil || !final && time.Since(p.last) < yieldInterval {
+		return
 	}
-	b.WriteString("END:VCALENDAR\n")
-	return b.String()
+	p.last = time.Now()
+	p.fn.Invoke(js.ValueOf(map[string]interface{}{
+		"parsedEvents": events,
+		"writtenFiles": files,
+		"percent":      int(percent),
+	}))
 }
 
-func skeletonSize(headerLines, timezones []string) int {
-	s := buildICS(headerLines, timezones, nil)
-	return len(s)
+// parsing is the calcut.ParseProgress of the split.
+func (p *progress) parsing(events int, fraction float64) {
+	p.report(events, 0, 50*fraction, false)
 }
 
-func parseSize(s string) int64 {
-	s = strings.TrimSpace(strings.ToUpper(s))
-	suffixes := map[string]int64{
-		"GB": 1024 * 1024 * 1024,
-		"MB": 1024 * 1024,
-		"KB": 1024,
-		"G":  1024 * 1024 * 1024,
-		"M":  1024 * 1024,
-		"K":  1024,
-	}
-	for _, suffix := range []string{"GB", "MB", "KB", "G", "M", "K"} {
-		if strings.HasSuffix(s, suffix) {
-			numStr := s[:len(s)-len(suffix)]
-			num, err := strconv.ParseFloat(numStr, 64)
-			if err != nil {
-				return 0
-			}
-			return int64(num * float64(suffixes[suffix]))
-		}
-	}
-	val, _ := strconv.ParseInt(s, 10, 64)
-	return val
+// writing reports files of total built.
+func (p *progress) writing(events, files, total int) {
+	p.report(events, files, 50+50*float64(files)/float64(total), false)
 }
 
-type SplitResult struct {
-	Filename string
-	Content  string
-	Events   int
-	Size     int
+// pollContext is canceled once poll reports that the page asked to stop.
+// The parser and the render loop only ever call Err between events, so
+// that is where poll runs.
+type pollContext struct {
+	context.Context
+	cancel context.CancelFunc
+	poll   func() bool
 }
 
-func splitBySize(parsed ParsedCalendar, prefix string, maxBytes int64) []SplitResult {
-	skelSize := int64(skeletonSize(parsed.HeaderLines, parsed.Timezones))
-	var results []SplitResult
-	var currentEvents []string
-	currentSize := skelSize
-	chunkIdx := 1
-	tag := prefix
-	if tag == "" {
-		tag = "part"
-	}
-
-	flush := func() {
-		filename := tag + "_" + padNumber(chunkIdx) + ".ics"
-		content := buildICS(parsed.HeaderLines, parsed.Timezones, currentEvents)
-		results = append(results, SplitResult{
-			Filename: filename,
-			Content:  content,
-			Events:   len(currentEvents),
-			Size:     len(content),
-		})
-		chunkIdx++
-		currentEvents = nil
-		currentSize = skelSize
+func (c *pollContext) Err() error {
+	if c.Context.Err() == nil && c.poll() {
+		c.cancel()
 	}
+	return c.Context.Err()
+}
 
-	for _, event := range parsed.Events {
-		eventBytes := int64(len(event.Text)) + 1
-		projected := currentSize + eventBytes
-
-		if eventBytes+skelSize > maxBytes {
-			if len(currentEvents) > 0 {
-				flush()
-			}
-			currentEvents = []string{event.Text}
-			flush()
-			continue
+// cancelContext returns the context of a split whose options may carry an
+// AbortSignal (signal) or a shouldCancel callback. With yield set it also
+// sleeps briefly every yieldInterval, which in js/wasm returns control to
+// the event loop so the signal can fire at all.
+func cancelContext(args []js.Value, yield bool) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var signal, shouldCancel js.Value
+	if len(args) > 1 && args[1].Type() == js.TypeObject {
+		signal = args[1].Get("signal")
+		shouldCancel = args[1].Get("shouldCancel")
+	}
+	if !yield && !signal.Truthy() && shouldCancel.Type() != js.TypeFunction {
+		return ctx, cancel
+	}
+	last := time.Now()
+	return &pollContext{Context: ctx, cancel: cancel, poll: func() bool {
+		if yield && time.Since(last) > yieldInterval {
+			time.Sleep(time.Millisecond)
+			last = time.Now()
 		}
-
-		if projected > maxBytes && len(currentEvents) > 0 {
-			flush()
+		if signal.Truthy() && signal.Get("aborted").Truthy() {
+			return true
 		}
+		return shouldCancel.Type() == js.TypeFunction && shouldCancel.Invoke().Truthy()
+	}}, cancel
+}
 
-		currentEvents = append(currentEvents, event.Text)
-		currentSize += eventBytes
-	}
-
-	if len(currentEvents) > 0 {
-		flush()
+// stringOption returns options[name], or "" when it is not a string, so
+// that omitted options do not read as "undefined".
+func stringOption(options js.Value, name string) string {
+	if v := options.Get(name); v.Type() == js.TypeString {
+		return v.String()
 	}
-
-	return results
+	return ""
 }
 
-func splitPerEvent(parsed ParsedCalendar, prefix string) []SplitResult {
-	var results []SplitResult
-
-	for i, event := range parsed.Events {
-		idx := i + 1
-		summaryPart := "event"
-		if event.Summary != "" {
-			summaryPart = sanitizeFilename(event.Summary)
-		}
-
-		var filename string
-		if prefix != "" {
-			filename = prefix + "_" + padNumber(idx) + "_" + summaryPart + ".ics"
-		} else {
-			filename = padNumber(idx) + "_" + summaryPart + ".ics"
-		}
-
-		content := buildICS(parsed.HeaderLines, parsed.Timezones, []string{event.Text})
-		results = append(results, SplitResult{
-			Filename: filename,
-			Content:  content,
-			Events:   1,
-			Size:     len(content),
-		})
+// calendarType is the MIME type of the File objects handed to the page.
+const calendarType = "text/calendar;charset=utf-8"
+
+// newFile wraps content in a JS File named filename, ready to be passed to
+// URL.createObjectURL. Where File is missing it falls back to a Blob.
+func newFile(filename, content string) js.Value {
+	data := js.Global().Get("Uint8Array").New(len(content))
+	js.CopyBytesToJS(data, []byte(content))
+	parts := js.Global().Get("Array").New(data)
+	props := js.ValueOf(map[string]interface{}{"type": calendarType})
+	if file := js.Global().Get("File"); file.Truthy() {
+		return file.New(parts, filename, props)
 	}
-	return results
+	return js.Global().Get("Blob").New(parts, props)
 }
 
-func padNumber(n int) string {
-	return strings.Repeat("0", 3-len(strconv.Itoa(n))) + strconv.Itoa(n)
+// localeOption returns the locale option of the options object at
+// args[i], if there is one.
+func localeOption(args []js.Value, i int) string {
+	if len(args) <= i || args[i].Type() != js.TypeObject {
+		return ""
+	}
+	return stringOption(args[i], "locale")
 }
 
-func splitIcalJS(this js.Value, args []js.Value) interface{} {
-	if len(args) < 2 {
+func getInfoJS(this js.Value, args []js.Value) interface{} {
+	locale := localeOption(args, 1)
+	if len(args) < 1 {
 		return js.ValueOf(map[string]interface{}{
-			"error": "인자가 부족합니다 (content, options)",
+			"error": i18n.Sprintf(locale, "파일 내용이 필요합니다"),
 		})
 	}
 
 	content := args[0].String()
-	options := args[1]
-
-	maxSize := options.Get("maxSize").String()
-	prefix := options.Get("prefix").String()
-	mode := options.Get("mode").String()
-
-	parsed := parseIcal(content)
-
-	if len(parsed.Events) == 0 {
+	parsed, err := calcut.ParseBytes([]byte(content), calcut.DefaultParseLimits())
+	if err != nil {
 		return js.ValueOf(map[string]interface{}{
-			"error":  "이벤트가 없습니다",
-			"events": 0,
+			"error": i18n.Message(locale, err),
 		})
 	}
 
-	var results []SplitResult
+	return js.ValueOf(map[string]interface{}{
+		"events": len(parsed.Events),
+		"size":   len(content),
+	})
+}
 
-	if mode == "size" && maxSize != "" {
-		maxBytes := parseSize(maxSize)
-		if maxBytes <= 0 {
-			return js.ValueOf(map[string]interface{}{
-				"error": "잘못된 크기 형식입니다",
-			})
-		}
-		results = splitBySize(parsed, prefix, maxBytes)
-	} else {
-		results = splitPerEvent(parsed, prefix)
+func validateIcalJS(this js.Value, args []js.Value) interface{} {
+	locale := localeOption(args, 1)
+	if len(args) < 1 {
+		return js.ValueOf(map[string]interface{}{
+			"error": i18n.Sprintf(locale, "파일 내용이 필요합니다"),
+		})
 	}
 
-	jsResults := make([]interface{}, len(results))
-	for i, r := range results {
-		jsResults[i] = map[string]interface{}{
-			"filename": r.Filename,
-			"content":  r.Content,
-			"events":   r.Events,
-			"size":     r.Size,
+	issues := calcut.ValidateLimits([]byte(args[0].String()), calcut.DefaultParseLimits())
+	jsIssues := make([]interface{}, len(issues))
+	errors := 0
+	for i, issue := range issues {
+		if issue.Severity == calcut.SeverityError {
+			errors++
+		}
+		jsIssues[i] = map[string]interface{}{
+			"line":     issue.Line,
+			"severity": issue.Severity,
+			"code":     issue.Code,
+			"message":  issue.Localize(locale),
+			"uid":      issue.UID,
 		}
 	}
-
 	return js.ValueOf(map[string]interface{}{
-		"success":     true,
-		"totalEvents": len(parsed.Events),
-		"files":       jsResults,
+		"valid":    errors == 0,
+		"errors":   errors,
+		"warnings": len(issues) - errors,
+		"issues":   jsIssues,
 	})
 }
 
-func getInfoJS(this js.Value, args []js.Value) interface{} {
-	if len(args) < 1 {
+func mergeIcalJS(this js.Value, args []js.Value) interface{} {
+	locale := localeOption(args, 1)
+	if len(args) < 1 || args[0].Type() != js.TypeObject {
 		return js.ValueOf(map[string]interface{}{
-			"error": "파일 내용이 필요합니다",
+			"error": i18n.Sprintf(locale, "합칠 파일 내용 배열이 필요합니다"),
 		})
 	}
 
-	content := args[0].String()
-	parsed := parseIcal(content)
+	var opts calcut.MergeOptions
+	if len(args) > 1 && args[1].Type() == js.TypeObject {
+		opts.DedupeUID = args[1].Get("dedupeUid").Truthy()
+	}
 
+	contents := args[0]
+	cals := make([]calcut.ParsedCalendar, contents.Length())
+	total := 0
+	for i := range cals {
+		parsed, err := calcut.ParseBytes([]byte(contents.Index(i).String()), calcut.DefaultParseLimits())
+		if err != nil {
+			return js.ValueOf(map[string]interface{}{
+				"error": i18n.Sprintf(locale, "%d번째 파일: %s", i+1, err),
+			})
+		}
+		cals[i] = parsed
+		total += len(parsed.Events)
+	}
+
+	merged := calcut.Merge(cals, opts)
+	content := merged.Build(merged.Events)
 	return js.ValueOf(map[string]interface{}{
-		"events": len(parsed.Events),
-		"size":   len(content),
+		"success":   true,
+		"content":   content,
+		"events":    len(merged.Events),
+		"dropped":   total - len(merged.Events),
+		"timezones": len(merged.Timezones),
+		"size":      len(content),
 	})
 }
 
 func main() {
 	js.Global().Set("calcut", js.ValueOf(map[string]interface{}{
-		"split":   js.FuncOf(splitIcalJS),
-		"getInfo": js.FuncOf(getInfoJS),
-		"version": "1.0.0",
-		"name":    "CalCut",
+		"split":      js.FuncOf(splitIcalJS),
+		"splitAsync": js.FuncOf(splitAsyncJS),
+		"splitMany":  js.FuncOf(splitManyJS),
+		"getInfo":    js.FuncOf(getInfoJS),
+		"merge":      js.FuncOf(mergeIcalJS),
+		"validate":   js.FuncOf(validateIcalJS),
+		"version":    "1.0.0",
+		"name":       "CalCut",
 	}))
 
 	select {}
@@ -0,0 +1,194 @@
+// Type definitions for the calcut global registered by wasm/main.go. Keep
+// them in step with the maps built there: go generate ./wasm/dtscheck
+// fails when the keys read or returned there and the properties declared
+// here differ.
+
+/** Languages of the messages returned in {@link CalcutError.error}. */
+export type Locale = 'ko' | 'en';
+
+/** Options of {@link Calcut.split}. Omitted fields take their defaults. */
+export interface SplitOptions {
+    /** "size" packs events up to maxSize/maxEvents; any other value writes one file per event. */
+    mode?: 'size' | 'event';
+    /** Maximum file size such as "512K" or "1M" (mode "size"). */
+    maxSize?: string;
+    /** Maximum number of events per file (mode "size"). */
+    maxEvents?: number;
+    /** Writes the events of each period to files of their own, such as 2024-03.ics; with mode "size" each period is further split by maxSize/maxEvents, into 2024-03_part_001.ics and so on. */
+    by?: 'year' | 'month' | 'week';
+    /** Prepended to every file name. */
+    prefix?: string;
+    /** Comma-separated component kinds to split, e.g. "VEVENT,VTODO"; all of VEVENT, VTODO, VJOURNAL and VFREEBUSY by default. */
+    components?: string;
+    /** Only keep events starting on or after this day (YYYY-MM-DD). */
+    from?: string;
+    /** Only keep events starting on or before this day (YYYY-MM-DD). */
+    to?: string;
+    /** Calendar colors given to the files in turn: "auto" for the built-in palette, or a comma-separated list of CSS color names and #RRGGBB values. */
+    colors?: string;
+    /** Language of error messages, "ko" by default. */
+    locale?: Locale;
+    /** Also return every chunk as a File of type text/calendar, see {@link SplitFile.file}. */
+    files?: boolean;
+    /** Also return all chunks packed into one zip archive, see {@link SplitSuccess.zip}. */
+    zip?: boolean;
+    /** Aborts the split when fired; checked between events. Only splitAsync lets the page run while splitting. */
+    signal?: AbortSignal;
+    /** Called between events; returning true aborts the split (e.g. a flag shared with a worker). */
+    shouldCancel?: () => boolean;
+    /** Called every 50 ms or so while splitting and once at the end. Only splitAsync lets the page repaint in between. */
+    onProgress?: (progress: SplitProgress) => void;
+}
+
+/** How far a split has got, see {@link SplitOptions.onProgress}. */
+export interface SplitProgress {
+    /** Events read so far; the total once parsing is done. */
+    parsedEvents: number;
+    /** Files built so far. */
+    writtenFiles: number;
+    /** 0 to 100; parsing takes the first half, building the files the second. */
+    percent: number;
+}
+
+export interface SplitFile {
+    filename: string;
+    content: string;
+    events: number;
+    /** Size of content in bytes (UTF-8). */
+    size: number;
+    /** The chunk as a downloadable File (a Blob where File is missing), only with the files option. */
+    file?: File | Blob;
+}
+
+export interface SplitSuccess {
+    success: true;
+    totalEvents: number;
+    files: SplitFile[];
+    /** The files as entries of a zip archive, only with the zip option. */
+    zip?: Uint8Array;
+}
+
+export interface CalcutError {
+    success?: undefined;
+    /** Human-readable message. */
+    error: string;
+    /** Set to 0 when the input holds no events. */
+    events?: number;
+    /** Set when signal or shouldCancel stopped the split. */
+    cancelled?: true;
+}
+
+export type SplitResult = SplitSuccess | CalcutError;
+
+export interface Info {
+    error?: undefined;
+    events: number;
+    size: number;
+}
+
+export type InfoResult = Info | CalcutError;
+
+/** One calendar of {@link Calcut.splitMany}. */
+export interface NamedInput {
+    name: string;
+    content: string;
+}
+
+/** The result of one input of {@link Calcut.splitMany}, tagged with its name. */
+export type NamedSplitResult = SplitResult & { name: string };
+
+export interface SplitManySuccess {
+    error?: undefined;
+    /** True when every input was split. */
+    success: boolean;
+    /** Number of inputs that failed. */
+    failed: number;
+    /** One entry per input, in order. */
+    results: NamedSplitResult[];
+}
+
+export type SplitManyResult = SplitManySuccess | CalcutError;
+
+/** Options of {@link Calcut.merge}. */
+export interface MergeOptions {
+    /** Keep only the first event for each UID and RECURRENCE-ID. */
+    dedupeUid?: boolean;
+    /** Language of error messages, "ko" by default. */
+    locale?: Locale;
+}
+
+/** Options of {@link Calcut.getInfo}. */
+export interface InfoOptions {
+    /** Language of error messages, "ko" by default. */
+    locale?: Locale;
+}
+
+export interface MergeSuccess {
+    success: true;
+    /** The merged calendar. */
+    content: string;
+    events: number;
+    /** Events left out by dedupeUid. */
+    dropped: number;
+    timezones: number;
+    size: number;
+}
+
+export type MergeResult = MergeSuccess | CalcutError;
+
+/** Options of {@link Calcut.validate}. */
+export interface ValidateOptions {
+    /** Language of the messages, "ko" by default. */
+    locale?: Locale;
+}
+
+export interface ValidationIssue {
+    /** 1-based line number, or 0 for the calendar as a whole. */
+    line: number;
+    /** Errors break RFC 5545; warnings are usually tolerated by importers. */
+    severity: 'error' | 'warning';
+    /** Stable identifier such as "missing-uid", "duplicate-uid", "unknown-tzid", "unescaped-text", "legacy-encoding", "line-length", "structure" or "too-large". */
+    code: string;
+    message: string;
+    /** UID of the component concerned, "" if none. */
+    uid: string;
+}
+
+export interface ValidationReport {
+    error?: undefined;
+    /** True when there are no errors; warnings are allowed. */
+    valid: boolean;
+    errors: number;
+    warnings: number;
+    issues: ValidationIssue[];
+}
+
+export type ValidateResult = ValidationReport | CalcutError;
+
+export interface Calcut {
+    /** Splits the text of an .ics file. */
+    split(content: string, options: SplitOptions): SplitResult;
+    /** Splits several calendars with the same options; a failing input, including one without a string name and content, does not stop the others. */
+    splitMany(inputs: NamedInput[], options: SplitOptions): SplitManyResult;
+    /** Like split, but yields to the page while working so it can be cancelled through options.signal. */
+    splitAsync(content: string, options: SplitOptions): Promise<SplitResult>;
+    /** Counts the events of an .ics file. */
+    getInfo(content: string, options?: InfoOptions): InfoResult;
+    /** Combines several .ics files into one calendar. */
+    merge(contents: string[], options?: MergeOptions): MergeResult;
+    /** Checks an .ics file for RFC 5545 structural problems. */
+    validate(content: string, options?: ValidateOptions): ValidateResult;
+    readonly version: string;
+    readonly name: string;
+}
+
+declare global {
+    /** Registered once the module is running. */
+    var calcut: Calcut | undefined;
+}
+
+/**
+ * Instantiates the module and returns the calcut API it registers.
+ * @param source the module, calcut.wasm next to this file by default.
+ */
+export function load(source?: string | URL | Response | BufferSource): Promise<Calcut>;
@@ -0,0 +1,34 @@
+// Loader for the calcut WebAssembly module. wasm_exec.js is the Go runtime
+// glue shipped with the Go toolchain; importing it defines globalThis.Go.
+import './wasm_exec.js';
+
+const defaultSource = new URL('./calcut.wasm', import.meta.url);
+
+async function readModule(source) {
+    if (source instanceof ArrayBuffer || ArrayBuffer.isView(source)) {
+        return source;
+    }
+    if (source instanceof Response) {
+        return source.arrayBuffer();
+    }
+    const url = new URL(source, defaultSource);
+    if (url.protocol === 'file:') {
+        const { readFile } = await import('node:fs/promises');
+        return readFile(url);
+    }
+    return (await fetch(url)).arrayBuffer();
+}
+
+/**
+ * Instantiates the module and returns the calcut API it registers.
+ * @param {string | URL | Response | BufferSource} [source] the module,
+ *     calcut.wasm next to this file by default.
+ * @returns {Promise<import('./index.js').Calcut>}
+ */
+export async function load(source = defaultSource) {
+    const go = new globalThis.Go();
+    const { instance } = await WebAssembly.instantiate(await readModule(source), go.importObject);
+    // main never returns: it keeps the exported functions alive.
+    go.run(instance);
+    return globalThis.calcut;
+}
@@ -24,6 +24,7 @@
         downloadAll: document.getElementById('download-all'),
         resetBtn: document.getElementById('reset-btn'),
         loading: document.getElementById('loading'),
+        loadingText: document.querySelector('#loading p'),
     };
 
     async function initWasm() {
@@ -107,18 +108,27 @@
             return;
         }
 
+        elements.loadingText.textContent = '처리 중...';
         showElement(elements.loading);
         hideElement(elements.options);
 
-        setTimeout(function() {
+        setTimeout(async function() {
             const mode = getSplitMode();
             const options = {
                 mode: mode,
                 maxSize: mode === 'size' ? elements.maxSize.value : '',
                 prefix: elements.prefix.value.trim(),
+                onProgress: function(progress) {
+                    elements.loadingText.textContent = progress.writtenFiles > 0
+                        ? `처리 중... ${progress.percent}% (${progress.writtenFiles}개 파일)`
+                        : `처리 중... ${progress.percent}% (${progress.parsedEvents}개 이벤트 읽음)`;
+                },
             };
 
-            const result = window.calcut.split(currentFile.content, options);
+            // Older builds of ical.wasm have no splitAsync and no progress.
+            const result = window.calcut.splitAsync
+                ? await window.calcut.splitAsync(currentFile.content, options)
+                : window.calcut.split(currentFile.content, options);
 
             hideElement(elements.loading);
 